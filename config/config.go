@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -17,6 +19,38 @@ type Config struct {
 	COSSecretID  string
 	COSSecretKey string
 	COSBucketURL string
+
+	// WeChat subscribe message templates used for reminders
+	VaccineTemplateID     string
+	DewormingTemplateID   string
+	BirthdayTemplateID    string
+	LostPetTemplateID     string
+	EventTemplateID       string
+	ReviewReplyTemplateID string
+	ReminderDryRun        bool
+	WechatFakeURL         string
+
+	// TemplateFields holds the data keys of each template, keyed by reminder kind, in the order values are sent
+	TemplateFields map[string][]string
+
+	// ModeratorIDs are the user IDs allowed to review place claims and held content
+	ModeratorIDs []string
+
+	// Content safety checks, the keyword backend works offline from a local blocklist
+	ContentCheckBackend  string
+	ContentBlocklistFile string
+	WechatPushToken      string
+
+	// ReportHideThreshold is the number of open reports that hides reported content until a moderator acts.
+	// Only reports from accounts at least ReportMinAccountAgeHours old count towards it.
+	ReportHideThreshold      int
+	ReportMinAccountAgeHours int
+
+	// EventBroker selects the push event broker, "memory" only reaches connections on the same instance
+	EventBroker string
+
+	// SessionSecret signs push session tokens, it must be shared by all instances
+	SessionSecret string
 }
 
 var (
@@ -39,6 +73,35 @@ func GetConfig() *Config {
 			COSSecretID:  getEnv("COS_SECRET_ID", ""),
 			COSSecretKey: getEnv("COS_SECRET_KEY", ""),
 			COSBucketURL: getEnv("COS_BUCKET_URL", "https://blog-1321748307.cos.ap-beijing.myqcloud.com"),
+
+			VaccineTemplateID:     getEnv("WECHAT_VACCINE_TEMPLATE_ID", ""),
+			DewormingTemplateID:   getEnv("WECHAT_DEWORMING_TEMPLATE_ID", ""),
+			BirthdayTemplateID:    getEnv("WECHAT_BIRTHDAY_TEMPLATE_ID", ""),
+			LostPetTemplateID:     getEnv("WECHAT_LOST_PET_TEMPLATE_ID", ""),
+			EventTemplateID:       getEnv("WECHAT_EVENT_TEMPLATE_ID", ""),
+			ReviewReplyTemplateID: getEnv("WECHAT_REVIEW_REPLY_TEMPLATE_ID", ""),
+			ReminderDryRun:        getEnv("REMINDER_DRY_RUN", "false") == "true",
+			WechatFakeURL:         getEnv("WECHAT_FAKE_URL", "http://localhost:8080/wechat/fake"),
+			ModeratorIDs:          getEnvList("MODERATOR_USER_IDS"),
+
+			TemplateFields: map[string][]string{
+				"vaccination":  getEnvList("WECHAT_VACCINE_TEMPLATE_FIELDS"),
+				"deworming":    getEnvList("WECHAT_DEWORMING_TEMPLATE_FIELDS"),
+				"birthday":     getEnvList("WECHAT_BIRTHDAY_TEMPLATE_FIELDS"),
+				"lost_pet":     getEnvList("WECHAT_LOST_PET_TEMPLATE_FIELDS"),
+				"event":        getEnvList("WECHAT_EVENT_TEMPLATE_FIELDS"),
+				"review_reply": getEnvList("WECHAT_REVIEW_REPLY_TEMPLATE_FIELDS"),
+			},
+
+			ContentCheckBackend:  getEnv("CONTENT_CHECK_BACKEND", "wechat"),
+			ContentBlocklistFile: getEnv("CONTENT_BLOCKLIST_FILE", ""),
+			WechatPushToken:      getEnv("WECHAT_PUSH_TOKEN", ""),
+			ReportHideThreshold:  getEnvInt("REPORT_HIDE_THRESHOLD", 5),
+
+			ReportMinAccountAgeHours: getEnvInt("REPORT_MIN_ACCOUNT_AGE_HOURS", 72),
+
+			EventBroker:   getEnv("EVENT_BROKER", "memory"),
+			SessionSecret: getEnv("SESSION_SECRET", ""),
 		}
 	})
 
@@ -53,3 +116,23 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvInt reads an integer environment variable or returns a default value if not set or invalid
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvList reads a comma-separated environment variable into a list, skipping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
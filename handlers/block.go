@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// handleUserBlocks handles /user/{id}/blocks and /user/{id}/mutes. Block lists are private, so only the user may read or change theirs.
+//
+//	GET    /user/{id}/blocks            lists blocked and muted users
+//	POST   /user/{id}/blocks/{otherId}  blocks a user, DELETE unblocks
+//	POST   /user/{id}/mutes/{otherId}   mutes a user, DELETE unmutes
+func handleUserBlocks(w http.ResponseWriter, r *http.Request, userID string, list string, urlParts []string) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid user ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	requestUserID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+	if requestUserID != id {
+		utils.ErrorResponse(w, "Not authorized to access this block list", 403, http.StatusForbidden)
+		return
+	}
+
+	if len(urlParts) == 0 {
+		if r.Method != http.MethodGet {
+			utils.ErrorResponse(w, "Method not allowed", 405, http.StatusMethodNotAllowed)
+			return
+		}
+		blockList, err := services.GetUserBlockList(id)
+		if err != nil {
+			blockErrorResponse(w, "Failed to get block list", err)
+			return
+		}
+		utils.SuccessResponse(w, blockList, http.StatusOK)
+		return
+	}
+
+	if len(urlParts) != 1 {
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+		return
+	}
+	otherID, err := primitive.ObjectIDFromHex(urlParts[0])
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid user ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	var change func(primitive.ObjectID, primitive.ObjectID) (*models.UserBlockList, error)
+	switch {
+	case r.Method == http.MethodPost && list == "blocks":
+		change = services.BlockUser
+	case r.Method == http.MethodDelete && list == "blocks":
+		change = services.UnblockUser
+	case r.Method == http.MethodPost && list == "mutes":
+		change = services.MuteUser
+	case r.Method == http.MethodDelete && list == "mutes":
+		change = services.UnmuteUser
+	default:
+		utils.ErrorResponse(w, "Method not allowed", 405, http.StatusMethodNotAllowed)
+		return
+	}
+
+	blockList, err := change(id, otherID)
+	if err != nil {
+		blockErrorResponse(w, "Failed to update block list", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, blockList, http.StatusOK)
+}
+
+// blockErrorResponse maps block list errors to HTTP responses
+func blockErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case strings.HasPrefix(err.Error(), "no user found"):
+		utils.ErrorResponse(w, "User not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "invalid block"):
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+	default:
+		utils.ErrorResponse(w, message+": "+err.Error(), 500, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HandleBreed handles breed catalog search, autocomplete and retrieval
+func HandleBreed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", 405, http.StatusMethodNotAllowed)
+		return
+	}
+
+	urlParts := utils.ExtractUrlParam(r.URL.Path, "/breed")
+	var breedID string
+	if len(urlParts) > 0 {
+		breedID = urlParts[0]
+	}
+
+	switch {
+	case breedID == "":
+		searchBreeds(w, r)
+	case breedID == "autocomplete":
+		autocompleteBreeds(w, r)
+	default:
+		getBreed(w, r, breedID)
+	}
+}
+
+// searchBreeds handles GET /breed?q=&species=&limit=
+func searchBreeds(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	species, limit, ok := parseBreedQuery(w, query.Get("species"), query.Get("limit"))
+	if !ok {
+		return
+	}
+
+	breeds, err := services.SearchBreeds(query.Get("q"), species, limit)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to search breeds: "+err.Error(), 500, http.StatusInternalServerError)
+		return
+	}
+
+	if breeds == nil {
+		breeds = make([]models.Breed, 0)
+	}
+	// Return response
+	utils.SuccessResponse(w, breeds, http.StatusOK)
+}
+
+// autocompleteBreeds handles GET /breed/autocomplete?q=&species=&limit=
+func autocompleteBreeds(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	prefix := query.Get("q")
+	if prefix == "" {
+		utils.ErrorResponse(w, "Query is required", 400, http.StatusBadRequest)
+		return
+	}
+
+	species, limit, ok := parseBreedQuery(w, query.Get("species"), query.Get("limit"))
+	if !ok {
+		return
+	}
+
+	suggestions, err := services.AutocompleteBreeds(prefix, species, limit)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to autocomplete breeds: "+err.Error(), 500, http.StatusInternalServerError)
+		return
+	}
+
+	if suggestions == nil {
+		suggestions = make([]models.BreedSuggestion, 0)
+	}
+	// Return response
+	utils.SuccessResponse(w, suggestions, http.StatusOK)
+}
+
+// getBreed handles GET /breed/{id}
+func getBreed(w http.ResponseWriter, r *http.Request, breedID string) {
+	id, err := primitive.ObjectIDFromHex(breedID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid breed ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	breed, err := services.GetBreedByID(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "no breed found") {
+			utils.ErrorResponse(w, "Breed not found", 404, http.StatusNotFound)
+		} else {
+			utils.ErrorResponse(w, "Failed to get breed: "+err.Error(), 500, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, breed, http.StatusOK)
+}
+
+// parseBreedQuery validates the species and limit query parameters
+func parseBreedQuery(w http.ResponseWriter, species string, limitParam string) (string, int64, bool) {
+	switch species {
+	case "", models.SpeciesDog, models.SpeciesCat, models.SpeciesOther:
+	default:
+		utils.ErrorResponse(w, "Invalid species, must be one of dog, cat, other", 400, http.StatusBadRequest)
+		return "", 0, false
+	}
+
+	var limit int64
+	if limitParam != "" {
+		parsedLimit, err := strconv.ParseInt(limitParam, 10, 64)
+		if err != nil || parsedLimit <= 0 {
+			utils.ErrorResponse(w, "Invalid limit parameter", 400, http.StatusBadRequest)
+			return "", 0, false
+		}
+		limit = parsedLimit
+	}
+
+	return species, limit, true
+}
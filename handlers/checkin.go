@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// checkInAtPlace handles POST /place/{id}/checkin with the user's current position
+func checkInAtPlace(id string, w http.ResponseWriter, r *http.Request) {
+	placeID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid location ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.CheckInRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	// Validate request
+	if request.Latitude < -90 || request.Latitude > 90 || request.Longitude < -180 || request.Longitude > 180 {
+		utils.ErrorResponse(w, "Invalid position", 400, http.StatusBadRequest)
+		return
+	}
+
+	checkIn, err := services.CheckInAtPlace(placeID, userID, request)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "no location found"):
+			utils.ErrorResponse(w, "Location not found", 404, http.StatusNotFound)
+		case strings.Contains(err.Error(), "too far from place"):
+			utils.ErrorResponse(w, err.Error(), 403, http.StatusForbidden)
+		case strings.Contains(err.Error(), "already checked in"):
+			utils.ErrorResponse(w, "Already checked in here recently", 409, http.StatusConflict)
+		default:
+			petResourceErrorResponse(w, "Failed to check in", err)
+		}
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, checkIn, http.StatusCreated)
+}
+
+// getPlaceVisits handles GET /place/{id}/visits returning the place's visit statistics
+func getPlaceVisits(id string, w http.ResponseWriter, r *http.Request) {
+	placeID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid location ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	stats, err := services.GetPlaceVisitStats(placeID)
+	if err != nil {
+		if strings.Contains(err.Error(), "no location found") {
+			utils.ErrorResponse(w, "Location not found", 404, http.StatusNotFound)
+		} else {
+			utils.ErrorResponse(w, "Failed to get visits: "+err.Error(), 500, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, stats, http.StatusOK)
+}
+
+// handleUserVisits handles GET /user/{id}/visits, the user's own visit history
+func handleUserVisits(w http.ResponseWriter, r *http.Request, userID string) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", 405, http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid user ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	requestUserID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+	if requestUserID != id {
+		utils.ErrorResponse(w, "Not authorized to view this visit history", 403, http.StatusForbidden)
+		return
+	}
+
+	var limit int64 = 100 // default 100 visits
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err = strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || limit <= 0 {
+			utils.ErrorResponse(w, "Invalid limit parameter", 400, http.StatusBadRequest)
+			return
+		}
+	}
+
+	visits, err := services.ListUserVisits(id, limit)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to list visits: "+err.Error(), 500, http.StatusInternalServerError)
+		return
+	}
+
+	if visits == nil {
+		visits = make([]models.CheckIn, 0)
+	}
+	// Return response
+	utils.SuccessResponse(w, visits, http.StatusOK)
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HandleClaim handles place ownership claims and their moderation
+func HandleClaim(w http.ResponseWriter, r *http.Request) {
+	urlParts := utils.ExtractUrlParam(r.URL.Path, "/claim")
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	if len(urlParts) == 0 {
+		switch r.Method {
+		case http.MethodPost:
+			createPlaceClaim(w, r, userID)
+		case http.MethodGet:
+			listUserPlaceClaims(w, r, userID)
+		default:
+			utils.ErrorResponse(w, "Method not allowed", 405, http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	if urlParts[0] == "review" && len(urlParts) == 1 && r.Method == http.MethodGet {
+		listPlaceClaimsForReview(w, r, userID)
+		return
+	}
+
+	claimID, err := primitive.ObjectIDFromHex(urlParts[0])
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid claim ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(urlParts) == 1:
+		getPlaceClaim(w, r, claimID, userID)
+	case r.Method == http.MethodDelete && len(urlParts) == 1:
+		withdrawPlaceClaim(w, r, claimID, userID)
+	case r.Method == http.MethodPost && len(urlParts) == 2 && urlParts[1] == "evidence":
+		uploadPlaceClaimEvidence(w, r, claimID, userID)
+	case r.Method == http.MethodPost && len(urlParts) == 2 && (urlParts[1] == "approve" || urlParts[1] == "reject"):
+		reviewPlaceClaim(w, r, claimID, userID, urlParts[1] == "approve")
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// createPlaceClaim handles POST requests to claim a place
+func createPlaceClaim(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.PlaceClaimRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	// Validate required fields
+	if request.PlaceID.IsZero() {
+		utils.ErrorResponse(w, "Place ID is required", 400, http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(request.BusinessName) == "" {
+		utils.ErrorResponse(w, "Business name is required", 400, http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(request.ContactName) == "" {
+		utils.ErrorResponse(w, "Contact name is required", 400, http.StatusBadRequest)
+		return
+	}
+	if request.PhoneCode == "" {
+		utils.ErrorResponse(w, "Phone code is required", 400, http.StatusBadRequest)
+		return
+	}
+	if len([]rune(request.Note)) > 500 {
+		utils.ErrorResponse(w, "Note must be at most 500 characters", 400, http.StatusBadRequest)
+		return
+	}
+
+	claim, err := services.CreatePlaceClaim(userID, request)
+	if err != nil {
+		placeClaimErrorResponse(w, "Failed to create claim", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, claim, http.StatusCreated)
+}
+
+// listUserPlaceClaims handles GET requests for the caller's own claims
+func listUserPlaceClaims(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	claims, err := services.ListUserPlaceClaims(userID)
+	if err != nil {
+		placeClaimErrorResponse(w, "Failed to list claims", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, claims, http.StatusOK)
+}
+
+// listPlaceClaimsForReview handles GET /claim/review, the moderation queue
+func listPlaceClaimsForReview(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.ClaimPending, models.ClaimApproved, models.ClaimRejected, models.ClaimWithdrawn:
+	default:
+		utils.ErrorResponse(w, "Invalid status parameter", 400, http.StatusBadRequest)
+		return
+	}
+
+	var limit int64
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsedLimit, err := strconv.ParseInt(limitParam, 10, 64)
+		if err != nil || parsedLimit <= 0 {
+			utils.ErrorResponse(w, "Invalid limit parameter", 400, http.StatusBadRequest)
+			return
+		}
+		limit = parsedLimit
+	}
+
+	claims, err := services.ListPlaceClaimsForReview(userID, status, limit)
+	if err != nil {
+		placeClaimErrorResponse(w, "Failed to list claims", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, claims, http.StatusOK)
+}
+
+// getPlaceClaim handles GET requests for a single claim
+func getPlaceClaim(w http.ResponseWriter, r *http.Request, claimID primitive.ObjectID, userID primitive.ObjectID) {
+	claim, err := services.GetPlaceClaim(claimID, userID)
+	if err != nil {
+		placeClaimErrorResponse(w, "Failed to get claim", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, claim, http.StatusOK)
+}
+
+// withdrawPlaceClaim handles DELETE requests to withdraw a pending claim
+func withdrawPlaceClaim(w http.ResponseWriter, r *http.Request, claimID primitive.ObjectID, userID primitive.ObjectID) {
+	claim, err := services.WithdrawPlaceClaim(claimID, userID)
+	if err != nil {
+		placeClaimErrorResponse(w, "Failed to withdraw claim", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, claim, http.StatusOK)
+}
+
+// uploadPlaceClaimEvidence handles POST /claim/{id}/evidence with a multipart image or PDF
+func uploadPlaceClaimEvidence(w http.ResponseWriter, r *http.Request, claimID primitive.ObjectID, userID primitive.ObjectID) {
+	// Parse multipart form with 10 MB max memory
+	const maxMemory = 10 * 1024 * 1024 // 10 MB
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
+		utils.ErrorResponse(w, "Failed to parse form: "+err.Error(), 400, http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(w, "No file provided or invalid file field", 400, http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	contentType := header.Header.Get("Content-Type")
+	if !isAllowedImageType(contentType) && contentType != "application/pdf" {
+		utils.ErrorResponse(w, "Unsupported file type: only images and PDF are allowed", 400, http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Received claim evidence: %s, size: %d bytes, type: %s", header.Filename, header.Size, contentType)
+
+	claim, err := services.AddPlaceClaimEvidence(claimID, userID, file, header.Filename, contentType)
+	if err != nil {
+		placeClaimErrorResponse(w, "Failed to upload evidence", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, claim, http.StatusOK)
+}
+
+// reviewPlaceClaim handles POST /claim/{id}/approve and /claim/{id}/reject for moderators
+func reviewPlaceClaim(w http.ResponseWriter, r *http.Request, claimID primitive.ObjectID, userID primitive.ObjectID, approve bool) {
+	var request models.PlaceClaimReviewRequest
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &request); err != nil {
+			utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+			return
+		}
+	}
+	if !approve && strings.TrimSpace(request.Note) == "" {
+		utils.ErrorResponse(w, "A note explaining the rejection is required", 400, http.StatusBadRequest)
+		return
+	}
+
+	var claim *models.PlaceClaim
+	if approve {
+		claim, err = services.ApprovePlaceClaim(claimID, userID, request)
+	} else {
+		claim, err = services.RejectPlaceClaim(claimID, userID, request)
+	}
+	if err != nil {
+		placeClaimErrorResponse(w, "Failed to review claim", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, claim, http.StatusOK)
+}
+
+// placeClaimErrorResponse maps place claim errors to HTTP responses
+func placeClaimErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "no claim found"):
+		utils.ErrorResponse(w, "Claim not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "no location found"):
+		utils.ErrorResponse(w, "Location not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "not authorized"):
+		utils.ErrorResponse(w, err.Error(), 403, http.StatusForbidden)
+	case strings.Contains(err.Error(), "invalid claim"):
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+	case strings.Contains(err.Error(), "phone verification failed"):
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+	default:
+		utils.ErrorResponse(w, message+": "+err.Error(), 500, http.StatusInternalServerError)
+	}
+}
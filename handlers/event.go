@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HandleEvent handles playdate events, their cancellation and RSVPs
+func HandleEvent(w http.ResponseWriter, r *http.Request) {
+	urlParts := utils.ExtractUrlParam(r.URL.Path, "/event")
+
+	var eventID string
+	if len(urlParts) > 0 {
+		eventID = urlParts[0]
+	}
+
+	switch {
+	case r.Method == http.MethodPost && eventID == "":
+		createEvent(w, r)
+	case r.Method == http.MethodGet && eventID == "":
+		listEvents(w, r)
+	case r.Method == http.MethodGet && len(urlParts) == 1:
+		getEvent(w, r, eventID)
+	case r.Method == http.MethodPost && len(urlParts) == 2 && urlParts[1] == "cancel":
+		cancelEvent(w, r, eventID)
+	case r.Method == http.MethodGet && len(urlParts) == 2 && urlParts[1] == "rsvps":
+		listEventRSVPs(w, r, eventID)
+	case r.Method == http.MethodPost && len(urlParts) == 2 && urlParts[1] == "rsvp":
+		rsvpToEvent(w, r, eventID)
+	case r.Method == http.MethodDelete && len(urlParts) == 2 && urlParts[1] == "rsvp":
+		cancelEventRSVP(w, r, eventID)
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// createEvent handles POST requests to host a new event
+func createEvent(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.EventRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	if err := validateEventRequest(request); err != nil {
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+		return
+	}
+
+	event, err := services.CreateEvent(userID, request)
+	if err != nil {
+		eventErrorResponse(w, "Failed to create event", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, event, http.StatusCreated)
+}
+
+// listEvents handles GET requests for upcoming events at a place or near a point
+func listEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	// List the events of one place when a location ID is given
+	if locationIDStr := query.Get("locationId"); locationIDStr != "" {
+		locationID, err := primitive.ObjectIDFromHex(locationIDStr)
+		if err != nil {
+			utils.ErrorResponse(w, "Invalid location ID format", 400, http.StatusBadRequest)
+			return
+		}
+
+		events, err := services.ListEventsByLocation(locationID)
+		if err != nil {
+			utils.ErrorResponse(w, "Failed to list events: "+err.Error(), 500, http.StatusInternalServerError)
+			return
+		}
+		if events == nil {
+			events = make([]models.Event, 0)
+		}
+		utils.SuccessResponse(w, events, http.StatusOK)
+		return
+	}
+
+	lat, err := strconv.ParseFloat(query.Get("latitude"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		utils.ErrorResponse(w, "Invalid latitude parameter", 400, http.StatusBadRequest)
+		return
+	}
+
+	lng, err := strconv.ParseFloat(query.Get("longitude"), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		utils.ErrorResponse(w, "Invalid longitude parameter", 400, http.StatusBadRequest)
+		return
+	}
+
+	search := models.EventSearchRequest{
+		Latitude:  lat,
+		Longitude: lng,
+		PetSize:   query.Get("petSize"),
+		PetType:   query.Get("petType"),
+	}
+
+	if radiusStr := query.Get("radius"); radiusStr != "" {
+		radius, err := strconv.ParseFloat(radiusStr, 64)
+		if err != nil || radius <= 0 {
+			utils.ErrorResponse(w, "Invalid radius parameter", 400, http.StatusBadRequest)
+			return
+		}
+		search.Radius = radius
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || limit <= 0 {
+			utils.ErrorResponse(w, "Invalid limit parameter", 400, http.StatusBadRequest)
+			return
+		}
+		search.Limit = limit
+	}
+
+	results, err := services.SearchNearbyEvents(search)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to search events: "+err.Error(), 500, http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, results, http.StatusOK)
+}
+
+// getEvent handles GET requests to retrieve an event
+func getEvent(w http.ResponseWriter, r *http.Request, eventID string) {
+	id, err := primitive.ObjectIDFromHex(eventID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid event ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	event, err := services.GetEventByID(id)
+	if err != nil {
+		eventErrorResponse(w, "Failed to get event", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, event, http.StatusOK)
+}
+
+// cancelEvent handles POST requests from the host to cancel an event
+func cancelEvent(w http.ResponseWriter, r *http.Request, eventID string) {
+	id, err := primitive.ObjectIDFromHex(eventID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid event ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	// The cancellation reason is optional
+	var request models.EventCancelRequest
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &request); err != nil {
+			utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+			return
+		}
+	}
+
+	event, err := services.CancelEvent(id, userID, request)
+	if err != nil {
+		eventErrorResponse(w, "Failed to cancel event", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, event, http.StatusOK)
+}
+
+// listEventRSVPs handles GET requests for an event's attendees and waitlist
+func listEventRSVPs(w http.ResponseWriter, r *http.Request, eventID string) {
+	id, err := primitive.ObjectIDFromHex(eventID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid event ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	rsvps, err := services.ListEventRSVPs(id)
+	if err != nil {
+		eventErrorResponse(w, "Failed to list RSVPs", err)
+		return
+	}
+
+	if rsvps == nil {
+		rsvps = make([]models.EventRSVP, 0)
+	}
+	// Return response
+	utils.SuccessResponse(w, rsvps, http.StatusOK)
+}
+
+// rsvpToEvent handles POST requests to attend an event with pets
+func rsvpToEvent(w http.ResponseWriter, r *http.Request, eventID string) {
+	id, err := primitive.ObjectIDFromHex(eventID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid event ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.EventRSVPRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	if len(request.PetIDs) == 0 {
+		utils.ErrorResponse(w, "At least one pet is required", 400, http.StatusBadRequest)
+		return
+	}
+	seen := make(map[primitive.ObjectID]bool, len(request.PetIDs))
+	for _, petID := range request.PetIDs {
+		if seen[petID] {
+			utils.ErrorResponse(w, "Each pet can only be listed once", 400, http.StatusBadRequest)
+			return
+		}
+		seen[petID] = true
+	}
+
+	rsvp, err := services.RSVPToEvent(id, userID, request)
+	if err != nil {
+		eventErrorResponse(w, "Failed to RSVP", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, rsvp, http.StatusOK)
+}
+
+// cancelEventRSVP handles DELETE requests to withdraw from an event
+func cancelEventRSVP(w http.ResponseWriter, r *http.Request, eventID string) {
+	id, err := primitive.ObjectIDFromHex(eventID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid event ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	rsvp, err := services.CancelEventRSVP(id, userID)
+	if err != nil {
+		eventErrorResponse(w, "Failed to cancel RSVP", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, rsvp, http.StatusOK)
+}
+
+// validateEventRequest checks an event's title, schedule, capacity and pet restrictions
+func validateEventRequest(request models.EventRequest) error {
+	if request.LocationID.IsZero() {
+		return fmt.Errorf("location ID is required")
+	}
+	if request.Title == "" {
+		return fmt.Errorf("title is required")
+	}
+	if request.StartTime.IsZero() || request.EndTime.IsZero() {
+		return fmt.Errorf("start and end time are required")
+	}
+	if !request.StartTime.After(time.Now()) {
+		return fmt.Errorf("start time must be in the future")
+	}
+	if !request.EndTime.After(request.StartTime) {
+		return fmt.Errorf("end time must be after start time")
+	}
+	if request.Capacity <= 0 || request.Capacity > 200 {
+		return fmt.Errorf("capacity must be between 1 and 200")
+	}
+	for _, size := range request.PetSizes {
+		if size != models.SizeSmall && size != models.SizeMedium && size != models.SizeLarge {
+			return fmt.Errorf("pet sizes must be small, medium or large")
+		}
+	}
+	for _, petType := range request.PetTypes {
+		if petType != models.SpeciesDog && petType != models.SpeciesCat && petType != models.SpeciesOther {
+			return fmt.Errorf("pet types must be dog, cat or other")
+		}
+	}
+	return nil
+}
+
+// eventErrorResponse maps event errors to HTTP responses
+func eventErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "user is banned"):
+		utils.ErrorResponse(w, "Your account has been banned", 403, http.StatusForbidden)
+	case strings.Contains(err.Error(), "blocked by user"):
+		utils.ErrorResponse(w, "The host has blocked you", 403, http.StatusForbidden)
+	case strings.Contains(err.Error(), "no event found"):
+		utils.ErrorResponse(w, "Event not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "no location found"):
+		utils.ErrorResponse(w, "Location not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "no RSVP found"):
+		utils.ErrorResponse(w, "RSVP not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "not open for RSVP"), strings.Contains(err.Error(), "already cancelled"):
+		utils.ErrorResponse(w, err.Error(), 409, http.StatusConflict)
+	case strings.Contains(err.Error(), "not authorized to cancel"):
+		utils.ErrorResponse(w, "Only the host can cancel this event", 403, http.StatusForbidden)
+	case strings.Contains(err.Error(), "invalid RSVP"):
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+	default:
+		petResourceErrorResponse(w, message, err)
+	}
+}
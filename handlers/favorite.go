@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HandlePlaceList handles saved place lists, their items and share links
+func HandlePlaceList(w http.ResponseWriter, r *http.Request) {
+	urlParts := utils.ExtractUrlParam(r.URL.Path, "/list")
+
+	// Shared lists are readable by anyone with the link
+	if len(urlParts) == 2 && urlParts[0] == "shared" && r.Method == http.MethodGet {
+		getSharedPlaceList(w, r, urlParts[1])
+		return
+	}
+
+	// Viewing public lists does not need a user, everything else does
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil && r.Method != http.MethodGet {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	if len(urlParts) == 0 {
+		switch r.Method {
+		case http.MethodPost:
+			createPlaceList(w, r, userID)
+		case http.MethodGet:
+			listPlaceLists(w, r, userID)
+		default:
+			utils.ErrorResponse(w, "Method not allowed", 405, http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	listID, err := primitive.ObjectIDFromHex(urlParts[0])
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid list ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(urlParts) == 1:
+		getPlaceList(w, r, listID, userID)
+	case r.Method == http.MethodPut && len(urlParts) == 1:
+		updatePlaceList(w, r, listID, userID)
+	case r.Method == http.MethodDelete && len(urlParts) == 1:
+		deletePlaceList(w, r, listID, userID)
+	case r.Method == http.MethodPost && len(urlParts) == 2 && urlParts[1] == "items":
+		addPlaceToList(w, r, listID, userID)
+	case r.Method == http.MethodPut && len(urlParts) == 3 && urlParts[1] == "items" && urlParts[2] == "order":
+		reorderPlaceList(w, r, listID, userID)
+	case r.Method == http.MethodDelete && len(urlParts) == 3 && urlParts[1] == "items":
+		removePlaceFromList(w, r, listID, userID, urlParts[2])
+	case r.Method == http.MethodPost && len(urlParts) == 2 && urlParts[1] == "share":
+		sharePlaceList(w, r, listID, userID)
+	case r.Method == http.MethodDelete && len(urlParts) == 2 && urlParts[1] == "share":
+		unsharePlaceList(w, r, listID, userID)
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// createPlaceList handles POST requests to create a list
+func createPlaceList(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	request, ok := readPlaceListRequest(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := services.CreatePlaceList(userID, request)
+	if err != nil {
+		placeListErrorResponse(w, "Failed to create list", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, list, http.StatusCreated)
+}
+
+// listPlaceLists handles GET requests for the caller's lists, or another user's public lists with ?userId=
+func listPlaceLists(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	ownerID := userID
+	if ownerIDStr := r.URL.Query().Get("userId"); ownerIDStr != "" {
+		id, err := primitive.ObjectIDFromHex(ownerIDStr)
+		if err != nil {
+			utils.ErrorResponse(w, "Invalid user ID format", 400, http.StatusBadRequest)
+			return
+		}
+		ownerID = id
+	}
+	if ownerID.IsZero() {
+		utils.ErrorResponse(w, "User ID is required", 400, http.StatusBadRequest)
+		return
+	}
+
+	lists, err := services.ListPlaceLists(ownerID, userID)
+	if err != nil {
+		placeListErrorResponse(w, "Failed to list lists", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, lists, http.StatusOK)
+}
+
+// getPlaceList handles GET requests for a single list
+func getPlaceList(w http.ResponseWriter, r *http.Request, listID primitive.ObjectID, userID primitive.ObjectID) {
+	list, err := services.GetPlaceList(listID, userID)
+	if err != nil {
+		placeListErrorResponse(w, "Failed to get list", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, list, http.StatusOK)
+}
+
+// getSharedPlaceList handles GET /list/shared/{code}
+func getSharedPlaceList(w http.ResponseWriter, r *http.Request, code string) {
+	list, err := services.GetSharedPlaceList(code)
+	if err != nil {
+		placeListErrorResponse(w, "Failed to get shared list", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, list, http.StatusOK)
+}
+
+// updatePlaceList handles PUT requests to rename a list or change its visibility
+func updatePlaceList(w http.ResponseWriter, r *http.Request, listID primitive.ObjectID, userID primitive.ObjectID) {
+	request, ok := readPlaceListRequest(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := services.UpdatePlaceList(listID, userID, request)
+	if err != nil {
+		placeListErrorResponse(w, "Failed to update list", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, list, http.StatusOK)
+}
+
+// deletePlaceList handles DELETE requests to remove a list
+func deletePlaceList(w http.ResponseWriter, r *http.Request, listID primitive.ObjectID, userID primitive.ObjectID) {
+	if err := services.DeletePlaceList(listID, userID); err != nil {
+		placeListErrorResponse(w, "Failed to delete list", err)
+		return
+	}
+
+	// Return success response
+	utils.SuccessResponse(w, map[string]string{"message": "List deleted successfully"}, http.StatusOK)
+}
+
+// addPlaceToList handles POST requests to save a place into a list
+func addPlaceToList(w http.ResponseWriter, r *http.Request, listID primitive.ObjectID, userID primitive.ObjectID) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.PlaceListItemRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	if request.PlaceID.IsZero() {
+		utils.ErrorResponse(w, "Place ID is required", 400, http.StatusBadRequest)
+		return
+	}
+	if len([]rune(request.Note)) > 200 {
+		utils.ErrorResponse(w, "Note must be at most 200 characters", 400, http.StatusBadRequest)
+		return
+	}
+
+	list, err := services.AddPlaceToList(listID, userID, request)
+	if err != nil {
+		placeListErrorResponse(w, "Failed to add place", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, list, http.StatusOK)
+}
+
+// removePlaceFromList handles DELETE requests to remove a place from a list
+func removePlaceFromList(w http.ResponseWriter, r *http.Request, listID primitive.ObjectID, userID primitive.ObjectID, placeID string) {
+	id, err := primitive.ObjectIDFromHex(placeID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid location ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	list, err := services.RemovePlaceFromList(listID, userID, id)
+	if err != nil {
+		placeListErrorResponse(w, "Failed to remove place", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, list, http.StatusOK)
+}
+
+// reorderPlaceList handles PUT requests that set the order of a list's places
+func reorderPlaceList(w http.ResponseWriter, r *http.Request, listID primitive.ObjectID, userID primitive.ObjectID) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.PlaceListOrderRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+	if len(request.PlaceIDs) == 0 {
+		utils.ErrorResponse(w, "Place IDs are required", 400, http.StatusBadRequest)
+		return
+	}
+
+	list, err := services.ReorderPlaceList(listID, userID, request)
+	if err != nil {
+		placeListErrorResponse(w, "Failed to reorder list", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, list, http.StatusOK)
+}
+
+// sharePlaceList handles POST requests that create a new share link for a list
+func sharePlaceList(w http.ResponseWriter, r *http.Request, listID primitive.ObjectID, userID primitive.ObjectID) {
+	list, err := services.SharePlaceList(listID, userID)
+	if err != nil {
+		placeListErrorResponse(w, "Failed to share list", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, list, http.StatusOK)
+}
+
+// unsharePlaceList handles DELETE requests that revoke a list's share link
+func unsharePlaceList(w http.ResponseWriter, r *http.Request, listID primitive.ObjectID, userID primitive.ObjectID) {
+	list, err := services.UnsharePlaceList(listID, userID)
+	if err != nil {
+		placeListErrorResponse(w, "Failed to unshare list", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, list, http.StatusOK)
+}
+
+// readPlaceListRequest parses and validates a list request body
+func readPlaceListRequest(w http.ResponseWriter, r *http.Request) (models.PlaceListRequest, bool) {
+	var request models.PlaceListRequest
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return request, false
+	}
+	defer r.Body.Close()
+
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return request, false
+	}
+
+	if err := validatePlaceListRequest(request); err != nil {
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+		return request, false
+	}
+
+	return request, true
+}
+
+// validatePlaceListRequest checks the name and description lengths of a list
+func validatePlaceListRequest(request models.PlaceListRequest) error {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len([]rune(name)) > 50 {
+		return fmt.Errorf("name must be at most 50 characters")
+	}
+	if len([]rune(request.Description)) > 500 {
+		return fmt.Errorf("description must be at most 500 characters")
+	}
+	return nil
+}
+
+// placeListErrorResponse maps place list errors to HTTP responses
+func placeListErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "no place list found"):
+		utils.ErrorResponse(w, "List not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "no location found"):
+		utils.ErrorResponse(w, "Location not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "not authorized"):
+		utils.ErrorResponse(w, "Not authorized to modify this list", 403, http.StatusForbidden)
+	case strings.Contains(err.Error(), "already in list"):
+		utils.ErrorResponse(w, err.Error(), 409, http.StatusConflict)
+	case strings.Contains(err.Error(), "invalid list"):
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+	default:
+		utils.ErrorResponse(w, message+": "+err.Error(), 500, http.StatusInternalServerError)
+	}
+}
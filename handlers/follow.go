@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HandleFollow handles following users and places
+//
+//	POST   /follow                          follows the user or place in the body
+//	GET    /follow?userId=&type=            lists what a user follows
+//	DELETE /follow/{type}/{id}              unfollows
+//	GET    /follow/{type}/{id}/followers    lists the followers of a user or place
+func HandleFollow(w http.ResponseWriter, r *http.Request) {
+	urlParts := utils.ExtractUrlParam(r.URL.Path, "/follow")
+
+	switch {
+	case r.Method == http.MethodPost && len(urlParts) == 0:
+		followTarget(w, r)
+	case r.Method == http.MethodGet && len(urlParts) == 0:
+		listFollowing(w, r)
+	case r.Method == http.MethodDelete && len(urlParts) == 2:
+		unfollowTarget(w, r, urlParts[0], urlParts[1])
+	case r.Method == http.MethodGet && len(urlParts) == 3 && urlParts[2] == "followers":
+		listFollowers(w, r, urlParts[0], urlParts[1])
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleFeed handles GET /feed?cursor=&limit=, the calling user's activity feed
+func HandleFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", 405, http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	limit, ok := followLimitParam(w, r, 50)
+	if !ok {
+		return
+	}
+
+	page, err := services.GetFeed(userID, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		followErrorResponse(w, "Failed to get feed", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, page, http.StatusOK)
+}
+
+// followTarget handles POST /follow
+func followTarget(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.FollowRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	// Validate required fields
+	if request.TargetType == "" || request.TargetID.IsZero() {
+		utils.ErrorResponse(w, "Target type and target ID are required", 400, http.StatusBadRequest)
+		return
+	}
+
+	follow, err := services.FollowTarget(userID, request)
+	if err != nil {
+		followErrorResponse(w, "Failed to follow", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, follow, http.StatusOK)
+}
+
+// unfollowTarget handles DELETE /follow/{type}/{id}
+func unfollowTarget(w http.ResponseWriter, r *http.Request, targetType string, targetID string) {
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid target ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	if err := services.UnfollowTarget(userID, targetType, id); err != nil {
+		followErrorResponse(w, "Failed to unfollow", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, map[string]string{"message": "Unfollowed successfully"}, http.StatusOK)
+}
+
+// listFollowing handles GET /follow?userId=&type=
+func listFollowing(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	userID, err := primitive.ObjectIDFromHex(query.Get("userId"))
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid user ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	limit, ok := followLimitParam(w, r, 0)
+	if !ok {
+		return
+	}
+
+	follows, err := services.ListFollowing(userID, query.Get("type"), limit)
+	if err != nil {
+		followErrorResponse(w, "Failed to list follows", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, follows, http.StatusOK)
+}
+
+// listFollowers handles GET /follow/{type}/{id}/followers
+func listFollowers(w http.ResponseWriter, r *http.Request, targetType string, targetID string) {
+	id, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid target ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	limit, ok := followLimitParam(w, r, 0)
+	if !ok {
+		return
+	}
+
+	follows, err := services.ListFollowers(targetType, id, limit)
+	if err != nil {
+		followErrorResponse(w, "Failed to list followers", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, follows, http.StatusOK)
+}
+
+// followLimitParam parses the optional limit query parameter, capped at max when max is set
+func followLimitParam(w http.ResponseWriter, r *http.Request, max int64) (int64, bool) {
+	limitParam := r.URL.Query().Get("limit")
+	if limitParam == "" {
+		return 0, true
+	}
+
+	limit, err := strconv.ParseInt(limitParam, 10, 64)
+	if err != nil || limit <= 0 {
+		utils.ErrorResponse(w, "Invalid limit parameter", 400, http.StatusBadRequest)
+		return 0, false
+	}
+	if max > 0 && limit > max {
+		limit = max
+	}
+	return limit, true
+}
+
+// followErrorResponse maps follow and feed errors to HTTP responses
+func followErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "blocked by user"):
+		utils.ErrorResponse(w, "This user has blocked you", 403, http.StatusForbidden)
+	case strings.Contains(err.Error(), "invalid follow"), strings.Contains(err.Error(), "invalid cursor"):
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+	case strings.HasPrefix(err.Error(), "no follow found"):
+		utils.ErrorResponse(w, "Not following", 404, http.StatusNotFound)
+	case strings.HasPrefix(err.Error(), "no user found"):
+		utils.ErrorResponse(w, "User not found", 404, http.StatusNotFound)
+	case strings.HasPrefix(err.Error(), "no location found"):
+		utils.ErrorResponse(w, "Place not found", 404, http.StatusNotFound)
+	default:
+		utils.ErrorResponse(w, message+": "+err.Error(), 500, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// handlePetHealth handles /pet/{id}/health and its sub paths
+func handlePetHealth(w http.ResponseWriter, r *http.Request, petID string, urlParts []string) {
+	id, err := primitive.ObjectIDFromHex(petID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid pet ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	// Only members of the pet may read or modify its health log
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	var recordID string
+	if len(urlParts) > 0 {
+		recordID = urlParts[0]
+	}
+
+	switch {
+	case r.Method == http.MethodPost && recordID == "":
+		createHealthRecord(w, r, id, userID)
+	case r.Method == http.MethodGet && recordID == "":
+		listHealthRecords(w, r, id, userID)
+	case r.Method == http.MethodPost && len(urlParts) == 2 && urlParts[1] == "attachments":
+		uploadHealthAttachment(w, r, id, userID, recordID)
+	case r.Method == http.MethodGet && len(urlParts) == 1:
+		getHealthRecord(w, r, id, userID, recordID)
+	case r.Method == http.MethodPut && len(urlParts) == 1:
+		updateHealthRecord(w, r, id, userID, recordID)
+	case r.Method == http.MethodDelete && len(urlParts) == 1:
+		deleteHealthRecord(w, r, id, userID, recordID)
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// createHealthRecord handles POST requests to add a health record
+func createHealthRecord(w http.ResponseWriter, r *http.Request, petID primitive.ObjectID, userID primitive.ObjectID) {
+	request, ok := readHealthRecordRequest(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := services.CreateHealthRecord(petID, userID, request)
+	if err != nil {
+		petResourceErrorResponse(w, "Failed to create health record", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, record, http.StatusCreated)
+}
+
+// listHealthRecords handles GET requests to list a pet's health records
+func listHealthRecords(w http.ResponseWriter, r *http.Request, petID primitive.ObjectID, userID primitive.ObjectID) {
+	recordType := r.URL.Query().Get("type")
+	if recordType != "" && !isValidHealthRecordType(recordType) {
+		utils.ErrorResponse(w, "Invalid record type", 400, http.StatusBadRequest)
+		return
+	}
+
+	records, err := services.ListHealthRecords(petID, userID, recordType)
+	if err != nil {
+		petResourceErrorResponse(w, "Failed to list health records", err)
+		return
+	}
+
+	if records == nil {
+		records = make([]models.HealthRecord, 0)
+	}
+	// Return response
+	utils.SuccessResponse(w, records, http.StatusOK)
+}
+
+// getHealthRecord handles GET requests to retrieve a single health record
+func getHealthRecord(w http.ResponseWriter, r *http.Request, petID primitive.ObjectID, userID primitive.ObjectID, recordID string) {
+	id, err := primitive.ObjectIDFromHex(recordID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid health record ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	record, err := services.GetHealthRecord(petID, id, userID)
+	if err != nil {
+		petResourceErrorResponse(w, "Failed to get health record", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, record, http.StatusOK)
+}
+
+// updateHealthRecord handles PUT requests to update a health record
+func updateHealthRecord(w http.ResponseWriter, r *http.Request, petID primitive.ObjectID, userID primitive.ObjectID, recordID string) {
+	id, err := primitive.ObjectIDFromHex(recordID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid health record ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	request, ok := readHealthRecordRequest(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := services.UpdateHealthRecord(petID, id, userID, request)
+	if err != nil {
+		petResourceErrorResponse(w, "Failed to update health record", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, record, http.StatusOK)
+}
+
+// deleteHealthRecord handles DELETE requests to remove a health record
+func deleteHealthRecord(w http.ResponseWriter, r *http.Request, petID primitive.ObjectID, userID primitive.ObjectID, recordID string) {
+	id, err := primitive.ObjectIDFromHex(recordID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid health record ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	if err := services.DeleteHealthRecord(petID, id, userID); err != nil {
+		petResourceErrorResponse(w, "Failed to delete health record", err)
+		return
+	}
+
+	// Return success response
+	utils.SuccessResponse(w, map[string]string{"message": "Health record deleted successfully"}, http.StatusOK)
+}
+
+// uploadHealthAttachment handles multipart uploads of health record attachments
+func uploadHealthAttachment(w http.ResponseWriter, r *http.Request, petID primitive.ObjectID, userID primitive.ObjectID, recordID string) {
+	id, err := primitive.ObjectIDFromHex(recordID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid health record ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	// Parse multipart form with 10 MB max memory
+	const maxMemory = 10 * 1024 * 1024 // 10 MB
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
+		utils.ErrorResponse(w, "Failed to parse form: "+err.Error(), 400, http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(w, "No file provided or invalid file field", 400, http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	// Vet documents are often PDFs, so accept them alongside images
+	contentType := header.Header.Get("Content-Type")
+	if !isAllowedImageType(contentType) && contentType != "application/pdf" {
+		utils.ErrorResponse(w, "Unsupported file type: only images and PDFs are allowed", 400, http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Received health attachment: %s, size: %d bytes, type: %s", header.Filename, header.Size, contentType)
+
+	record, err := services.AddHealthRecordAttachment(petID, id, userID, file, header.Filename, contentType, header.Size)
+	if err != nil {
+		petResourceErrorResponse(w, "Failed to upload attachment", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, record, http.StatusOK)
+}
+
+// readHealthRecordRequest parses and validates a health record request body
+func readHealthRecordRequest(w http.ResponseWriter, r *http.Request) (models.HealthRecordRequest, bool) {
+	var request models.HealthRecordRequest
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return request, false
+	}
+	defer r.Body.Close()
+
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return request, false
+	}
+
+	if err := validateHealthRecordRequest(request); err != nil {
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+		return request, false
+	}
+
+	return request, true
+}
+
+// validateHealthRecordRequest checks the fields required by each record type
+func validateHealthRecordRequest(request models.HealthRecordRequest) error {
+	if !isValidHealthRecordType(request.Type) {
+		return fmt.Errorf("type must be one of vaccination, deworming, vet_visit, medication")
+	}
+	if request.Date.IsZero() {
+		return fmt.Errorf("date is required")
+	}
+
+	switch request.Type {
+	case models.HealthRecordVaccination:
+		if request.VaccineName == "" {
+			return fmt.Errorf("vaccine name is required")
+		}
+	case models.HealthRecordVetVisit:
+		if request.Clinic == "" {
+			return fmt.Errorf("clinic is required")
+		}
+	case models.HealthRecordMedication:
+		if request.Medication == "" {
+			return fmt.Errorf("medication is required")
+		}
+		if request.EndDate != nil && request.EndDate.Before(request.Date) {
+			return fmt.Errorf("end date must not be before date")
+		}
+	}
+
+	if request.NextDueDate != nil && request.NextDueDate.Before(request.Date) {
+		return fmt.Errorf("next due date must not be before date")
+	}
+
+	return nil
+}
+
+// isValidHealthRecordType checks if the record type is supported
+func isValidHealthRecordType(recordType string) bool {
+	switch recordType {
+	case models.HealthRecordVaccination, models.HealthRecordDeworming, models.HealthRecordVetVisit, models.HealthRecordMedication:
+		return true
+	}
+	return false
+}
+
+// petResourceErrorResponse maps errors of member-only pet sub-resources to HTTP responses
+func petResourceErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "no pet found"):
+		utils.ErrorResponse(w, "Pet not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "no health record found"):
+		utils.ErrorResponse(w, "Health record not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "no weight record found"):
+		utils.ErrorResponse(w, "Weight record not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "no photo found"):
+		utils.ErrorResponse(w, "Photo not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "no invite found"):
+		utils.ErrorResponse(w, "Invite not found or expired", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "not authorized"):
+		utils.ErrorResponse(w, "Not authorized to access this pet", 403, http.StatusForbidden)
+	case strings.Contains(err.Error(), "no user found"):
+		utils.ErrorResponse(w, "User not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "already a member"), strings.Contains(err.Error(), "cannot remove the owner"),
+		strings.Contains(err.Error(), "already has an owner"):
+		utils.ErrorResponse(w, err.Error(), 409, http.StatusConflict)
+	case strings.Contains(err.Error(), "invalid invite"), strings.Contains(err.Error(), "invalid attachment"),
+		strings.Contains(err.Error(), "invalid measuredAt"):
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+	default:
+		utils.ErrorResponse(w, message+": "+err.Error(), 500, http.StatusInternalServerError)
+	}
+}
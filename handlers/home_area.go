@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// handleUserHomeArea handles /user/{id}/home-area. Home areas are private, so only the user may read or change theirs.
+func handleUserHomeArea(w http.ResponseWriter, r *http.Request, userID string) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid user ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	requestUserID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+	if requestUserID != id {
+		utils.ErrorResponse(w, "Not authorized to access this home area", 403, http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		getUserHomeArea(w, r, id)
+	case http.MethodPut:
+		setUserHomeArea(w, r, id)
+	case http.MethodDelete:
+		clearUserHomeArea(w, r, id)
+	default:
+		utils.ErrorResponse(w, "Method not allowed", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// getUserHomeArea handles GET requests for a user's home area
+func getUserHomeArea(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	homeArea, err := services.GetUserHomeArea(userID)
+	if err != nil {
+		homeAreaErrorResponse(w, "Failed to get home area", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, homeArea, http.StatusOK)
+}
+
+// setUserHomeArea handles PUT requests to set a user's home area and alert preferences
+func setUserHomeArea(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.UserHomeAreaRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	// Validate request
+	if request.Latitude < -90 || request.Latitude > 90 {
+		utils.ErrorResponse(w, "Latitude must be between -90 and 90", 400, http.StatusBadRequest)
+		return
+	}
+	if request.Longitude < -180 || request.Longitude > 180 {
+		utils.ErrorResponse(w, "Longitude must be between -180 and 180", 400, http.StatusBadRequest)
+		return
+	}
+	if request.RadiusMeters < 0 {
+		utils.ErrorResponse(w, "Radius cannot be negative", 400, http.StatusBadRequest)
+		return
+	}
+
+	homeArea, err := services.SetUserHomeArea(userID, request)
+	if err != nil {
+		homeAreaErrorResponse(w, "Failed to set home area", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, homeArea, http.StatusOK)
+}
+
+// clearUserHomeArea handles DELETE requests to remove a user's home area
+func clearUserHomeArea(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	if err := services.ClearUserHomeArea(userID); err != nil {
+		homeAreaErrorResponse(w, "Failed to clear home area", err)
+		return
+	}
+
+	// Return success response
+	utils.SuccessResponse(w, map[string]string{"message": "Home area cleared successfully"}, http.StatusOK)
+}
+
+// homeAreaErrorResponse maps home area errors to HTTP responses
+func homeAreaErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "no user found"):
+		utils.ErrorResponse(w, "User not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "no home area found"):
+		utils.ErrorResponse(w, "Home area not set", 404, http.StatusNotFound)
+	default:
+		utils.ErrorResponse(w, message+": "+err.Error(), 500, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HandleLost handles lost pet reports, their resolution and found pet sightings
+func HandleLost(w http.ResponseWriter, r *http.Request) {
+	urlParts := utils.ExtractUrlParam(r.URL.Path, "/lost")
+
+	var reportID string
+	if len(urlParts) > 0 {
+		reportID = urlParts[0]
+	}
+
+	switch {
+	case r.Method == http.MethodPost && reportID == "":
+		createLostReport(w, r)
+	case r.Method == http.MethodGet && reportID == "":
+		searchLostReports(w, r)
+	case r.Method == http.MethodPost && reportID == "sightings" && len(urlParts) == 1:
+		createFoundSighting(w, r)
+	case r.Method == http.MethodGet && len(urlParts) == 1:
+		getLostReport(w, r, reportID)
+	case r.Method == http.MethodPost && len(urlParts) == 2 && urlParts[1] == "resolve":
+		resolveLostReport(w, r, reportID)
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// createLostReport handles POST requests to report a lost pet
+func createLostReport(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.LostPetReportRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	// Validate request
+	if request.PetID.IsZero() {
+		utils.ErrorResponse(w, "Pet ID is required", 400, http.StatusBadRequest)
+		return
+	}
+	if request.Latitude < -90 || request.Latitude > 90 || request.Longitude < -180 || request.Longitude > 180 {
+		utils.ErrorResponse(w, "Invalid last seen coordinates", 400, http.StatusBadRequest)
+		return
+	}
+	if request.LastSeenAt.After(time.Now()) {
+		utils.ErrorResponse(w, "Last seen time cannot be in the future", 400, http.StatusBadRequest)
+		return
+	}
+	switch request.ContactPreference {
+	case models.ContactPreferencePhone:
+		if request.ContactPhone == "" {
+			utils.ErrorResponse(w, "Contact phone is required", 400, http.StatusBadRequest)
+			return
+		}
+	case models.ContactPreferenceWechat, models.ContactPreferenceInApp:
+	case "":
+		request.ContactPreference = models.ContactPreferenceInApp
+	default:
+		utils.ErrorResponse(w, "Contact preference must be one of phone, wechat, in_app", 400, http.StatusBadRequest)
+		return
+	}
+
+	report, err := services.CreateLostPetReport(userID, request)
+	if err != nil {
+		lostErrorResponse(w, "Failed to create lost report", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, report, http.StatusCreated)
+}
+
+// searchLostReports handles GET requests to list open lost pet reports near a point
+func searchLostReports(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	lat, err := strconv.ParseFloat(query.Get("latitude"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		utils.ErrorResponse(w, "Invalid latitude parameter", 400, http.StatusBadRequest)
+		return
+	}
+
+	lng, err := strconv.ParseFloat(query.Get("longitude"), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		utils.ErrorResponse(w, "Invalid longitude parameter", 400, http.StatusBadRequest)
+		return
+	}
+
+	search := models.LostPetSearchRequest{
+		Latitude:  lat,
+		Longitude: lng,
+		Species:   query.Get("species"),
+	}
+
+	if radiusStr := query.Get("radius"); radiusStr != "" {
+		radius, err := strconv.ParseFloat(radiusStr, 64)
+		if err != nil || radius <= 0 {
+			utils.ErrorResponse(w, "Invalid radius parameter", 400, http.StatusBadRequest)
+			return
+		}
+		search.Radius = radius
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || limit <= 0 {
+			utils.ErrorResponse(w, "Invalid limit parameter", 400, http.StatusBadRequest)
+			return
+		}
+		search.Limit = limit
+	}
+
+	results, err := services.SearchLostPetReports(search)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to search lost reports: "+err.Error(), 500, http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, results, http.StatusOK)
+}
+
+// getLostReport handles GET requests to retrieve a lost pet report
+func getLostReport(w http.ResponseWriter, r *http.Request, reportID string) {
+	id, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid lost report ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	report, err := services.GetLostPetReport(id)
+	if err != nil {
+		lostErrorResponse(w, "Failed to get lost report", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, report, http.StatusOK)
+}
+
+// resolveLostReport handles POST requests to close a lost pet report
+func resolveLostReport(w http.ResponseWriter, r *http.Request, reportID string) {
+	id, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid lost report ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	// The resolution note is optional
+	var request models.LostPetResolveRequest
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &request); err != nil {
+			utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := services.ResolveLostPetReport(id, userID, request)
+	if err != nil {
+		lostErrorResponse(w, "Failed to resolve lost report", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, report, http.StatusOK)
+}
+
+// createFoundSighting handles POST requests reporting a found pet, returning matching lost reports
+func createFoundSighting(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.FoundPetSightingRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	// Validate request
+	if request.Latitude < -90 || request.Latitude > 90 || request.Longitude < -180 || request.Longitude > 180 {
+		utils.ErrorResponse(w, "Invalid sighting coordinates", 400, http.StatusBadRequest)
+		return
+	}
+	switch request.Species {
+	case "", models.SpeciesDog, models.SpeciesCat, models.SpeciesOther:
+	default:
+		utils.ErrorResponse(w, "Species must be one of dog, cat, other", 400, http.StatusBadRequest)
+		return
+	}
+	if request.SeenAt.After(time.Now()) {
+		utils.ErrorResponse(w, "Seen time cannot be in the future", 400, http.StatusBadRequest)
+		return
+	}
+
+	response, err := services.CreateFoundPetSighting(userID, request)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to report sighting: "+err.Error(), 500, http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, response, http.StatusCreated)
+}
+
+// lostErrorResponse maps lost report errors to HTTP responses
+func lostErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "no lost report found"):
+		utils.ErrorResponse(w, "Lost report not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "already has an open lost report"), strings.Contains(err.Error(), "already resolved"):
+		utils.ErrorResponse(w, err.Error(), 409, http.StatusConflict)
+	default:
+		petResourceErrorResponse(w, message, err)
+	}
+}
@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// defaultPollWait and maxPollWait bound how long a long poll waits for new messages
+	defaultPollWait = 25 * time.Second
+	maxPollWait     = 55 * time.Second
+)
+
+// HandleMessage handles direct messages between users.
+// The WebSocket and long polling streams also take the session token as ?token=, like /push.
+//
+//	GET  /message/ws                                 real time message events over WebSocket
+//	GET  /message/poll?after=&wait=                  long polling fallback for clients without WebSocket
+//	GET  /message/unread                             total unread count
+//	GET  /message/conversations                      lists the caller's conversations
+//	POST /message/conversations                      opens a conversation with a user
+//	GET  /message/conversations/{id}/messages        pages through messages, newest first
+//	POST /message/conversations/{id}/messages        sends a message
+//	POST /message/conversations/{id}/read            marks the conversation read
+func HandleMessage(w http.ResponseWriter, r *http.Request) {
+	urlParts := utils.ExtractUrlParam(r.URL.Path, "/message")
+
+	isStream := len(urlParts) == 1 && r.Method == http.MethodGet && (urlParts[0] == "ws" || urlParts[0] == "poll")
+	var userID primitive.ObjectID
+	var err error
+	if isStream {
+		userID, err = utils.GetStreamUserID(r)
+	} else {
+		userID, err = utils.GetRequestUserID(r)
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "user is banned") {
+			utils.ErrorResponse(w, "Your account has been banned", 403, http.StatusForbidden)
+		} else {
+			utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		}
+		return
+	}
+
+	if len(urlParts) == 1 && r.Method == http.MethodGet {
+		switch urlParts[0] {
+		case "ws":
+			handleMessageWebSocket(w, r, userID)
+			return
+		case "poll":
+			pollMessages(w, r, userID)
+			return
+		case "unread":
+			getUnreadMessageCount(w, r, userID)
+			return
+		}
+	}
+
+	if len(urlParts) == 0 || urlParts[0] != "conversations" {
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if len(urlParts) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			listConversations(w, r, userID)
+		case http.MethodPost:
+			openConversation(w, r, userID)
+		default:
+			utils.ErrorResponse(w, "Method not allowed", 405, http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	conversationID, err := primitive.ObjectIDFromHex(urlParts[1])
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid conversation ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(urlParts) == 3 && urlParts[2] == "messages":
+		listMessages(w, r, conversationID, userID)
+	case r.Method == http.MethodPost && len(urlParts) == 3 && urlParts[2] == "messages":
+		sendMessage(w, r, conversationID, userID)
+	case r.Method == http.MethodPost && len(urlParts) == 3 && urlParts[2] == "read":
+		markConversationRead(w, r, conversationID, userID)
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// handleMessageWebSocket streams the user's message events as JSON text frames until the client disconnects
+func handleMessageWebSocket(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	if !utils.IsWebSocketUpgrade(r) {
+		utils.ErrorResponse(w, "WebSocket upgrade required, use /message/poll instead", 426, http.StatusUpgradeRequired)
+		return
+	}
+
+	streamWebSocket(w, r, userID, []string{models.PushEventMessage, models.PushEventMessageRead}, encodeMessageEvent)
+}
+
+// encodeMessageEvent sends the message event carried by a push event on its own
+func encodeMessageEvent(event models.PushEvent) ([]byte, error) {
+	return event.Data, nil
+}
+
+// pollMessages handles GET /message/poll, waiting for new messages after the given cursor
+func pollMessages(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	query := r.URL.Query()
+
+	wait := defaultPollWait
+	if waitParam := query.Get("wait"); waitParam != "" {
+		seconds, err := strconv.Atoi(waitParam)
+		if err != nil || seconds < 0 {
+			utils.ErrorResponse(w, "Invalid wait parameter", 400, http.StatusBadRequest)
+			return
+		}
+		wait = time.Duration(seconds) * time.Second
+		if wait > maxPollWait {
+			wait = maxPollWait
+		}
+	}
+
+	response, err := services.PollMessages(r.Context(), userID, query.Get("after"), wait)
+	if err != nil {
+		messageErrorResponse(w, "Failed to poll messages", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, response, http.StatusOK)
+}
+
+// getUnreadMessageCount handles GET /message/unread
+func getUnreadMessageCount(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	unread, err := services.GetUnreadMessageCount(userID)
+	if err != nil {
+		messageErrorResponse(w, "Failed to count unread messages", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, map[string]int64{"unread": unread}, http.StatusOK)
+}
+
+// listConversations handles GET /message/conversations
+func listConversations(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	limit, ok := followLimitParam(w, r, 100)
+	if !ok {
+		return
+	}
+
+	conversations, err := services.ListConversations(userID, limit)
+	if err != nil {
+		messageErrorResponse(w, "Failed to list conversations", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, conversations, http.StatusOK)
+}
+
+// openConversation handles POST /message/conversations
+func openConversation(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.ConversationRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+	if request.UserID.IsZero() {
+		utils.ErrorResponse(w, "User ID is required", 400, http.StatusBadRequest)
+		return
+	}
+
+	conversation, err := services.OpenConversation(userID, request.UserID)
+	if err != nil {
+		messageErrorResponse(w, "Failed to open conversation", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, conversation, http.StatusOK)
+}
+
+// listMessages handles GET /message/conversations/{id}/messages?before=&limit=
+func listMessages(w http.ResponseWriter, r *http.Request, conversationID primitive.ObjectID, userID primitive.ObjectID) {
+	limit, ok := followLimitParam(w, r, 100)
+	if !ok {
+		return
+	}
+
+	messages, err := services.ListMessages(conversationID, userID, r.URL.Query().Get("before"), limit)
+	if err != nil {
+		messageErrorResponse(w, "Failed to list messages", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, messages, http.StatusOK)
+}
+
+// sendMessage handles POST /message/conversations/{id}/messages
+func sendMessage(w http.ResponseWriter, r *http.Request, conversationID primitive.ObjectID, userID primitive.ObjectID) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.MessageRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	message, err := services.SendMessage(conversationID, userID, request)
+	if err != nil {
+		messageErrorResponse(w, "Failed to send message", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, message, http.StatusCreated)
+}
+
+// markConversationRead handles POST /message/conversations/{id}/read
+func markConversationRead(w http.ResponseWriter, r *http.Request, conversationID primitive.ObjectID, userID primitive.ObjectID) {
+	receipt, err := services.MarkConversationRead(conversationID, userID)
+	if err != nil {
+		messageErrorResponse(w, "Failed to mark conversation read", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, receipt, http.StatusOK)
+}
+
+// messageErrorResponse maps messaging errors to HTTP responses
+func messageErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "user is banned"):
+		utils.ErrorResponse(w, "Your account has been banned", 403, http.StatusForbidden)
+	case strings.Contains(err.Error(), "blocked by user"):
+		utils.ErrorResponse(w, "This user has blocked you", 403, http.StatusForbidden)
+	case strings.Contains(err.Error(), "rate limit exceeded"):
+		utils.ErrorResponse(w, err.Error(), 429, http.StatusTooManyRequests)
+	case strings.Contains(err.Error(), "invalid message"), strings.Contains(err.Error(), "invalid cursor"):
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+	case strings.HasPrefix(err.Error(), "no conversation found"):
+		utils.ErrorResponse(w, "Conversation not found", 404, http.StatusNotFound)
+	case strings.HasPrefix(err.Error(), "no user found"):
+		utils.ErrorResponse(w, "User not found", 404, http.StatusNotFound)
+	case strings.HasPrefix(err.Error(), "no location found"), strings.HasPrefix(err.Error(), "no pet found"):
+		utils.ErrorResponse(w, "Shared place or pet not found", 404, http.StatusNotFound)
+	default:
+		utils.ErrorResponse(w, message+": "+err.Error(), 500, http.StatusInternalServerError)
+	}
+}
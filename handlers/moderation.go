@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HandleModeration handles the moderator queues of content held by content checks and of reported content
+func HandleModeration(w http.ResponseWriter, r *http.Request) {
+	urlParts := utils.ExtractUrlParam(r.URL.Path, "/moderation")
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(urlParts) == 1 && urlParts[0] == "reports":
+		listReportQueue(w, r, userID)
+	case r.Method == http.MethodGet && len(urlParts) == 3 && urlParts[0] == "reports":
+		listTargetReports(w, r, userID, urlParts[1], urlParts[2])
+	case r.Method == http.MethodPost && len(urlParts) == 1 && urlParts[0] == "actions":
+		applyModerationAction(w, r, userID)
+	case r.Method == http.MethodGet && len(urlParts) == 1 && urlParts[0] == "actions":
+		listModerationActions(w, r, userID)
+	case r.Method == http.MethodGet && len(urlParts) == 1:
+		listHeldContent(w, r, userID, urlParts[0])
+	case r.Method == http.MethodPost && len(urlParts) == 3 && (urlParts[2] == "approve" || urlParts[2] == "reject"):
+		moderateHeldContent(w, r, userID, urlParts[0], urlParts[1], urlParts[2] == "approve")
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// listHeldContent handles GET /moderation/{type}, listing reviews, pets, places or images waiting for a moderator
+func listHeldContent(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID, contentType string) {
+	var limit int64
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsedLimit, err := strconv.ParseInt(limitParam, 10, 64)
+		if err != nil || parsedLimit <= 0 {
+			utils.ErrorResponse(w, "Invalid limit parameter", 400, http.StatusBadRequest)
+			return
+		}
+		limit = parsedLimit
+	}
+
+	content, err := services.ListHeldContent(userID, contentType, limit)
+	if err != nil {
+		moderationErrorResponse(w, "Failed to list held content", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, content, http.StatusOK)
+}
+
+// moderateHeldContent handles POST /moderation/{type}/{id}/approve and /reject
+func moderateHeldContent(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID, contentType string, contentID string, approve bool) {
+	id, err := primitive.ObjectIDFromHex(contentID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid content ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	if err := services.ModerateHeldContent(userID, contentType, id, approve); err != nil {
+		moderationErrorResponse(w, "Failed to moderate content", err)
+		return
+	}
+
+	status := models.ModerationRejected
+	if approve {
+		status = models.ModerationApproved
+	}
+
+	// Return response
+	utils.SuccessResponse(w, map[string]string{"id": contentID, "moderationStatus": status}, http.StatusOK)
+}
+
+// listReportQueue handles GET /moderation/reports?type=, listing reported targets with open reports
+func listReportQueue(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	limit, ok := moderationLimitParam(w, r)
+	if !ok {
+		return
+	}
+
+	cases, err := services.ListReportQueue(userID, r.URL.Query().Get("type"), limit)
+	if err != nil {
+		moderationErrorResponse(w, "Failed to list report queue", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, cases, http.StatusOK)
+}
+
+// listTargetReports handles GET /moderation/reports/{type}/{id}, listing every report against a target
+func listTargetReports(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID, targetType string, targetID string) {
+	id, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid target ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	reports, err := services.ListTargetReports(userID, targetType, id)
+	if err != nil {
+		moderationErrorResponse(w, "Failed to list reports", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, reports, http.StatusOK)
+}
+
+// applyModerationAction handles POST /moderation/actions, dismissing, hiding, deleting or banning a reported target
+func applyModerationAction(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.ModerationActionRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	// Validate required fields
+	if request.TargetType == "" || request.TargetID.IsZero() {
+		utils.ErrorResponse(w, "Target type and target ID are required", 400, http.StatusBadRequest)
+		return
+	}
+	if request.Action == "" {
+		utils.ErrorResponse(w, "Action is required", 400, http.StatusBadRequest)
+		return
+	}
+
+	action, err := services.ApplyModerationAction(userID, request)
+	if err != nil {
+		moderationErrorResponse(w, "Failed to apply moderation action", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, action, http.StatusOK)
+}
+
+// listModerationActions handles GET /moderation/actions?type=&id=, the moderation audit trail
+func listModerationActions(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	limit, ok := moderationLimitParam(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	var targetID *primitive.ObjectID
+	if idParam := query.Get("id"); idParam != "" {
+		id, err := primitive.ObjectIDFromHex(idParam)
+		if err != nil {
+			utils.ErrorResponse(w, "Invalid target ID format", 400, http.StatusBadRequest)
+			return
+		}
+		targetID = &id
+	}
+
+	actions, err := services.ListModerationActions(userID, query.Get("type"), targetID, limit)
+	if err != nil {
+		moderationErrorResponse(w, "Failed to list moderation actions", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, actions, http.StatusOK)
+}
+
+// moderationLimitParam parses the optional limit query parameter, writing an error response when it is invalid
+func moderationLimitParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	limitParam := r.URL.Query().Get("limit")
+	if limitParam == "" {
+		return 0, true
+	}
+
+	limit, err := strconv.ParseInt(limitParam, 10, 64)
+	if err != nil || limit <= 0 {
+		utils.ErrorResponse(w, "Invalid limit parameter", 400, http.StatusBadRequest)
+		return 0, false
+	}
+	return limit, true
+}
+
+// moderationErrorResponse maps moderation errors to HTTP responses
+func moderationErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not authorized"):
+		utils.ErrorResponse(w, err.Error(), 403, http.StatusForbidden)
+	case strings.Contains(err.Error(), "invalid content type"), strings.Contains(err.Error(), "invalid moderation action"):
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+	case strings.HasPrefix(err.Error(), "no held content found"):
+		utils.ErrorResponse(w, "Content not found or not held for moderation", 404, http.StatusNotFound)
+	case strings.HasPrefix(err.Error(), "no review found"), strings.HasPrefix(err.Error(), "no location found"),
+		strings.HasPrefix(err.Error(), "no pet found"), strings.HasPrefix(err.Error(), "no user found"):
+		utils.ErrorResponse(w, "Reported content not found", 404, http.StatusNotFound)
+	default:
+		utils.ErrorResponse(w, message+": "+err.Error(), 500, http.StatusInternalServerError)
+	}
+}
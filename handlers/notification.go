@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HandleNotification handles the calling user's notification inbox
+//
+//	GET  /notification?unread=&before=&limit=    pages through the inbox, newest first
+//	GET  /notification/unread                    unread count
+//	POST /notification/read-all?type=            marks all, or all of one type, read
+//	POST /notification/{id}/read                 marks a notification read
+//	GET  /notification/preferences               which notification types are on
+//	PUT  /notification/preferences              switches notification types on or off
+func HandleNotification(w http.ResponseWriter, r *http.Request) {
+	urlParts := utils.ExtractUrlParam(r.URL.Path, "/notification")
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(urlParts) == 0:
+		listNotifications(w, r, userID)
+	case r.Method == http.MethodGet && len(urlParts) == 1 && urlParts[0] == "unread":
+		getUnreadNotificationCount(w, r, userID)
+	case r.Method == http.MethodPost && len(urlParts) == 1 && urlParts[0] == "read-all":
+		markAllNotificationsRead(w, r, userID)
+	case r.Method == http.MethodPost && len(urlParts) == 2 && urlParts[1] == "read":
+		markNotificationRead(w, r, userID, urlParts[0])
+	case r.Method == http.MethodGet && len(urlParts) == 1 && urlParts[0] == "preferences":
+		getNotificationPreferences(w, r, userID)
+	case r.Method == http.MethodPut && len(urlParts) == 1 && urlParts[0] == "preferences":
+		updateNotificationPreferences(w, r, userID)
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// listNotifications handles GET /notification
+func listNotifications(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	limit, ok := followLimitParam(w, r, 100)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	page, err := services.ListNotifications(userID, query.Get("unread") == "true", query.Get("before"), limit)
+	if err != nil {
+		notificationErrorResponse(w, "Failed to list notifications", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, page, http.StatusOK)
+}
+
+// getUnreadNotificationCount handles GET /notification/unread
+func getUnreadNotificationCount(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	unread, err := services.GetUnreadNotificationCount(userID)
+	if err != nil {
+		notificationErrorResponse(w, "Failed to count unread notifications", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, map[string]int64{"unread": unread}, http.StatusOK)
+}
+
+// markAllNotificationsRead handles POST /notification/read-all
+func markAllNotificationsRead(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	marked, err := services.MarkAllNotificationsRead(userID, r.URL.Query().Get("type"))
+	if err != nil {
+		notificationErrorResponse(w, "Failed to mark notifications read", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, map[string]int64{"marked": marked}, http.StatusOK)
+}
+
+// markNotificationRead handles POST /notification/{id}/read
+func markNotificationRead(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID, notificationID string) {
+	id, err := primitive.ObjectIDFromHex(notificationID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid notification ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	notification, err := services.MarkNotificationRead(userID, id)
+	if err != nil {
+		notificationErrorResponse(w, "Failed to mark notification read", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, notification, http.StatusOK)
+}
+
+// getNotificationPreferences handles GET /notification/preferences
+func getNotificationPreferences(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	preferences, err := services.GetNotificationPreferences(userID)
+	if err != nil {
+		notificationErrorResponse(w, "Failed to get notification preferences", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, preferences, http.StatusOK)
+}
+
+// updateNotificationPreferences handles PUT /notification/preferences with a map of type to on or off
+func updateNotificationPreferences(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.NotificationPreferences
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	preferences, err := services.UpdateNotificationPreferences(userID, request)
+	if err != nil {
+		notificationErrorResponse(w, "Failed to update notification preferences", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, preferences, http.StatusOK)
+}
+
+// notificationErrorResponse maps notification errors to HTTP responses
+func notificationErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "invalid notification"), strings.Contains(err.Error(), "invalid cursor"):
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+	case strings.HasPrefix(err.Error(), "no notification found"):
+		utils.ErrorResponse(w, "Notification not found", 404, http.StatusNotFound)
+	case strings.HasPrefix(err.Error(), "no user found"):
+		utils.ErrorResponse(w, "User not found", 404, http.StatusNotFound)
+	default:
+		utils.ErrorResponse(w, message+": "+err.Error(), 500, http.StatusInternalServerError)
+	}
+}
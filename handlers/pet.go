@@ -2,12 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"playtime-go/models"
 	"playtime-go/services"
 	"playtime-go/utils"
 	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -23,6 +25,36 @@ func HandlePet(w http.ResponseWriter, r *http.Request) {
 		petID = urlParts[2]
 	}
 
+	// Redeem invite codes at /pet/invites/{code}
+	subParts := utils.ExtractUrlParam(r.URL.Path, "/pet")
+	if petID == "invites" {
+		handlePetInviteAccept(w, r, subParts[1:])
+		return
+	}
+
+	// Route pet sub-resources such as /pet/{id}/health
+	if petID != "" && len(subParts) > 1 {
+		switch subParts[1] {
+		case "health":
+			handlePetHealth(w, r, petID, subParts[2:])
+		case "weights":
+			handlePetWeights(w, r, petID, subParts[2:])
+		case "photos":
+			handlePetPhotos(w, r, petID, subParts[2:])
+		case "playmates":
+			handlePetPlaymates(w, r, petID)
+		case "members":
+			handlePetMembers(w, r, petID, subParts[2:])
+		case "invites":
+			handlePetInvites(w, r, petID, subParts[2:])
+		case "owner":
+			handlePetOwner(w, r, petID, subParts[2:])
+		default:
+			utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
 	// Handle request based on method and whether we have a specific pet ID
 	switch {
 	case r.Method == http.MethodPost && petID == "":
@@ -40,8 +72,14 @@ func HandlePet(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// createPet handles POST requests to create a new pet
+// createPet handles POST requests to create a new pet owned by the calling user
 func createPet(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
 	// Read request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -63,16 +101,23 @@ func createPet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Optional: Add validation for age if needed
-	if request.Age <= 0 {
-		utils.ErrorResponse(w, "Valid pet age is required", 400, http.StatusBadRequest)
+	// Validate birthdate and species, older clients may still send an age instead
+	if err := validatePetRequest(request); err != nil {
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
 		return
 	}
 
+	// The caller always owns the pets they create
+	request.OwnerID = userID
+
 	// Call service to create pet
 	pet, err := services.CreatePet(request)
 	if err != nil {
-		utils.ErrorResponse(w, "Failed to create pet: "+err.Error(), 500, http.StatusInternalServerError)
+		if strings.Contains(err.Error(), "user is banned") {
+			utils.ErrorResponse(w, "Your account has been banned", 403, http.StatusForbidden)
+		} else {
+			utils.ErrorResponse(w, "Failed to create pet: "+err.Error(), 500, http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -88,7 +133,7 @@ func listPets(w http.ResponseWriter, r *http.Request) {
 
 	var ownerID *primitive.ObjectID
 
-	// Process owner ID filter if present
+	// Process owner ID filter if present, it matches every pet the user is a member of
 	if ownerIDStr != "" {
 		id, err := primitive.ObjectIDFromHex(ownerIDStr)
 		if err != nil {
@@ -98,13 +143,26 @@ func listPets(w http.ResponseWriter, r *http.Request) {
 		ownerID = &id
 	}
 
-	// Get pets from service
-	pets, err := services.ListPets(ownerID, 100)
+	// Process age stage filter if present
+	stage := query.Get("stage")
+	if stage != "" && stage != models.PetStagePuppy && stage != models.PetStageAdult && stage != models.PetStageSenior {
+		utils.ErrorResponse(w, "Invalid stage, must be one of puppy, adult, senior", 400, http.StatusBadRequest)
+		return
+	}
+
+	// Get pets from service, held pets are only listed to their own members
+	viewerID, _ := utils.GetRequestUserID(r)
+	pets, err := services.ListPets(ownerID, viewerID, stage, 100)
 	if err != nil {
 		utils.ErrorResponse(w, "Failed to list pets: "+err.Error(), 500, http.StatusInternalServerError)
 		return
 	}
 
+	// Members are only shown to the pet's own members
+	for i := range pets {
+		services.HidePetMembers(&pets[i], viewerID)
+	}
+
 	// Return response
 	utils.SuccessResponse(w, pets, http.StatusOK)
 }
@@ -118,8 +176,9 @@ func getPet(w http.ResponseWriter, r *http.Request, petID string) {
 		return
 	}
 
-	// Get the pet
-	pet, err := services.GetPetByID(id)
+	// Get the pet, a held pet is only shown to its own members
+	viewerID, _ := utils.GetRequestUserID(r)
+	pet, err := services.GetVisiblePet(id, viewerID)
 	if err != nil {
 		if strings.Contains(err.Error(), "no pet found") {
 			utils.ErrorResponse(w, "Pet not found", 404, http.StatusNotFound)
@@ -129,6 +188,9 @@ func getPet(w http.ResponseWriter, r *http.Request, petID string) {
 		return
 	}
 
+	// Members are only shown to the pet's own members
+	services.HidePetMembers(pet, viewerID)
+
 	// Return response
 	utils.SuccessResponse(w, pet, http.StatusOK)
 }
@@ -163,17 +225,28 @@ func updatePet(w http.ResponseWriter, r *http.Request, petID string) {
 		return
 	}
 
-	// Optional: Add validation for age if needed
-	if request.Age <= 0 {
-		utils.ErrorResponse(w, "Valid pet age is required", 400, http.StatusBadRequest)
+	// Validate birthdate and species, older clients may still send an age instead
+	if err := validatePetRequest(request); err != nil {
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+		return
+	}
+
+	// Only owners and co-owners may edit the pet profile
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
 		return
 	}
 
 	// Update the pet
-	pet, err := services.UpdatePet(id, request)
+	pet, err := services.UpdatePet(id, userID, request)
 	if err != nil {
 		if strings.Contains(err.Error(), "no pet found") {
 			utils.ErrorResponse(w, "Pet not found", 404, http.StatusNotFound)
+		} else if strings.Contains(err.Error(), "user is banned") {
+			utils.ErrorResponse(w, "Your account has been banned", 403, http.StatusForbidden)
+		} else if strings.Contains(err.Error(), "not authorized") {
+			utils.ErrorResponse(w, "Not authorized to edit this pet", 403, http.StatusForbidden)
 		} else {
 			utils.ErrorResponse(w, "Failed to update pet: "+err.Error(), 500, http.StatusInternalServerError)
 		}
@@ -193,11 +266,20 @@ func deletePet(w http.ResponseWriter, r *http.Request, petID string) {
 		return
 	}
 
+	// Only the owner may delete the pet
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
 	// Delete the pet
-	err = services.DeletePet(id)
+	err = services.DeletePet(id, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "no pet found") {
 			utils.ErrorResponse(w, "Pet not found", 404, http.StatusNotFound)
+		} else if strings.Contains(err.Error(), "not authorized") {
+			utils.ErrorResponse(w, "Not authorized to delete this pet", 403, http.StatusForbidden)
 		} else {
 			utils.ErrorResponse(w, "Failed to delete pet: "+err.Error(), 500, http.StatusInternalServerError)
 		}
@@ -207,3 +289,41 @@ func deletePet(w http.ResponseWriter, r *http.Request, petID string) {
 	// Return success response
 	utils.SuccessResponse(w, map[string]string{"message": "Pet deleted successfully"}, http.StatusOK)
 }
+
+// validatePetRequest checks the species, energy and temperament tags, and that a pet has a birthdate
+// that is not in the future, or a legacy age
+func validatePetRequest(request models.PetRequest) error {
+	switch request.Species {
+	case "", models.SpeciesDog, models.SpeciesCat, models.SpeciesOther:
+	default:
+		return fmt.Errorf("species must be one of dog, cat, other")
+	}
+
+	switch request.Energy {
+	case "", models.EnergyLow, models.EnergyMedium, models.EnergyHigh:
+	default:
+		return fmt.Errorf("energy must be one of low, medium, high")
+	}
+	if request.Size != "" && services.NormalizeSize(request.Size) == "" {
+		return fmt.Errorf("size must be one of small, medium, large")
+	}
+	for _, tag := range request.Temperament {
+		if !services.IsValidTemperament(tag) {
+			return fmt.Errorf("unknown temperament tag: %s", tag)
+		}
+	}
+
+	if request.BirthDate != nil {
+		if request.BirthDate.After(time.Now()) {
+			return fmt.Errorf("birth date cannot be in the future")
+		}
+		return nil
+	}
+	if request.Age < 0 {
+		return fmt.Errorf("age cannot be negative")
+	}
+	if request.Age == 0 {
+		return fmt.Errorf("birth date is required")
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// handlePetMembers handles /pet/{id}/members and /pet/{id}/members/{userId}
+func handlePetMembers(w http.ResponseWriter, r *http.Request, petID string, urlParts []string) {
+	id, err := primitive.ObjectIDFromHex(petID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid pet ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(urlParts) == 0:
+		listPetMembers(w, r, id, userID)
+	case r.Method == http.MethodDelete && len(urlParts) == 1:
+		removePetMember(w, r, id, userID, urlParts[0])
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// listPetMembers handles GET requests to list the members of a pet
+func listPetMembers(w http.ResponseWriter, r *http.Request, petID primitive.ObjectID, userID primitive.ObjectID) {
+	members, err := services.ListPetMembers(petID, userID)
+	if err != nil {
+		petResourceErrorResponse(w, "Failed to list members", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, members, http.StatusOK)
+}
+
+// removePetMember handles DELETE requests to remove a member or leave a pet
+func removePetMember(w http.ResponseWriter, r *http.Request, petID primitive.ObjectID, userID primitive.ObjectID, memberID string) {
+	id, err := primitive.ObjectIDFromHex(memberID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid user ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	if err := services.RemovePetMember(petID, userID, id); err != nil {
+		petResourceErrorResponse(w, "Failed to remove member", err)
+		return
+	}
+
+	// Return success response
+	utils.SuccessResponse(w, map[string]string{"message": "Member removed successfully"}, http.StatusOK)
+}
+
+// handlePetInvites handles POST /pet/{id}/invites to create a shareable invite code
+func handlePetInvites(w http.ResponseWriter, r *http.Request, petID string, urlParts []string) {
+	if r.Method != http.MethodPost || len(urlParts) != 0 {
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(petID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid pet ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.PetInviteRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	if request.Role != models.PetRoleCoOwner && request.Role != models.PetRoleSitter {
+		utils.ErrorResponse(w, "Role must be one of co_owner, sitter", 400, http.StatusBadRequest)
+		return
+	}
+
+	invite, err := services.CreatePetInvite(id, userID, request)
+	if err != nil {
+		petResourceErrorResponse(w, "Failed to create invite", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, invite, http.StatusCreated)
+}
+
+// handlePetInviteAccept handles POST /pet/invites/{code} to join a pet with an invite code
+func handlePetInviteAccept(w http.ResponseWriter, r *http.Request, urlParts []string) {
+	if r.Method != http.MethodPost || len(urlParts) != 1 {
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	pet, err := services.AcceptPetInvite(urlParts[0], userID)
+	if err != nil {
+		petResourceErrorResponse(w, "Failed to accept invite", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, pet, http.StatusOK)
+}
+
+// handlePetOwner handles PUT /pet/{id}/owner, letting a moderator assign an owner to a pet without one
+func handlePetOwner(w http.ResponseWriter, r *http.Request, petID string, urlParts []string) {
+	if r.Method != http.MethodPut || len(urlParts) != 0 {
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(petID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid pet ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	moderatorID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	var request struct {
+		UserID primitive.ObjectID `json:"userId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.UserID.IsZero() {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	pet, err := services.AssignPetOwner(id, moderatorID, request.UserID)
+	if err != nil {
+		petResourceErrorResponse(w, "Failed to assign owner", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, pet, http.StatusOK)
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// handlePetPhotos handles /pet/{id}/photos, /pet/{id}/photos/order and /pet/{id}/photos/{photoId}
+func handlePetPhotos(w http.ResponseWriter, r *http.Request, petID string, urlParts []string) {
+	id, err := primitive.ObjectIDFromHex(petID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid pet ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(urlParts) == 0:
+		listPetPhotos(w, r, id, userID)
+	case r.Method == http.MethodPost && len(urlParts) == 0:
+		uploadPetPhoto(w, r, id, userID)
+	case r.Method == http.MethodPut && len(urlParts) == 1 && urlParts[0] == "order":
+		reorderPetPhotos(w, r, id, userID)
+	case r.Method == http.MethodPut && len(urlParts) == 1:
+		updatePetPhoto(w, r, id, userID, urlParts[0])
+	case r.Method == http.MethodDelete && len(urlParts) == 1:
+		deletePetPhoto(w, r, id, userID, urlParts[0])
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// listPetPhotos handles GET requests for a pet's gallery, optionally filtered by album
+func listPetPhotos(w http.ResponseWriter, r *http.Request, petID primitive.ObjectID, userID primitive.ObjectID) {
+	photos, err := services.ListPetPhotos(petID, userID, r.URL.Query().Get("album"))
+	if err != nil {
+		petResourceErrorResponse(w, "Failed to list photos", err)
+		return
+	}
+
+	if photos == nil {
+		photos = make([]models.PetPhoto, 0)
+	}
+	// Return response
+	utils.SuccessResponse(w, photos, http.StatusOK)
+}
+
+// uploadPetPhoto handles multipart uploads of gallery photos with optional caption, album, takenAt and cover fields
+func uploadPetPhoto(w http.ResponseWriter, r *http.Request, petID primitive.ObjectID, userID primitive.ObjectID) {
+	// Parse multipart form with 10 MB max memory
+	const maxMemory = 10 * 1024 * 1024 // 10 MB
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
+		utils.ErrorResponse(w, "Failed to parse form: "+err.Error(), 400, http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(w, "No file provided or invalid file field", 400, http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	contentType := header.Header.Get("Content-Type")
+	if !isAllowedImageType(contentType) {
+		utils.ErrorResponse(w, "Unsupported file type: only images are allowed", 400, http.StatusBadRequest)
+		return
+	}
+
+	request := models.PetPhotoRequest{
+		Album:   r.FormValue("album"),
+		Caption: r.FormValue("caption"),
+		IsCover: r.FormValue("isCover") == "true",
+	}
+	if takenAt := r.FormValue("takenAt"); takenAt != "" {
+		t, err := time.Parse(time.RFC3339, takenAt)
+		if err != nil {
+			utils.ErrorResponse(w, "Invalid takenAt format, expected RFC3339", 400, http.StatusBadRequest)
+			return
+		}
+		request.TakenAt = &t
+	}
+	if !validatePetPhotoRequest(w, request) {
+		return
+	}
+
+	log.Printf("Received pet photo: %s, size: %d bytes, type: %s", header.Filename, header.Size, contentType)
+
+	photo, err := services.AddPetPhoto(petID, userID, file, header.Filename, contentType, header.Size, request)
+	if err != nil {
+		petResourceErrorResponse(w, "Failed to upload photo", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, photo, http.StatusCreated)
+}
+
+// updatePetPhoto handles PUT requests to edit a photo's details or make it the cover
+func updatePetPhoto(w http.ResponseWriter, r *http.Request, petID primitive.ObjectID, userID primitive.ObjectID, photoID string) {
+	id, err := primitive.ObjectIDFromHex(photoID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid photo ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.PetPhotoRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+	if !validatePetPhotoRequest(w, request) {
+		return
+	}
+
+	photo, err := services.UpdatePetPhoto(petID, id, userID, request)
+	if err != nil {
+		petResourceErrorResponse(w, "Failed to update photo", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, photo, http.StatusOK)
+}
+
+// reorderPetPhotos handles PUT requests that set the gallery order
+func reorderPetPhotos(w http.ResponseWriter, r *http.Request, petID primitive.ObjectID, userID primitive.ObjectID) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.PetPhotoOrderRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+	if len(request.PhotoIDs) == 0 {
+		utils.ErrorResponse(w, "Photo IDs are required", 400, http.StatusBadRequest)
+		return
+	}
+
+	photos, err := services.ReorderPetPhotos(petID, userID, request)
+	if err != nil {
+		petResourceErrorResponse(w, "Failed to reorder photos", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, photos, http.StatusOK)
+}
+
+// deletePetPhoto handles DELETE requests to remove a photo and its stored file
+func deletePetPhoto(w http.ResponseWriter, r *http.Request, petID primitive.ObjectID, userID primitive.ObjectID, photoID string) {
+	id, err := primitive.ObjectIDFromHex(photoID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid photo ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	if err := services.DeletePetPhoto(petID, id, userID); err != nil {
+		petResourceErrorResponse(w, "Failed to delete photo", err)
+		return
+	}
+
+	// Return success response
+	utils.SuccessResponse(w, map[string]string{"message": "Photo deleted successfully"}, http.StatusOK)
+}
+
+// validatePetPhotoRequest checks caption and album lengths and that the photo was not taken in the future
+func validatePetPhotoRequest(w http.ResponseWriter, request models.PetPhotoRequest) bool {
+	if len([]rune(request.Caption)) > 200 {
+		utils.ErrorResponse(w, "Caption must be at most 200 characters", 400, http.StatusBadRequest)
+		return false
+	}
+	if len([]rune(request.Album)) > 50 {
+		utils.ErrorResponse(w, "Album must be at most 50 characters", 400, http.StatusBadRequest)
+		return false
+	}
+	if request.TakenAt != nil && request.TakenAt.After(time.Now()) {
+		utils.ErrorResponse(w, "Taken at date cannot be in the future", 400, http.StatusBadRequest)
+		return false
+	}
+	return true
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"playtime-go/models"
 	"playtime-go/services"
@@ -32,6 +33,16 @@ func HandlePlace(w http.ResponseWriter, r *http.Request) {
 		listPlaces(w, r)
 	case placeID == "search" && r.Method == http.MethodGet:
 		searchPlaces(w, r)
+	case len(urlParts) == 2 && urlParts[1] == "checkin" && r.Method == http.MethodPost:
+		checkInAtPlace(placeID, w, r)
+	case len(urlParts) == 2 && urlParts[1] == "visits" && r.Method == http.MethodGet:
+		getPlaceVisits(placeID, w, r)
+	case len(urlParts) == 2 && urlParts[1] == "reviews" && r.Method == http.MethodGet:
+		getPlaceReviews(placeID, w, r)
+	case len(urlParts) == 2 && urlParts[1] == "photos" && r.Method == http.MethodGet:
+		getPlaceReviewPhotos(placeID, w, r)
+	case len(urlParts) > 1:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
 	case placeID != "" && r.Method == http.MethodGet:
 		getPlace(placeID, w, r)
 	case placeID != "" && r.Method == http.MethodPut:
@@ -66,9 +77,21 @@ func createPlace(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Places are always created by the caller, whatever user the body names
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+	request.CreatedBy = userID
+
 	// Call service to create location
 	location, err := services.CreateLocation(request)
 	if err != nil {
+		if strings.Contains(err.Error(), "user is banned") {
+			utils.ErrorResponse(w, "Your account has been banned", 403, http.StatusForbidden)
+			return
+		}
 		utils.ErrorResponse(w, "Failed to create location: "+err.Error(), 500, http.StatusInternalServerError)
 		return
 	}
@@ -88,7 +111,8 @@ func getPlace(id string, w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get the location
-	location, err := services.GetLocationByID(objectID)
+	viewerID, _ := utils.GetRequestUserID(r)
+	location, err := services.GetVisibleLocationByID(objectID, viewerID)
 	if err != nil {
 		if strings.Contains(err.Error(), "no location found") {
 			utils.ErrorResponse(w, "Location not found", 404, http.StatusNotFound)
@@ -141,6 +165,11 @@ func updatePlace(id string, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Claimed places can only be edited by their manager
+	if !authorizePlaceEdit(w, r, objectID) {
+		return
+	}
+
 	// Read request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -187,6 +216,11 @@ func deletePlace(id string, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Claimed places can only be deleted by their manager
+	if !authorizePlaceEdit(w, r, objectID) {
+		return
+	}
+
 	// Call service to delete location
 	err = services.DeleteLocation(objectID)
 	if err != nil {
@@ -202,6 +236,25 @@ func deletePlace(id string, w http.ResponseWriter, r *http.Request) {
 	utils.SuccessResponse(w, map[string]string{"message": "Location deleted successfully"}, http.StatusOK)
 }
 
+// authorizePlaceEdit checks the caller may change a place, writing the error response if not
+func authorizePlaceEdit(w http.ResponseWriter, r *http.Request, id primitive.ObjectID) bool {
+	// An unclaimed place does not need a user, so a missing user is left to the service to reject
+	userID, _ := utils.GetRequestUserID(r)
+
+	if err := services.AuthorizePlaceEdit(id, userID); err != nil {
+		if strings.Contains(err.Error(), "no location found") {
+			utils.ErrorResponse(w, "Location not found", 404, http.StatusNotFound)
+		} else if strings.Contains(err.Error(), "not authorized") {
+			utils.ErrorResponse(w, "Only the place manager can change a claimed place", 403, http.StatusForbidden)
+		} else {
+			utils.ErrorResponse(w, "Failed to check place: "+err.Error(), 500, http.StatusInternalServerError)
+		}
+		return false
+	}
+
+	return true
+}
+
 // searchPlaces handles GET requests to search for nearby locations
 func searchPlaces(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
@@ -270,6 +323,13 @@ func searchPlaces(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Flag places the calling user has saved
+	if userID, err := utils.GetRequestUserID(r); err == nil {
+		if err := services.MarkFavoritedResults(userID, results); err != nil {
+			log.Printf("Failed to mark favorited places: %v", err)
+		}
+	}
+
 	// Return response
 	utils.SuccessResponse(w, results, http.StatusOK)
 }
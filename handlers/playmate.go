@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// handlePetPlaymates handles GET /pet/{id}/playmates, suggesting compatible pets near the user's home area
+func handlePetPlaymates(w http.ResponseWriter, r *http.Request, petID string) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", 405, http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(petID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid pet ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			utils.ErrorResponse(w, "Invalid limit parameter", 400, http.StatusBadRequest)
+			return
+		}
+	}
+
+	suggestions, err := services.FindPlaymates(id, userID, limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "requires a home area") {
+			utils.ErrorResponse(w, "Set a home area and enable playmate matching first", 409, http.StatusConflict)
+		} else {
+			petResourceErrorResponse(w, "Failed to find playmates", err)
+		}
+		return
+	}
+
+	if suggestions == nil {
+		suggestions = make([]models.PlaymateSuggestion, 0)
+	}
+	// Return response
+	utils.SuccessResponse(w, suggestions, http.StatusOK)
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// pushHeartbeatInterval keeps idle push connections open through proxies
+const pushHeartbeatInterval = 30 * time.Second
+
+// HandlePush handles the real-time push channel
+//
+//	POST /push/token                 exchanges a WeChat login code for a session token, like POST /session
+//	GET  /push?token=&types=         streams the caller's events over WebSocket, or Server-Sent Events otherwise
+//
+// The stream is authenticated with a session token, passed as the token query parameter or as
+// an "Authorization: Bearer" header, because browsers cannot set custom headers on these requests.
+func HandlePush(w http.ResponseWriter, r *http.Request) {
+	urlParts := utils.ExtractUrlParam(r.URL.Path, "/push")
+
+	switch {
+	case len(urlParts) == 0 && r.Method == http.MethodGet:
+		streamPushEvents(w, r)
+	case len(urlParts) == 1 && urlParts[0] == "token" && r.Method == http.MethodPost:
+		issueSessionToken(w, r)
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// issueSessionToken handles POST /push/token, the body carries a code from wx.login
+func issueSessionToken(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.SessionTokenRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+	if request.Code == "" {
+		utils.ErrorResponse(w, "Code is required", 401, http.StatusUnauthorized)
+		return
+	}
+
+	token, err := services.IssueSessionToken(request.Code)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "user is banned"):
+			utils.ErrorResponse(w, "Your account has been banned", 403, http.StatusForbidden)
+		case strings.HasPrefix(err.Error(), "invalid login code"):
+			utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		case strings.HasPrefix(err.Error(), "no user found"):
+			utils.ErrorResponse(w, "User not found", 404, http.StatusNotFound)
+		default:
+			utils.ErrorResponse(w, "Failed to issue session token: "+err.Error(), 500, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, token, http.StatusOK)
+}
+
+// streamPushEvents handles GET /push
+func streamPushEvents(w http.ResponseWriter, r *http.Request) {
+	userID, err := utils.GetStreamUserID(r)
+	if err != nil {
+		if strings.Contains(err.Error(), "user is banned") {
+			utils.ErrorResponse(w, "Your account has been banned", 403, http.StatusForbidden)
+		} else {
+			utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		}
+		return
+	}
+
+	var types []string
+	if typesParam := r.URL.Query().Get("types"); typesParam != "" {
+		types = strings.Split(typesParam, ",")
+	}
+
+	if utils.IsWebSocketUpgrade(r) {
+		streamWebSocket(w, r, userID, types, encodePushEvent)
+		return
+	}
+	streamServerSentEvents(w, r, userID, types)
+}
+
+// encodePushEvent encodes the whole event for the push channel
+func encodePushEvent(event models.PushEvent) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// streamWebSocket upgrades the connection and writes the user's events as JSON text frames until the client disconnects
+func streamWebSocket(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID, types []string, encode func(models.PushEvent) ([]byte, error)) {
+	conn, err := utils.UpgradeWebSocket(w, r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe, err := services.SubscribeUserEvents(userID, types...)
+	if err != nil {
+		log.Printf("Failed to subscribe user %s to push events: %v", userID.Hex(), err)
+		return
+	}
+	defer unsubscribe()
+
+	// Events only flow to the client, the read loop answers pings and notices the client leaving
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(pushHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-events:
+			data, err := encode(event)
+			if err != nil {
+				log.Printf("Failed to encode %s event: %v", event.Type, err)
+				continue
+			}
+			if err := conn.WriteText(data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WritePing(); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// streamServerSentEvents writes the user's events as a text/event-stream until the client disconnects
+func streamServerSentEvents(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID, types []string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.ErrorResponse(w, "Streaming not supported", 500, http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe, err := services.SubscribeUserEvents(userID, types...)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to subscribe to events: "+err.Error(), 500, http.StatusInternalServerError)
+		return
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(pushHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-events:
+			data, err := encodePushEvent(event)
+			if err != nil {
+				log.Printf("Failed to encode %s event: %v", event.Type, err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-ticker.C:
+			// Comment lines keep the connection alive and are ignored by EventSource
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"playtime-go/config"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// handleUserSubscriptions handles /user/{id}/subscriptions
+func handleUserSubscriptions(w http.ResponseWriter, r *http.Request, userID string) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid user ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	if !requireSameUser(w, r, id, "Not authorized to access this user's subscriptions") {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		recordSubscription(w, r, id)
+	case http.MethodGet:
+		listSubscriptions(w, r, id)
+	default:
+		utils.ErrorResponse(w, "Method not allowed", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// recordSubscription handles POST requests reporting the result of a subscribe message prompt
+func recordSubscription(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.SubscriptionRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	if request.TemplateID == "" {
+		utils.ErrorResponse(w, "Template ID is required", 400, http.StatusBadRequest)
+		return
+	}
+
+	subscription, err := services.RecordSubscription(userID, request)
+	if err != nil {
+		if strings.Contains(err.Error(), "unknown template") {
+			utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+		} else {
+			utils.ErrorResponse(w, "Failed to record subscription: "+err.Error(), 500, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, subscription, http.StatusOK)
+}
+
+// listSubscriptions handles GET requests to list a user's subscription consents
+func listSubscriptions(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	subscriptions, err := services.ListSubscriptions(userID)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to list subscriptions: "+err.Error(), 500, http.StatusInternalServerError)
+		return
+	}
+
+	if subscriptions == nil {
+		subscriptions = make([]models.Subscription, 0)
+	}
+	// Return response
+	utils.SuccessResponse(w, subscriptions, http.StatusOK)
+}
+
+// handleUserReminders handles GET /user/{id}/reminders to list reminder delivery logs
+func handleUserReminders(w http.ResponseWriter, r *http.Request, userID string) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", 405, http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid user ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	if !requireSameUser(w, r, id, "Not authorized to access this user's reminders") {
+		return
+	}
+
+	var limit int64 = 100 // Default limit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsedLimit, err := strconv.ParseInt(limitParam, 10, 64)
+		if err != nil || parsedLimit <= 0 {
+			utils.ErrorResponse(w, "Invalid limit parameter", 400, http.StatusBadRequest)
+			return
+		}
+		limit = parsedLimit
+	}
+
+	logs, err := services.ListReminderLogs(id, limit)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to list reminders: "+err.Error(), 500, http.StatusInternalServerError)
+		return
+	}
+
+	if logs == nil {
+		logs = make([]models.ReminderLog, 0)
+	}
+	// Return response
+	utils.SuccessResponse(w, logs, http.StatusOK)
+}
+
+// handleSubscribeTemplates returns the template IDs the client should request consent for
+func handleSubscribeTemplates(w http.ResponseWriter, r *http.Request) {
+	utils.SuccessResponse(w, services.GetReminderTemplates(), http.StatusOK)
+}
+
+// requireSameUser writes an error response unless the request is made by the given user
+func requireSameUser(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID, message string) bool {
+	requestUserID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return false
+	}
+	if requestUserID != userID {
+		utils.ErrorResponse(w, message, 403, http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// handleRunReminders triggers a reminder sweep immediately, only moderators may run it
+func handleRunReminders(w http.ResponseWriter, r *http.Request) {
+	requestUserID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+	if !services.IsModerator(requestUserID) {
+		utils.ErrorResponse(w, "Not authorized to run reminders", 403, http.StatusForbidden)
+		return
+	}
+
+	sent, err := services.RunReminderSweep(time.Now())
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to run reminders: "+err.Error(), 500, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, map[string]int{"sent": sent}, http.StatusOK)
+}
+
+// handleFakeSubscribeSend imitates the WeChat subscribe message API for dry runs
+func handleFakeSubscribeSend(w http.ResponseWriter, r *http.Request) {
+	if !config.GetConfig().ReminderDryRun {
+		utils.ErrorResponse(w, "Dry run is not enabled", 404, http.StatusNotFound)
+		return
+	}
+
+	var message models.SubscribeMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.SubscribeMessageResponse{ErrCode: 47001, ErrMsg: "data format error"})
+		return
+	}
+	defer r.Body.Close()
+
+	log.Printf("[dry-run] subscribe message to %s with template %s: %+v", message.ToUser, message.TemplateID, message.Data)
+
+	// Mirror the real API's rejection of messages without a recipient
+	response := models.SubscribeMessageResponse{ErrCode: 0, ErrMsg: "ok"}
+	if message.ToUser == "" {
+		response = models.SubscribeMessageResponse{ErrCode: 40003, ErrMsg: "invalid openid"}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HandleReport handles abuse reports against reviews, places, pets and users
+func HandleReport(w http.ResponseWriter, r *http.Request) {
+	urlParts := utils.ExtractUrlParam(r.URL.Path, "/report")
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && len(urlParts) == 0:
+		createReport(w, r, userID)
+	case r.Method == http.MethodGet && len(urlParts) == 0:
+		listUserReports(w, r, userID)
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// createReport handles POST /report, filing a report with a reason code and optional details
+func createReport(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.ReportRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	// Validate required fields
+	if request.TargetType == "" || request.TargetID.IsZero() {
+		utils.ErrorResponse(w, "Target type and target ID are required", 400, http.StatusBadRequest)
+		return
+	}
+	if request.Reason == "" {
+		utils.ErrorResponse(w, "Reason is required", 400, http.StatusBadRequest)
+		return
+	}
+
+	report, err := services.CreateReport(userID, request)
+	if err != nil {
+		reportErrorResponse(w, "Failed to create report", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, report, http.StatusCreated)
+}
+
+// listUserReports handles GET /report, listing the reports the caller has filed
+func listUserReports(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	var limit int64
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsedLimit, err := strconv.ParseInt(limitParam, 10, 64)
+		if err != nil || parsedLimit <= 0 {
+			utils.ErrorResponse(w, "Invalid limit parameter", 400, http.StatusBadRequest)
+			return
+		}
+		limit = parsedLimit
+	}
+
+	reports, err := services.ListUserReports(userID, limit)
+	if err != nil {
+		reportErrorResponse(w, "Failed to list reports", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, reports, http.StatusOK)
+}
+
+// reportErrorResponse maps report errors to HTTP responses
+func reportErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "user is banned"):
+		utils.ErrorResponse(w, "Your account has been banned", 403, http.StatusForbidden)
+	case strings.Contains(err.Error(), "not authorized"):
+		utils.ErrorResponse(w, err.Error(), 403, http.StatusForbidden)
+	case strings.Contains(err.Error(), "invalid report"), strings.Contains(err.Error(), "invalid content type"):
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+	case strings.HasPrefix(err.Error(), "no review found"), strings.HasPrefix(err.Error(), "no location found"),
+		strings.HasPrefix(err.Error(), "no pet found"), strings.HasPrefix(err.Error(), "no user found"):
+		utils.ErrorResponse(w, "Reported content not found", 404, http.StatusNotFound)
+	default:
+		utils.ErrorResponse(w, message+": "+err.Error(), 500, http.StatusInternalServerError)
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"playtime-go/models"
@@ -12,26 +13,33 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-const reviewCollection = "review"
-
 func HandleReview(w http.ResponseWriter, r *http.Request) {
 
 	urlParts := utils.ExtractUrlParam(r.URL.Path, "/review")
 	var placeID, userID, reviewID string
 
-	if len(urlParts) > 2 {
-		if urlParts[0] == "place" {
-			placeID = urlParts[1]
-			reviewID = urlParts[2]
-		} else if urlParts[0] == "user" {
-			userID = urlParts[1]
-			reviewID = urlParts[2]
-		} else {
-			reviewID = urlParts[1]
-		}
+	// Helpfulness votes on a single review
+	if len(urlParts) == 2 && urlParts[1] == "vote" {
+		handleReviewVote(urlParts[0], w, r)
+		return
+	}
+
+	// Reply thread of a single review
+	if len(urlParts) >= 2 && urlParts[0] != "place" && urlParts[0] != "user" && urlParts[1] == "replies" {
+		handleReviewReplies(urlParts, w, r)
+		return
+	}
+
+	if len(urlParts) > 1 && urlParts[0] == "place" {
+		placeID = urlParts[1]
+	} else if len(urlParts) > 1 && urlParts[0] == "user" {
+		userID = urlParts[1]
+	} else if len(urlParts) > 2 {
+		reviewID = urlParts[1]
+	} else if len(urlParts) == 1 {
+		reviewID = urlParts[0]
 	}
 
 	switch {
@@ -103,10 +111,6 @@ func createReview(w http.ResponseWriter, r *http.Request) {
 		utils.ErrorResponse(w, "Place ID is required", 400, http.StatusBadRequest)
 		return
 	}
-	if request.UserID == "" {
-		utils.ErrorResponse(w, "User ID is required", 400, http.StatusBadRequest)
-		return
-	}
 	if request.Content == "" {
 		utils.ErrorResponse(w, "Content is required", 400, http.StatusBadRequest)
 		return
@@ -115,10 +119,30 @@ func createReview(w http.ResponseWriter, r *http.Request) {
 		utils.ErrorResponse(w, "Rating must be between 1 and 5", 400, http.StatusBadRequest)
 		return
 	}
+	if len(request.PhotoURLs) > models.MaxReviewPhotos {
+		utils.ErrorResponse(w, fmt.Sprintf("A review can have at most %d photos", models.MaxReviewPhotos), 400, http.StatusBadRequest)
+		return
+	}
+
+	// Reviews are always written by the caller, whatever user the body names
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+	request.UserID = userID.Hex()
 
 	review, err := services.CreateReview(request)
 	if err != nil {
-		utils.ErrorResponse(w, "Failed to create review: "+err.Error(), 500, http.StatusInternalServerError)
+		if strings.Contains(err.Error(), "user is banned") {
+			utils.ErrorResponse(w, "Your account has been banned", 403, http.StatusForbidden)
+		} else if strings.Contains(err.Error(), "no check-in found") {
+			utils.ErrorResponse(w, "A check-in at this place is required to review it", 403, http.StatusForbidden)
+		} else if strings.Contains(err.Error(), "invalid photo") {
+			utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+		} else {
+			utils.ErrorResponse(w, "Failed to create review: "+err.Error(), 500, http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -133,7 +157,8 @@ func getReview(w http.ResponseWriter, r *http.Request, reviewID string) {
 		return
 	}
 
-	review, err := services.GetReview(id)
+	viewerID, _ := utils.GetRequestUserID(r)
+	review, err := services.GetVisibleReview(id, viewerID)
 	if err != nil {
 		if strings.Contains(err.Error(), "no review found") {
 			utils.ErrorResponse(w, "Review not found", 404, http.StatusNotFound)
@@ -176,11 +201,27 @@ func updateReview(w http.ResponseWriter, r *http.Request, reviewID string) {
 		utils.ErrorResponse(w, "Rating must be between 1 and 5", 400, http.StatusBadRequest)
 		return
 	}
+	if len(request.PhotoURLs) > models.MaxReviewPhotos {
+		utils.ErrorResponse(w, fmt.Sprintf("A review can have at most %d photos", models.MaxReviewPhotos), 400, http.StatusBadRequest)
+		return
+	}
 
-	review, err := services.UpdateReview(id, request)
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	review, err := services.UpdateReview(id, userID, request)
 	if err != nil {
 		if strings.Contains(err.Error(), "no review found") {
 			utils.ErrorResponse(w, "Review not found", 404, http.StatusNotFound)
+		} else if strings.Contains(err.Error(), "not authorized") {
+			utils.ErrorResponse(w, "Only the author or a moderator can edit this review", 403, http.StatusForbidden)
+		} else if strings.Contains(err.Error(), "user is banned") {
+			utils.ErrorResponse(w, "Your account has been banned", 403, http.StatusForbidden)
+		} else if strings.Contains(err.Error(), "invalid photo") {
+			utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
 		} else {
 			utils.ErrorResponse(w, "Failed to update review: "+err.Error(), 500, http.StatusInternalServerError)
 		}
@@ -198,10 +239,20 @@ func deleteReview(w http.ResponseWriter, r *http.Request, reviewID string) {
 		return
 	}
 
-	err = services.DeleteReview(id)
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	err = services.DeleteReview(id, userID)
 	if err != nil {
 		if strings.Contains(err.Error(), "no review found") {
 			utils.ErrorResponse(w, "Review not found", 404, http.StatusNotFound)
+		} else if strings.Contains(err.Error(), "not authorized") {
+			utils.ErrorResponse(w, "Only the author or a moderator can delete this review", 403, http.StatusForbidden)
+		} else if strings.Contains(err.Error(), "user is banned") {
+			utils.ErrorResponse(w, "Your account has been banned", 403, http.StatusForbidden)
 		} else {
 			utils.ErrorResponse(w, "Failed to delete review: "+err.Error(), 500, http.StatusInternalServerError)
 		}
@@ -218,7 +269,8 @@ func getAllUserReview(w http.ResponseWriter, r *http.Request, userID string) {
 		return
 	}
 
-	reviews, err := services.GetAllUserReview(id)
+	viewerID, _ := utils.GetRequestUserID(r)
+	reviews, err := services.GetAllUserReview(id, viewerID)
 	if err != nil {
 		if strings.Contains(err.Error(), "no reviews found") {
 			utils.ErrorResponse(w, "No reviews found for this user", 404, http.StatusNotFound)
@@ -238,9 +290,17 @@ func deleteAllUserReview(w http.ResponseWriter, r *http.Request, userID string)
 		return
 	}
 
-	err = services.DeleteAllUserReview(id)
+	moderatorID, err := utils.GetRequestUserID(r)
 	if err != nil {
-		if strings.Contains(err.Error(), "no reviews found") {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	err = services.DeleteAllUserReview(id, moderatorID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not authorized") {
+			utils.ErrorResponse(w, "Only moderators can delete all reviews", 403, http.StatusForbidden)
+		} else if strings.Contains(err.Error(), "no reviews found") {
 			utils.ErrorResponse(w, "No reviews found for this user", 404, http.StatusNotFound)
 		} else {
 			utils.ErrorResponse(w, "Failed to delete reviews: "+err.Error(), 500, http.StatusInternalServerError)
@@ -258,7 +318,13 @@ func getAllPlaceReview(w http.ResponseWriter, r *http.Request, placeID string) {
 		return
 	}
 
-	reviews, err := services.GetAllPlaceReview(id)
+	sort := r.URL.Query().Get("sort")
+	if sort != "" && !services.IsValidReviewSort(sort) {
+		utils.ErrorResponse(w, "Invalid sort parameter, must be newest, highest_rated, lowest_rated or most_helpful", 400, http.StatusBadRequest)
+		return
+	}
+
+	reviews, err := services.GetAllPlaceReview(id, sort, requestViewerID(r))
 	if err != nil {
 		if strings.Contains(err.Error(), "no reviews found") {
 			utils.ErrorResponse(w, "No reviews found for this place", 404, http.StatusNotFound)
@@ -278,9 +344,17 @@ func deleteAllPlaceReview(w http.ResponseWriter, r *http.Request, placeID string
 		return
 	}
 
-	err = services.DeleteAllPlaceReview(id)
+	moderatorID, err := utils.GetRequestUserID(r)
 	if err != nil {
-		if strings.Contains(err.Error(), "no reviews found") {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	err = services.DeleteAllPlaceReview(id, moderatorID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not authorized") {
+			utils.ErrorResponse(w, "Only moderators can delete all reviews", 403, http.StatusForbidden)
+		} else if strings.Contains(err.Error(), "no reviews found") {
 			utils.ErrorResponse(w, "No reviews found for this place", 404, http.StatusNotFound)
 		} else {
 			utils.ErrorResponse(w, "Failed to delete reviews: "+err.Error(), 500, http.StatusInternalServerError)
@@ -301,28 +375,27 @@ func listReviews(w http.ResponseWriter, r *http.Request) {
 	userIDParam := query.Get("userId")
 	ratingParam := query.Get("rating")
 	limitParam := query.Get("limit")
+	sortParam := query.Get("sort")
 
-	// Prepare filter
+	// Prepare filter, content held for moderation is left out by the service
 	filter := bson.M{}
 
 	// Add placeId filter if provided
 	if placeIDParam != "" {
-		placeID, err := primitive.ObjectIDFromHex(placeIDParam)
-		if err != nil {
+		if _, err := primitive.ObjectIDFromHex(placeIDParam); err != nil {
 			utils.ErrorResponse(w, "Invalid place ID format", 400, http.StatusBadRequest)
 			return
 		}
-		filter["place_id"] = placeID
+		filter["placeId"] = placeIDParam
 	}
 
 	// Add userId filter if provided
 	if userIDParam != "" {
-		userID, err := primitive.ObjectIDFromHex(userIDParam)
-		if err != nil {
+		if _, err := primitive.ObjectIDFromHex(userIDParam); err != nil {
 			utils.ErrorResponse(w, "Invalid user ID format", 400, http.StatusBadRequest)
 			return
 		}
-		filter["user_id"] = userID
+		filter["userId"] = userIDParam
 	}
 
 	// Add rating filter if provided
@@ -335,6 +408,12 @@ func listReviews(w http.ResponseWriter, r *http.Request) {
 		filter["rating"] = rating
 	}
 
+	// Validate sort mode if provided
+	if sortParam != "" && !services.IsValidReviewSort(sortParam) {
+		utils.ErrorResponse(w, "Invalid sort parameter, must be newest, highest_rated, lowest_rated or most_helpful", 400, http.StatusBadRequest)
+		return
+	}
+
 	// Parse limit if provided
 	var limit int64 = 100 // Default limit
 	if limitParam != "" {
@@ -346,22 +425,234 @@ func listReviews(w http.ResponseWriter, r *http.Request) {
 		limit = parsedLimit
 	}
 
-	// Create find options
-	findOptions := options.Find()
-	findOptions.SetSort(bson.D{{Key: "date", Value: -1}}) // Sort by date, newest first
-	findOptions.SetLimit(limit)
-
-	// Get reviews from database
-	var reviews []models.Review
-	err := services.FindMany(reviewCollection, filter, &reviews, findOptions)
+	// Get reviews, newest first unless a sort mode is given
+	reviews, err := services.ListReviews(filter, sortParam, limit, requestViewerID(r))
 	if err != nil {
 		utils.ErrorResponse(w, "Failed to list reviews: "+err.Error(), 500, http.StatusInternalServerError)
 		return
 	}
 
+	// Return response
+	utils.SuccessResponse(w, reviews, http.StatusOK)
+}
+
+// getPlaceReviews handles GET /place/{id}/reviews with an optional sort mode and limit
+func getPlaceReviews(placeID string, w http.ResponseWriter, r *http.Request) {
+	if _, err := primitive.ObjectIDFromHex(placeID); err != nil {
+		utils.ErrorResponse(w, "Invalid place ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	sort := r.URL.Query().Get("sort")
+	if sort != "" && !services.IsValidReviewSort(sort) {
+		utils.ErrorResponse(w, "Invalid sort parameter, must be newest, highest_rated, lowest_rated or most_helpful", 400, http.StatusBadRequest)
+		return
+	}
+
+	var limit int64
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsedLimit, err := strconv.ParseInt(limitParam, 10, 64)
+		if err != nil || parsedLimit <= 0 {
+			utils.ErrorResponse(w, "Invalid limit parameter", 400, http.StatusBadRequest)
+			return
+		}
+		limit = parsedLimit
+	}
+
+	reviews, err := services.GetReviewsByPlace(placeID, sort, limit, requestViewerID(r))
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to get reviews: "+err.Error(), 500, http.StatusInternalServerError)
+		return
+	}
+
 	if reviews == nil {
 		reviews = make([]models.Review, 0)
 	}
-	// Return response
 	utils.SuccessResponse(w, reviews, http.StatusOK)
 }
+
+// requestViewerID returns the calling user for listings that hide blocked and muted users, nil for anonymous requests
+func requestViewerID(r *http.Request) *primitive.ObjectID {
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		return nil
+	}
+	return &userID
+}
+
+// handleReviewVote handles POST and DELETE /review/{id}/vote
+func handleReviewVote(reviewID string, w http.ResponseWriter, r *http.Request) {
+	id, err := primitive.ObjectIDFromHex(reviewID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid review ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	var review *models.Review
+	switch r.Method {
+	case http.MethodPost:
+		body, readErr := io.ReadAll(r.Body)
+		if readErr != nil {
+			utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		var request models.ReviewVoteRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+			return
+		}
+		if request.Helpful == nil {
+			utils.ErrorResponse(w, "Helpful is required", 400, http.StatusBadRequest)
+			return
+		}
+
+		review, err = services.VoteReview(id, userID, *request.Helpful)
+	case http.MethodDelete:
+		review, err = services.RemoveReviewVote(id, userID)
+	default:
+		utils.ErrorResponse(w, "Method not allowed", 405, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "no review found"):
+			utils.ErrorResponse(w, "Review not found", 404, http.StatusNotFound)
+		case strings.Contains(err.Error(), "no vote found"):
+			utils.ErrorResponse(w, "Vote not found", 404, http.StatusNotFound)
+		case strings.Contains(err.Error(), "invalid vote"):
+			utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+		default:
+			utils.ErrorResponse(w, "Failed to vote on review: "+err.Error(), 500, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.SuccessResponse(w, review, http.StatusOK)
+}
+
+// handleReviewReplies handles POST /review/{id}/replies and DELETE /review/{id}/replies/{replyId}
+func handleReviewReplies(urlParts []string, w http.ResponseWriter, r *http.Request) {
+	reviewID, err := primitive.ObjectIDFromHex(urlParts[0])
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid review ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	var review *models.Review
+	switch {
+	case r.Method == http.MethodPost && len(urlParts) == 2:
+		body, readErr := io.ReadAll(r.Body)
+		if readErr != nil {
+			utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		var request models.ReviewReplyRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+			return
+		}
+
+		request.Content = strings.TrimSpace(request.Content)
+		if request.Content == "" {
+			utils.ErrorResponse(w, "Content is required", 400, http.StatusBadRequest)
+			return
+		}
+		if len([]rune(request.Content)) > 500 {
+			utils.ErrorResponse(w, "Content must be at most 500 characters", 400, http.StatusBadRequest)
+			return
+		}
+
+		review, err = services.AddReviewReply(reviewID, userID, request)
+		if err == nil {
+			utils.SuccessResponse(w, review, http.StatusCreated)
+			return
+		}
+	case r.Method == http.MethodDelete && len(urlParts) == 3:
+		replyID, parseErr := primitive.ObjectIDFromHex(urlParts[2])
+		if parseErr != nil {
+			utils.ErrorResponse(w, "Invalid reply ID format", 400, http.StatusBadRequest)
+			return
+		}
+
+		review, err = services.DeleteReviewReply(reviewID, replyID, userID)
+		if err == nil {
+			utils.SuccessResponse(w, review, http.StatusOK)
+			return
+		}
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch {
+	case strings.Contains(err.Error(), "user is banned"):
+		utils.ErrorResponse(w, "Your account has been banned", 403, http.StatusForbidden)
+	case strings.Contains(err.Error(), "blocked by user"):
+		utils.ErrorResponse(w, "The reviewer has blocked you", 403, http.StatusForbidden)
+	case strings.Contains(err.Error(), "no review found"):
+		utils.ErrorResponse(w, "Review not found", 404, http.StatusNotFound)
+	case strings.HasPrefix(err.Error(), "no reply found"):
+		utils.ErrorResponse(w, "Reply not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "not authorized"):
+		utils.ErrorResponse(w, "Only the place manager and the reviewer can reply", 403, http.StatusForbidden)
+	case strings.Contains(err.Error(), "invalid reply"):
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+	default:
+		utils.ErrorResponse(w, "Failed to reply to review: "+err.Error(), 500, http.StatusInternalServerError)
+	}
+}
+
+// getPlaceReviewPhotos handles GET /place/{id}/photos, paging through the photos of all the place's reviews
+func getPlaceReviewPhotos(placeID string, w http.ResponseWriter, r *http.Request) {
+	if _, err := primitive.ObjectIDFromHex(placeID); err != nil {
+		utils.ErrorResponse(w, "Invalid place ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+
+	var page int64 = 1
+	if pageParam := query.Get("page"); pageParam != "" {
+		parsedPage, err := strconv.ParseInt(pageParam, 10, 64)
+		if err != nil || parsedPage <= 0 {
+			utils.ErrorResponse(w, "Invalid page parameter", 400, http.StatusBadRequest)
+			return
+		}
+		page = parsedPage
+	}
+
+	var limit int64 = 20
+	if limitParam := query.Get("limit"); limitParam != "" {
+		parsedLimit, err := strconv.ParseInt(limitParam, 10, 64)
+		if err != nil || parsedLimit <= 0 || parsedLimit > 100 {
+			utils.ErrorResponse(w, "Invalid limit parameter, must be between 1 and 100", 400, http.StatusBadRequest)
+			return
+		}
+		limit = parsedLimit
+	}
+
+	photos, err := services.ListPlaceReviewPhotos(placeID, page, limit)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to get review photos: "+err.Error(), 500, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, photos, http.StatusOK)
+}
@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"net/http"
+	"playtime-go/utils"
+)
+
+// HandleSession handles POST /session, signing the caller in with a code from wx.login.
+// The returned session token authenticates every other request as an "Authorization: Bearer" header.
+func HandleSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", 405, http.StatusMethodNotAllowed)
+		return
+	}
+
+	issueSessionToken(w, r)
+}
@@ -23,11 +23,35 @@ func HandleUser(w http.ResponseWriter, r *http.Request) {
 	// urlPath = strings.TrimPrefix(urlPath, "/")
 	// urlParts := strings.Split(urlPath, "/")
 
-	var userID string
+	var userID, subResource string
 	// Check if we have a user ID in the URL
 	if len(urlParts) > 0 && urlParts[0] != "" {
 		userID = urlParts[0]
 	}
+	if len(urlParts) > 1 {
+		subResource = urlParts[1]
+	}
+
+	// Route user sub-resources
+	if userID != "" && subResource != "" {
+		switch subResource {
+		case "export":
+			handleUserExport(w, r, userID, urlParts[2:])
+		case "subscriptions":
+			handleUserSubscriptions(w, r, userID)
+		case "reminders":
+			handleUserReminders(w, r, userID)
+		case "home-area":
+			handleUserHomeArea(w, r, userID)
+		case "visits":
+			handleUserVisits(w, r, userID)
+		case "blocks", "mutes":
+			handleUserBlocks(w, r, userID, subResource, urlParts[2:])
+		default:
+			utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+		}
+		return
+	}
 
 	// Handle request based on method and whether we have a specific user ID
 	switch {
@@ -251,3 +275,69 @@ func HandleUserByOpenID(w http.ResponseWriter, r *http.Request) {
 	// Return response
 	utils.SuccessResponse(w, user, http.StatusOK)
 }
+
+// handleUserExport handles /user/{id}/export and /user/{id}/export/{exportId}. Only the user may export their data.
+func handleUserExport(w http.ResponseWriter, r *http.Request, userID string, urlParts []string) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid user ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	requestUserID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+	if requestUserID != id {
+		utils.ErrorResponse(w, "Not authorized to export this user's data", 403, http.StatusForbidden)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && len(urlParts) == 0:
+		createUserExport(w, r, id)
+	case r.Method == http.MethodGet && len(urlParts) == 1:
+		getUserExport(w, r, id, urlParts[0])
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// createUserExport handles POST requests to start a personal data export
+func createUserExport(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	job, err := services.CreateExportJob(userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "no user found") {
+			utils.ErrorResponse(w, "User not found", 404, http.StatusNotFound)
+		} else {
+			utils.ErrorResponse(w, "Failed to create export: "+err.Error(), 500, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, job, http.StatusAccepted)
+}
+
+// getUserExport handles GET requests to check an export and fetch its download link
+func getUserExport(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID, exportID string) {
+	id, err := primitive.ObjectIDFromHex(exportID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid export ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	job, err := services.GetExportJob(userID, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "no export found") {
+			utils.ErrorResponse(w, "Export not found", 404, http.StatusNotFound)
+		} else {
+			utils.ErrorResponse(w, "Failed to get export: "+err.Error(), 500, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, job, http.StatusOK)
+}
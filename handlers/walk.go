@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HandleWalk handles walk logging for the calling user
+//
+//	POST   /walk                          logs a walk from timestamped points
+//	POST   /walk/import                   logs a walk from an uploaded GPX file
+//	GET    /walk?petId=&before=&limit=    lists the caller's walks, or a pet's walks
+//	GET    /walk/{id}                     gets a walk with its route
+//	GET    /walk/{id}/gpx                 exports a walk as GPX
+//	DELETE /walk/{id}                     deletes a walk
+func HandleWalk(w http.ResponseWriter, r *http.Request) {
+	urlParts := utils.ExtractUrlParam(r.URL.Path, "/walk")
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && len(urlParts) == 0:
+		createWalk(w, r, userID)
+	case r.Method == http.MethodPost && len(urlParts) == 1 && urlParts[0] == "import":
+		importWalk(w, r, userID)
+	case r.Method == http.MethodGet && len(urlParts) == 0:
+		listWalks(w, r, userID)
+	case r.Method == http.MethodGet && len(urlParts) == 1:
+		getWalk(w, r, userID, urlParts[0])
+	case r.Method == http.MethodGet && len(urlParts) == 2 && urlParts[1] == "gpx":
+		exportWalk(w, r, userID, urlParts[0])
+	case r.Method == http.MethodDelete && len(urlParts) == 1:
+		deleteWalk(w, r, userID, urlParts[0])
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// createWalk handles POST /walk
+func createWalk(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.WalkRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	walk, err := services.CreateWalk(userID, request)
+	if err != nil {
+		walkErrorResponse(w, "Failed to create walk", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, walk, http.StatusCreated)
+}
+
+// importWalk handles multipart uploads of a GPX file with petIds (comma-separated) and an optional title
+func importWalk(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	// Parse multipart form with 10 MB max memory
+	const maxMemory = 10 * 1024 * 1024 // 10 MB
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
+		utils.ErrorResponse(w, "Failed to parse form: "+err.Error(), 400, http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(w, "No file provided or invalid file field", 400, http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxMemory+1))
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read file", 400, http.StatusBadRequest)
+		return
+	}
+	if len(data) > maxMemory {
+		utils.ErrorResponse(w, "GPX file is too large", 400, http.StatusBadRequest)
+		return
+	}
+
+	name, points, err := utils.ParseGPX(data)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+		return
+	}
+
+	request := models.WalkRequest{Title: r.FormValue("title"), Points: points}
+	if request.Title == "" {
+		request.Title = name
+	}
+	for _, petID := range strings.Split(r.FormValue("petIds"), ",") {
+		if petID = strings.TrimSpace(petID); petID == "" {
+			continue
+		}
+		id, err := primitive.ObjectIDFromHex(petID)
+		if err != nil {
+			utils.ErrorResponse(w, "Invalid pet ID format", 400, http.StatusBadRequest)
+			return
+		}
+		request.PetIDs = append(request.PetIDs, id)
+	}
+
+	walk, err := services.CreateWalk(userID, request)
+	if err != nil {
+		walkErrorResponse(w, "Failed to import walk", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, walk, http.StatusCreated)
+}
+
+// listWalks handles GET /walk
+func listWalks(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID) {
+	limit, ok := followLimitParam(w, r, 100)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	var petID *primitive.ObjectID
+	if petParam := query.Get("petId"); petParam != "" {
+		id, err := primitive.ObjectIDFromHex(petParam)
+		if err != nil {
+			utils.ErrorResponse(w, "Invalid pet ID format", 400, http.StatusBadRequest)
+			return
+		}
+		petID = &id
+	}
+
+	var before time.Time
+	if beforeParam := query.Get("before"); beforeParam != "" {
+		parsed, err := time.Parse(time.RFC3339, beforeParam)
+		if err != nil {
+			utils.ErrorResponse(w, "Invalid before parameter, use RFC 3339", 400, http.StatusBadRequest)
+			return
+		}
+		before = parsed
+	}
+
+	walks, err := services.ListWalks(userID, petID, before, limit)
+	if err != nil {
+		walkErrorResponse(w, "Failed to list walks", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, walks, http.StatusOK)
+}
+
+// getWalk handles GET /walk/{id}
+func getWalk(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID, walkID string) {
+	id, err := primitive.ObjectIDFromHex(walkID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid walk ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	walk, err := services.GetWalk(id, userID)
+	if err != nil {
+		walkErrorResponse(w, "Failed to get walk", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, walk, http.StatusOK)
+}
+
+// exportWalk handles GET /walk/{id}/gpx, the route is returned as a GPX file download
+func exportWalk(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID, walkID string) {
+	id, err := primitive.ObjectIDFromHex(walkID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid walk ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	walk, err := services.GetWalk(id, userID)
+	if err != nil {
+		walkErrorResponse(w, "Failed to get walk", err)
+		return
+	}
+
+	name := walk.Title
+	if name == "" {
+		name = "Walk " + walk.StartedAt.Format("2006-01-02 15:04")
+	}
+	data, err := utils.EncodeGPX(name, services.WalkPoints(walk))
+	if err != nil {
+		walkErrorResponse(w, "Failed to export walk", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gpx+xml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"walk-%s.gpx\"", walk.ID.Hex()))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// deleteWalk handles DELETE /walk/{id}
+func deleteWalk(w http.ResponseWriter, r *http.Request, userID primitive.ObjectID, walkID string) {
+	id, err := primitive.ObjectIDFromHex(walkID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid walk ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	if err := services.DeleteWalk(id, userID); err != nil {
+		walkErrorResponse(w, "Failed to delete walk", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, map[string]string{"message": "Walk deleted successfully"}, http.StatusOK)
+}
+
+// walkErrorResponse maps walk errors to HTTP responses
+func walkErrorResponse(w http.ResponseWriter, message string, err error) {
+	switch {
+	case strings.Contains(err.Error(), "invalid walk"):
+		utils.ErrorResponse(w, err.Error(), 400, http.StatusBadRequest)
+	case strings.HasPrefix(err.Error(), "no walk found"):
+		utils.ErrorResponse(w, "Walk not found", 404, http.StatusNotFound)
+	case strings.HasPrefix(err.Error(), "no pet found"):
+		utils.ErrorResponse(w, "Pet not found", 404, http.StatusNotFound)
+	case strings.Contains(err.Error(), "not authorized"):
+		utils.ErrorResponse(w, err.Error(), 403, http.StatusForbidden)
+	default:
+		utils.ErrorResponse(w, message+": "+err.Error(), 500, http.StatusInternalServerError)
+	}
+}
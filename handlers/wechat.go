@@ -28,6 +28,14 @@ func HandleWechat(w http.ResponseWriter, r *http.Request) {
 		HandleUpload(w, r)
 	case path == "map/reverseGeocode" && r.Method == http.MethodGet:
 		HandleReverseGeocode(w, r)
+	case path == "subscribe/templates" && r.Method == http.MethodGet:
+		handleSubscribeTemplates(w, r)
+	case path == "reminders/run" && r.Method == http.MethodPost:
+		handleRunReminders(w, r)
+	case path == "fake/cgi-bin/message/subscribe/send" && r.Method == http.MethodPost:
+		handleFakeSubscribeSend(w, r)
+	case path == "media/callback":
+		handleMediaCheckCallback(w, r)
 	default:
 		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
 	}
@@ -143,14 +151,47 @@ func HandleUpload(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Received file upload: %s, size: %d bytes, type: %s", header.Filename, header.Size, contentType)
 
-	// Upload file to COS
-	response, err := services.UploadFileToCOS(file, header.Filename, contentType)
+	// Upload file to COS, review photos and message images are kept apart from avatars.
+	// They must be recorded against their uploader before a review or message can use them.
+	prefix := "avatar"
+	switch r.FormValue("kind") {
+	case "review":
+		prefix = services.ReviewUploadPrefix
+	case "message":
+		prefix = services.MessageUploadPrefix
+	}
+	isOwnedUpload := prefix != "avatar"
+	if isOwnedUpload {
+		if _, err := utils.GetRequestUserID(r); err != nil {
+			utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+			return
+		}
+	}
+	response, err := services.UploadFileToCOS(file, header.Filename, contentType, prefix)
 	if err != nil {
 		log.Printf("Failed to upload file to COS: %v", err)
 		utils.ErrorResponse(w, "Failed to upload file: "+err.Error(), 500, http.StatusInternalServerError)
 		return
 	}
 
+	// Record the upload against the calling user so it can be included in data exports
+	if userID, err := utils.GetRequestUserID(r); err == nil {
+		if _, err := services.RecordUpload(userID, response, contentType, header.Size); err != nil {
+			log.Printf("Failed to record upload: %v", err)
+			if isOwnedUpload {
+				utils.ErrorResponse(w, "Failed to upload file: "+err.Error(), 500, http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// Check the image in the background, a rejected image is removed again
+		go func() {
+			if err := services.SubmitUploadCheck(userID, response); err != nil {
+				log.Printf("Failed to check upload %s: %v", response.Filename, err)
+			}
+		}()
+	}
+
 	// Return success response with file URL
 	utils.SuccessResponse(w, response, http.StatusOK)
 }
@@ -188,3 +229,42 @@ func HandleReverseGeocode(w http.ResponseWriter, r *http.Request) {
 	// Return response
 	utils.SuccessResponse(w, location, http.StatusOK)
 }
+
+// handleMediaCheckCallback handles the WeChat message push for async media checks.
+// GET verifies the push URL, POST delivers wxa_media_check events.
+func handleMediaCheckCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if !services.VerifyWechatPushSignature(query.Get("signature"), query.Get("timestamp"), query.Get("nonce")) {
+		utils.ErrorResponse(w, "Invalid signature", 403, http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Write([]byte(query.Get("echostr")))
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		var event models.WechatMediaCheckEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+			return
+		}
+
+		if event.Event == "wxa_media_check" {
+			if err := services.HandleMediaCheckEvent(event); err != nil {
+				log.Printf("Failed to handle media check %s: %v", event.TraceID, err)
+			}
+		}
+
+		// WeChat retries the push unless it receives "success"
+		w.Write([]byte("success"))
+	default:
+		utils.ErrorResponse(w, "Method not allowed", 405, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"playtime-go/models"
+	"playtime-go/services"
+	"playtime-go/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// handlePetWeights handles /pet/{id}/weights and /pet/{id}/weights/{weightId}
+func handlePetWeights(w http.ResponseWriter, r *http.Request, petID string, urlParts []string) {
+	id, err := primitive.ObjectIDFromHex(petID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid pet ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	userID, err := utils.GetRequestUserID(r)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), 401, http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(urlParts) == 0:
+		getPetWeights(w, r, id, userID)
+	case r.Method == http.MethodPost && len(urlParts) == 0:
+		addPetWeight(w, r, id, userID)
+	case r.Method == http.MethodDelete && len(urlParts) == 1:
+		deletePetWeight(w, r, id, userID, urlParts[0])
+	default:
+		utils.ErrorResponse(w, "Method not allowed or invalid URL", 405, http.StatusMethodNotAllowed)
+	}
+}
+
+// getPetWeights handles GET requests for a pet's weight series and trend statistics
+func getPetWeights(w http.ResponseWriter, r *http.Request, petID primitive.ObjectID, userID primitive.ObjectID) {
+	summary, err := services.GetWeightSummary(petID, userID)
+	if err != nil {
+		petResourceErrorResponse(w, "Failed to get weights", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, summary, http.StatusOK)
+}
+
+// addPetWeight handles POST requests to record a weight measurement
+func addPetWeight(w http.ResponseWriter, r *http.Request, petID primitive.ObjectID, userID primitive.ObjectID) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to read request body", 400, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	var request models.WeightRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		utils.ErrorResponse(w, "Invalid request format", 400, http.StatusBadRequest)
+		return
+	}
+
+	// Validate request
+	if request.WeightKg <= 0 || request.WeightKg > 150 {
+		utils.ErrorResponse(w, "Weight must be between 0 and 150 kg", 400, http.StatusBadRequest)
+		return
+	}
+
+	record, err := services.AddWeightRecord(petID, userID, request)
+	if err != nil {
+		petResourceErrorResponse(w, "Failed to record weight", err)
+		return
+	}
+
+	// Return response
+	utils.SuccessResponse(w, record, http.StatusCreated)
+}
+
+// deletePetWeight handles DELETE requests to remove a weight measurement
+func deletePetWeight(w http.ResponseWriter, r *http.Request, petID primitive.ObjectID, userID primitive.ObjectID, weightID string) {
+	id, err := primitive.ObjectIDFromHex(weightID)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid weight record ID format", 400, http.StatusBadRequest)
+		return
+	}
+
+	if err := services.DeleteWeightRecord(petID, id, userID); err != nil {
+		petResourceErrorResponse(w, "Failed to delete weight", err)
+		return
+	}
+
+	// Return success response
+	utils.SuccessResponse(w, map[string]string{"message": "Weight record deleted successfully"}, http.StatusOK)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,13 +12,23 @@ import (
 	"playtime-go/services"
 	"playtime-go/utils"
 	"syscall"
+	"time"
 )
 
 func main() {
 	// Initialize router
 	router := http.NewServeMux()
 
+	// Callers are identified by the session tokens they sign in for
+	utils.SetSessionVerifier(services.VerifySessionToken)
+
+	// Push events only reach other instances through the configured broker
+	if err := services.InitEventBroker(); err != nil {
+		log.Fatalf("Failed to set up push events: %v", err)
+	}
+
 	// Register routes with logging middleware
+	router.HandleFunc("/session", utils.LoggingMiddleware(handlers.HandleSession))
 	router.HandleFunc("/token", utils.LoggingMiddleware(handlers.HandleToken))
 	router.HandleFunc("/phone", utils.LoggingMiddleware(handlers.HandlePhone))
 	router.HandleFunc("/wechat/", utils.LoggingMiddleware(handlers.HandleWechat))
@@ -31,9 +42,56 @@ func main() {
 	router.HandleFunc("/pet", utils.LoggingMiddleware(handlers.HandlePet))
 	router.HandleFunc("/pet/", utils.LoggingMiddleware(handlers.HandlePet)) // This will catch all /pet/* paths
 
+	// breed catalog
+	router.HandleFunc("/breed", utils.LoggingMiddleware(handlers.HandleBreed))
+	router.HandleFunc("/breed/", utils.LoggingMiddleware(handlers.HandleBreed))
+
+	// lost and found
+	router.HandleFunc("/lost", utils.LoggingMiddleware(handlers.HandleLost))
+	router.HandleFunc("/lost/", utils.LoggingMiddleware(handlers.HandleLost))
+
+	// walk tracking
+	router.HandleFunc("/walk", utils.LoggingMiddleware(handlers.HandleWalk))
+	router.HandleFunc("/walk/", utils.LoggingMiddleware(handlers.HandleWalk))
+
+	// playdate events
+	router.HandleFunc("/event", utils.LoggingMiddleware(handlers.HandleEvent))
+	router.HandleFunc("/event/", utils.LoggingMiddleware(handlers.HandleEvent))
+
 	router.HandleFunc("/place", utils.LoggingMiddleware(handlers.HandlePlace)) // This will catch all /place/* paths
 	router.HandleFunc("/place/", utils.LoggingMiddleware(handlers.HandlePlace))
 
+	// saved place lists
+	router.HandleFunc("/list", utils.LoggingMiddleware(handlers.HandlePlaceList))
+	router.HandleFunc("/list/", utils.LoggingMiddleware(handlers.HandlePlaceList))
+
+	// place ownership claims
+	router.HandleFunc("/claim", utils.LoggingMiddleware(handlers.HandleClaim))
+	router.HandleFunc("/claim/", utils.LoggingMiddleware(handlers.HandleClaim))
+
+	// moderation of held and reported content
+	router.HandleFunc("/moderation/", utils.LoggingMiddleware(handlers.HandleModeration))
+
+	// abuse reports
+	router.HandleFunc("/report", utils.LoggingMiddleware(handlers.HandleReport))
+	router.HandleFunc("/report/", utils.LoggingMiddleware(handlers.HandleReport))
+
+	// follows and activity feed
+	router.HandleFunc("/follow", utils.LoggingMiddleware(handlers.HandleFollow))
+	router.HandleFunc("/follow/", utils.LoggingMiddleware(handlers.HandleFollow))
+	router.HandleFunc("/feed", utils.LoggingMiddleware(handlers.HandleFeed))
+
+	// direct messages
+	router.HandleFunc("/message/", utils.LoggingMiddleware(handlers.HandleMessage))
+
+	// notification inbox
+	router.HandleFunc("/notification", utils.LoggingMiddleware(handlers.HandleNotification))
+	router.HandleFunc("/notification/", utils.LoggingMiddleware(handlers.HandleNotification))
+
+	// real-time push channel
+	router.HandleFunc("/push", utils.LoggingMiddleware(handlers.HandlePush))
+	router.HandleFunc("/push/", utils.LoggingMiddleware(handlers.HandlePush))
+
 	// review related
 	router.HandleFunc("/review/user/", utils.LoggingMiddleware(handlers.HandleReview))  // handle user reviews
 	router.HandleFunc("/review/place/", utils.LoggingMiddleware(handlers.HandleReview)) // handler place reviews
@@ -47,12 +105,127 @@ func main() {
 		log.Printf("Warning: Failed to create geospatial index: %v", err)
 	}
 
+	if err := services.EnsureHealthRecordIndexes(); err != nil {
+		log.Printf("Warning: Failed to create health record indexes: %v", err)
+	}
+
+	if err := services.EnsureWeightIndexes(); err != nil {
+		log.Printf("Warning: Failed to create weight indexes: %v", err)
+	}
+
+	if err := services.EnsurePhotoIndexes(); err != nil {
+		log.Printf("Warning: Failed to create pet photo indexes: %v", err)
+	}
+
+	if err := services.EnsureUserIndexes(); err != nil {
+		log.Printf("Warning: Failed to create user indexes: %v", err)
+	}
+
+	if err := services.EnsureLostPetIndexes(); err != nil {
+		log.Printf("Warning: Failed to create lost pet indexes: %v", err)
+	}
+
+	if err := services.EnsureEventIndexes(); err != nil {
+		log.Printf("Warning: Failed to create event indexes: %v", err)
+	}
+
+	if err := services.EnsureCheckInIndexes(); err != nil {
+		log.Printf("Warning: Failed to create check-in indexes: %v", err)
+	}
+
+	if err := services.EnsurePlaceListIndexes(); err != nil {
+		log.Printf("Warning: Failed to create place list indexes: %v", err)
+	}
+
+	if err := services.EnsureReviewVoteIndexes(); err != nil {
+		log.Printf("Warning: Failed to create review vote indexes: %v", err)
+	}
+
+	if err := services.EnsurePlaceClaimIndexes(); err != nil {
+		log.Printf("Warning: Failed to create place claim indexes: %v", err)
+	}
+	if err := services.EnsureReportIndexes(); err != nil {
+		log.Printf("Warning: Failed to create report indexes: %v", err)
+	}
+	if err := services.EnsureFeedIndexes(); err != nil {
+		log.Printf("Warning: Failed to create feed indexes: %v", err)
+	}
+	if err := services.EnsureMessageIndexes(); err != nil {
+		log.Printf("Warning: Failed to create message indexes: %v", err)
+	}
+	if err := services.EnsureNotificationIndexes(); err != nil {
+		log.Printf("Warning: Failed to create notification indexes: %v", err)
+	}
+	if err := services.EnsureWalkIndexes(); err != nil {
+		log.Printf("Warning: Failed to create walk indexes: %v", err)
+	}
+
+	if err := services.EnsureMediaCheckIndexes(); err != nil {
+		log.Printf("Warning: Failed to create media check indexes: %v", err)
+	}
+
+	if err := services.EnsureReminderLogIndexes(); err != nil {
+		log.Printf("Warning: Failed to create reminder log indexes: %v", err)
+	}
+
+	// Seed the breed catalog
+	if err := services.EnsureBreedIndexes(); err != nil {
+		log.Printf("Warning: Failed to create breed indexes: %v", err)
+	}
+	if err := services.SeedBreeds(); err != nil {
+		log.Printf("Warning: Failed to seed breeds: %v", err)
+	}
+	if migrated, err := services.MigratePetSizes(); err != nil {
+		log.Printf("Warning: Failed to migrate pet sizes: %v", err)
+	} else if migrated > 0 {
+		log.Printf("Migrated %d pets to size classes", migrated)
+	}
+
+	// Give existing pets a membership list
+	if err := services.EnsurePetIndexes(); err != nil {
+		log.Printf("Warning: Failed to create pet indexes: %v", err)
+	}
+	if migrated, err := services.MigratePetMembers(); err != nil {
+		log.Printf("Warning: Failed to migrate pet members: %v", err)
+	} else if migrated > 0 {
+		log.Printf("Migrated %d pets to membership lists", migrated)
+	}
+
+	// Move review ratings out of the legacy ratingStar field
+	if migrated, err := services.MigrateReviewRatings(); err != nil {
+		log.Printf("Warning: Failed to migrate review ratings: %v", err)
+	} else if migrated > 0 {
+		log.Printf("Migrated %d review ratings from ratingStar", migrated)
+	}
+
+	// Convert legacy pet ages into estimated birthdates
+	if migrated, err := services.MigratePetAges(); err != nil {
+		log.Printf("Warning: Failed to migrate pet ages: %v", err)
+	} else if migrated > 0 {
+		log.Printf("Migrated %d pets from age to birthdate", migrated)
+	}
+
+	// Make claim evidence uploaded as public files private
+	if migrated, err := services.MigrateClaimEvidence(); err != nil {
+		log.Printf("Warning: Failed to migrate claim evidence: %v", err)
+	} else if migrated > 0 {
+		log.Printf("Migrated evidence of %d claims to private storage", migrated)
+	}
+
 	// Setup graceful shutdown
 	setupGracefulShutdown()
 
-	// Start the server
+	// Start the server, the listener is opened first so the reminder sweep can reach the dry-run endpoint
 	fmt.Println("Server starting on :8080...")
-	if err := http.ListenAndServe(":8080", router); err != nil {
+	listener, err := net.Listen("tcp", ":8080")
+	if err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+
+	// Start delivering vaccination and care reminders
+	services.StartReminderScheduler(time.Hour)
+
+	if err := http.Serve(listener, router); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
@@ -0,0 +1,37 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Pet species, matching the values of BaseLocation.PetType
+const (
+	SpeciesDog   = "dog"
+	SpeciesCat   = "cat"
+	SpeciesOther = "other"
+)
+
+// Pet size classes, matching the values of BaseLocation.PetSize
+const (
+	SizeSmall  = "small"
+	SizeMedium = "medium"
+	SizeLarge  = "large"
+)
+
+// Breed is an entry of the breed catalog
+type Breed struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Species     string             `json:"species" bson:"species"`
+	NameZh      string             `json:"nameZh" bson:"nameZh"`
+	NameEn      string             `json:"nameEn" bson:"nameEn"`
+	Aliases     []string           `json:"aliases" bson:"aliases"`
+	SizeClass   string             `json:"sizeClass" bson:"sizeClass"`
+	WeightRange *BreedWeightRange  `json:"weightRange,omitempty" bson:"weightRange,omitempty"`
+}
+
+// BreedSuggestion is the compact form of a breed returned by autocomplete
+type BreedSuggestion struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	Species   string             `json:"species" bson:"species"`
+	NameZh    string             `json:"nameZh" bson:"nameZh"`
+	NameEn    string             `json:"nameEn" bson:"nameEn"`
+	SizeClass string             `json:"sizeClass" bson:"sizeClass"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CheckIn records a visit of a user, and optionally their pets, to a place
+type CheckIn struct {
+	ID        primitive.ObjectID   `json:"id,omitempty" bson:"_id,omitempty"`
+	PlaceID   primitive.ObjectID   `json:"placeId" bson:"placeId"`
+	PlaceName string               `json:"placeName" bson:"placeName"`
+	UserID    primitive.ObjectID   `json:"userId" bson:"userId"`
+	PetIDs    []primitive.ObjectID `json:"petIds" bson:"petIds"`
+	Distance  float64              `json:"distance" bson:"distance"` // Distance from the place when checking in, in meters
+	CreatedAt time.Time            `json:"createdAt" bson:"createdAt"`
+}
+
+// CheckInRequest represents the incoming request to check in at a place with the current position
+type CheckInRequest struct {
+	Latitude  float64              `json:"latitude"`
+	Longitude float64              `json:"longitude"`
+	PetIDs    []primitive.ObjectID `json:"petIds"`
+}
+
+// PlaceVisitStats summarizes the visits to a place
+type PlaceVisitStats struct {
+	PlaceID          primitive.ObjectID `json:"placeId"`
+	VisitCount       int64              `json:"visitCount"`
+	VisitorCount     int                `json:"visitorCount"`
+	RecentPetCount   int                `json:"recentPetCount"` // Distinct pets checked in during the recent window
+	RecentWindowDays int                `json:"recentWindowDays"`
+}
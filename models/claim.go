@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Place claim statuses
+const (
+	ClaimPending   = "pending"
+	ClaimApproved  = "approved"
+	ClaimRejected  = "rejected"
+	ClaimWithdrawn = "withdrawn"
+)
+
+// PlaceClaim is a merchant's request to become the verified manager of a place.
+// The phone number is verified through WeChat and evidence documents are private COS objects referenced by key.
+type PlaceClaim struct {
+	ID           primitive.ObjectID  `json:"id,omitempty" bson:"_id,omitempty"`
+	PlaceID      primitive.ObjectID  `json:"placeId" bson:"placeId"`
+	PlaceName    string              `json:"placeName" bson:"placeName"`
+	UserID       primitive.ObjectID  `json:"userId" bson:"userId"`
+	BusinessName string              `json:"businessName" bson:"businessName"`
+	ContactName  string              `json:"contactName" bson:"contactName"`
+	PhoneNumber  string              `json:"phoneNumber" bson:"phoneNumber"`
+	Evidence     []string            `json:"evidence" bson:"evidence"`
+	Note         string              `json:"note" bson:"note"`
+	Status       string              `json:"status" bson:"status"`
+	ReviewerID   *primitive.ObjectID `json:"reviewerId,omitempty" bson:"reviewerId,omitempty"`
+	ReviewNote   string              `json:"reviewNote,omitempty" bson:"reviewNote,omitempty"`
+	ReviewedAt   *time.Time          `json:"reviewedAt,omitempty" bson:"reviewedAt,omitempty"`
+	CreatedAt    time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt    time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+// PlaceClaimRequest represents the incoming request to claim a place.
+// PhoneCode is the code from the mini program's getPhoneNumber button.
+type PlaceClaimRequest struct {
+	PlaceID      primitive.ObjectID `json:"placeId"`
+	BusinessName string             `json:"businessName"`
+	ContactName  string             `json:"contactName"`
+	PhoneCode    string             `json:"phoneCode"`
+	Note         string             `json:"note"`
+}
+
+// PlaceClaimReviewRequest represents a moderator's decision on a claim
+type PlaceClaimReviewRequest struct {
+	Note string `json:"note"`
+}
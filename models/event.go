@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Event statuses
+const (
+	EventScheduled = "scheduled"
+	EventCancelled = "cancelled"
+)
+
+// RSVP statuses
+const (
+	RSVPGoing      = "going"
+	RSVPWaitlisted = "waitlisted"
+	RSVPCancelled  = "cancelled"
+)
+
+// Event represents a playdate meetup hosted at a place. Capacity counts pets.
+type Event struct {
+	ID            primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	LocationID    primitive.ObjectID `json:"locationId" bson:"locationId"`
+	PlaceName     string             `json:"placeName" bson:"placeName"`
+	Location      GeoLocation        `json:"location" bson:"location"`
+	HostID        primitive.ObjectID `json:"hostId" bson:"hostId"`
+	Title         string             `json:"title" bson:"title"`
+	Description   string             `json:"description" bson:"description"`
+	StartTime     time.Time          `json:"startTime" bson:"startTime"`
+	EndTime       time.Time          `json:"endTime" bson:"endTime"`
+	Capacity      int                `json:"capacity" bson:"capacity"`
+	PetSizes      []string           `json:"petSizes" bson:"petSizes"`
+	PetTypes      []string           `json:"petTypes" bson:"petTypes"`
+	Status        string             `json:"status" bson:"status"`
+	CancelReason  string             `json:"cancelReason,omitempty" bson:"cancelReason,omitempty"`
+	PetCount      int                `json:"petCount" bson:"petCount"`
+	WaitlistCount int                `json:"waitlistCount" bson:"waitlistCount"`
+	CreatedAt     time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt     time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// EventRequest represents the incoming request to create an event
+type EventRequest struct {
+	LocationID  primitive.ObjectID `json:"locationId"`
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	StartTime   time.Time          `json:"startTime"`
+	EndTime     time.Time          `json:"endTime"`
+	Capacity    int                `json:"capacity"`
+	PetSizes    []string           `json:"petSizes"`
+	PetTypes    []string           `json:"petTypes"`
+}
+
+// EventCancelRequest represents the incoming request to cancel an event
+type EventCancelRequest struct {
+	Reason string `json:"reason"`
+}
+
+// EventRSVP represents a user's attendance of an event with their pets
+type EventRSVP struct {
+	ID        primitive.ObjectID   `json:"id,omitempty" bson:"_id,omitempty"`
+	EventID   primitive.ObjectID   `json:"eventId" bson:"eventId"`
+	UserID    primitive.ObjectID   `json:"userId" bson:"userId"`
+	PetIDs    []primitive.ObjectID `json:"petIds" bson:"petIds"`
+	Status    string               `json:"status" bson:"status"`
+	CreatedAt time.Time            `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time            `json:"updatedAt" bson:"updatedAt"`
+}
+
+// EventRSVPRequest represents the incoming request to RSVP to an event
+type EventRSVPRequest struct {
+	PetIDs []primitive.ObjectID `json:"petIds"`
+}
+
+// EventSearchRequest represents a request to search for upcoming events near a point
+type EventSearchRequest struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Radius    float64 `json:"radius"` // Search radius in meters, default 5000
+	Limit     int64   `json:"limit"`  // Maximum number of results, default 20
+	PetSize   string  `json:"petSize"`
+	PetType   string  `json:"petType"`
+}
+
+// EventSearchResult wraps an Event with its distance to the search point
+type EventSearchResult struct {
+	Event    Event   `json:"event"`
+	Distance float64 `json:"distance"` // Distance to the search point in meters
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Export job statuses
+const (
+	ExportStatusPending   = "pending"
+	ExportStatusRunning   = "running"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+)
+
+// ExportJob tracks an asynchronous personal data export for a user
+type ExportJob struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `json:"userId" bson:"userId"`
+	Status      string             `json:"status" bson:"status"`
+	Filename    string             `json:"-" bson:"filename,omitempty"`
+	DownloadURL string             `json:"downloadUrl,omitempty" bson:"-"`
+	Error       string             `json:"error,omitempty" bson:"error,omitempty"`
+	ExpiresAt   *time.Time         `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// UserDataExport is the full set of data we hold about a user
+type UserDataExport struct {
+	User          User                  `json:"user"`
+	HomeArea      *UserHomeAreaResponse `json:"homeArea,omitempty"`
+	Pets          []Pet                 `json:"pets"`
+	HealthRecords []HealthRecord        `json:"healthRecords"`
+	Reviews       []Review              `json:"reviews"`
+	Places        []LocationResponse    `json:"places"`
+	Uploads       []Upload              `json:"uploads"`
+	Visits        []CheckIn             `json:"visits"`
+	ExportedAt    time.Time             `json:"exportedAt"`
+}
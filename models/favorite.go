@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PlaceList is a user's named collection of saved places, such as "weekend parks"
+type PlaceList struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `json:"userId" bson:"userId"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description" bson:"description"`
+	IsPublic    bool               `json:"isPublic" bson:"isPublic"`
+	ShareCode   string             `json:"shareCode,omitempty" bson:"shareCode,omitempty"`
+	Items       []PlaceListItem    `json:"items" bson:"items"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// PlaceListItem is a saved place in a list. The name and address are kept so the entry
+// can still be shown as a tombstone after the place is deleted.
+type PlaceListItem struct {
+	PlaceID   primitive.ObjectID `json:"placeId" bson:"placeId"`
+	Name      string             `json:"name" bson:"name"`
+	Address   string             `json:"address" bson:"address"`
+	Note      string             `json:"note,omitempty" bson:"note,omitempty"`
+	AddedAt   time.Time          `json:"addedAt" bson:"addedAt"`
+	DeletedAt *time.Time         `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+}
+
+// PlaceListRequest represents the incoming request to create or update a list
+type PlaceListRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsPublic    bool   `json:"isPublic"`
+}
+
+// PlaceListItemRequest represents the incoming request to add a place to a list
+type PlaceListItemRequest struct {
+	PlaceID primitive.ObjectID `json:"placeId"`
+	Note    string             `json:"note"`
+}
+
+// PlaceListOrderRequest represents the incoming request to reorder the places of a list
+type PlaceListOrderRequest struct {
+	PlaceIDs []primitive.ObjectID `json:"placeIds"`
+}
+
+// PlaceListEntry is a list item with the current place details. Place is empty and
+// Tombstone is set when the place has been deleted.
+type PlaceListEntry struct {
+	PlaceListItem
+	Place     *LocationResponse `json:"place,omitempty"`
+	Tombstone bool              `json:"tombstone"`
+}
+
+// PlaceListResponse represents the API response for a list with its entries
+type PlaceListResponse struct {
+	ID          primitive.ObjectID `json:"id"`
+	UserID      primitive.ObjectID `json:"userId"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	IsPublic    bool               `json:"isPublic"`
+	ShareCode   string             `json:"shareCode,omitempty"`
+	Entries     []PlaceListEntry   `json:"entries"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Follow target types
+const (
+	FollowTargetUser  = "user"
+	FollowTargetPlace = "place"
+)
+
+// Feed activity kinds
+const (
+	FeedKindReview  = "review"
+	FeedKindPet     = "pet"
+	FeedKindCheckIn = "checkin"
+)
+
+// Follow is a user following another user or a place
+type Follow struct {
+	ID         primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	FollowerID primitive.ObjectID `json:"followerId" bson:"followerId"`
+	TargetType string             `json:"targetType" bson:"targetType"`
+	TargetID   primitive.ObjectID `json:"targetId" bson:"targetId"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// FollowRequest represents the incoming request to follow a user or place
+type FollowRequest struct {
+	TargetType string             `json:"targetType"`
+	TargetID   primitive.ObjectID `json:"targetId"`
+}
+
+// FeedActivity is something a user did that shows up in their followers' feeds.
+// Reviews also show up for the followers of the reviewed place.
+type FeedActivity struct {
+	ID        primitive.ObjectID  `json:"id,omitempty" bson:"_id,omitempty"`
+	Kind      string              `json:"kind" bson:"kind"`
+	ActorID   primitive.ObjectID  `json:"actorId" bson:"actorId"`
+	ActorName string              `json:"actorName" bson:"actorName"`
+	SourceID  primitive.ObjectID  `json:"sourceId" bson:"sourceId"` // The review, pet or check-in
+	PlaceID   *primitive.ObjectID `json:"placeId,omitempty" bson:"placeId,omitempty"`
+	PlaceName string              `json:"placeName,omitempty" bson:"placeName,omitempty"`
+	Summary   string              `json:"summary" bson:"summary"`
+	CreatedAt time.Time           `json:"createdAt" bson:"createdAt"`
+}
+
+// FeedItem is a copy of an activity fanned out into one follower's feed
+type FeedItem struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty"`
+	UserID   primitive.ObjectID `bson:"userId"`
+	Activity FeedActivity       `bson:"activity"`
+}
+
+// FeedPage is one page of a user's feed, newest first.
+// NextCursor is passed back to get the following page and is empty on the last page.
+type FeedPage struct {
+	Items      []FeedActivity `json:"items"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
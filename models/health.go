@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Health record types
+const (
+	HealthRecordVaccination = "vaccination"
+	HealthRecordDeworming   = "deworming"
+	HealthRecordVetVisit    = "vet_visit"
+	HealthRecordMedication  = "medication"
+)
+
+// HealthRecord represents a single entry in a pet's health log
+type HealthRecord struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	PetID       primitive.ObjectID `json:"petId" bson:"petId"`
+	Type        string             `json:"type" bson:"type"`
+	Date        time.Time          `json:"date" bson:"date"`
+	VaccineName string             `json:"vaccineName,omitempty" bson:"vaccineName,omitempty"`
+	Product     string             `json:"product,omitempty" bson:"product,omitempty"`
+	Clinic      string             `json:"clinic,omitempty" bson:"clinic,omitempty"`
+	Medication  string             `json:"medication,omitempty" bson:"medication,omitempty"`
+	Dosage      string             `json:"dosage,omitempty" bson:"dosage,omitempty"`
+	EndDate     *time.Time         `json:"endDate,omitempty" bson:"endDate,omitempty"`
+	NextDueDate *time.Time         `json:"nextDueDate,omitempty" bson:"nextDueDate,omitempty"`
+	Notes       string             `json:"notes,omitempty" bson:"notes,omitempty"`
+	Attachments []string           `json:"attachments" bson:"attachments"`
+	CreatedBy   primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// HealthRecordRequest represents the incoming request to create or update a health record
+type HealthRecordRequest struct {
+	Type        string     `json:"type"`
+	Date        time.Time  `json:"date"`
+	VaccineName string     `json:"vaccineName"`
+	Product     string     `json:"product"`
+	Clinic      string     `json:"clinic"`
+	Medication  string     `json:"medication"`
+	Dosage      string     `json:"dosage"`
+	EndDate     *time.Time `json:"endDate"`
+	NextDueDate *time.Time `json:"nextDueDate"`
+	Notes       string     `json:"notes"`
+	Attachments []string   `json:"attachments"`
+}
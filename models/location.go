@@ -14,17 +14,18 @@ type GeoLocation struct {
 
 // BaseLocation contains common fields shared across location-related structs
 type BaseLocation struct {
-	Name             string           `json:"name" bson:"name" validate:"required,min=2,max=100"`
-	Address          string           `json:"address" bson:"address" validate:"required,min=5,max=200"`
-	Description      string           `json:"description" bson:"description" validate:"max=500"`
-	Category         string           `json:"category" bson:"category" validate:"required,oneof=park cafe restaurant shop other"`
-	Photos           []string         `json:"photos,omitempty" bson:"photos,omitempty" validate:"max=10"`
-	IsPetFriendly    bool             `json:"isPetFriendly" bson:"isPetFriendly"`
-	PetSize          []string         `json:"petSize" bson:"petSize" validate:"dive,omitempty,oneof=small medium large"`
-	PetType          []string         `json:"petType" bson:"petType" validate:"dive,omitempty,oneof=dog cat other"`
-	Zone             []string         `json:"zone" bson:"zone" validate:"required,dive,required"`
-	AddressComponent AddressComponent `json:"addressComponent" bson:"addressComponent" validate:"required"`
-	AdInfo           AdInfo           `json:"adInfo" bson:"adInfo" validate:"required"`
+	Name             string             `json:"name" bson:"name" validate:"required,min=2,max=100"`
+	Address          string             `json:"address" bson:"address" validate:"required,min=5,max=200"`
+	Description      string             `json:"description" bson:"description" validate:"max=500"`
+	Category         string             `json:"category" bson:"category" validate:"required,oneof=park cafe restaurant shop other"`
+	Photos           []string           `json:"photos,omitempty" bson:"photos,omitempty" validate:"max=10"`
+	IsPetFriendly    bool               `json:"isPetFriendly" bson:"isPetFriendly"`
+	PetSize          []string           `json:"petSize" bson:"petSize" validate:"dive,omitempty,oneof=small medium large"`
+	PetType          []string           `json:"petType" bson:"petType" validate:"dive,omitempty,oneof=dog cat other"`
+	Zone             []string           `json:"zone" bson:"zone" validate:"required,dive,required"`
+	AddressComponent AddressComponent   `json:"addressComponent" bson:"addressComponent" validate:"required"`
+	AdInfo           AdInfo             `json:"adInfo" bson:"adInfo" validate:"required"`
+	CreatedBy        primitive.ObjectID `json:"createdBy,omitempty" bson:"createdBy,omitempty"`
 }
 
 // Location represents a stored location in the system
@@ -34,14 +35,28 @@ type Location struct {
 	Location     GeoLocation `json:"location" bson:"location" validate:"required"`
 	CreatedAt    time.Time   `json:"createdAt" bson:"createdAt"`
 	UpdatedAt    time.Time   `json:"updatedAt" bson:"updatedAt"`
+
+	// ManagerID is the verified manager of a claimed place, who may reply to its reviews
+	ManagerID    *primitive.ObjectID `json:"managerId,omitempty" bson:"managerId,omitempty"`
+	ManagedSince *time.Time          `json:"managedSince,omitempty" bson:"managedSince,omitempty"`
+
+	// ModerationStatus is pending while a flagged name or description waits for a moderator
+	ModerationStatus string `json:"moderationStatus,omitempty" bson:"moderationStatus,omitempty"`
+
+	Followers int64 `json:"followerCount" bson:"followerCount"`
 }
 
 // LocationResponse represents the API response for a location
 type LocationResponse struct {
 	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	BaseLocation `bson:",inline"`
-	Latitude     float64 `json:"latitude" bson:"latitude"`
-	Longitude    float64 `json:"longitude" bson:"longitude"`
+	Latitude     float64             `json:"latitude" bson:"latitude"`
+	Longitude    float64             `json:"longitude" bson:"longitude"`
+	ManagerID    *primitive.ObjectID `json:"managerId,omitempty" bson:"managerId,omitempty"`
+	Verified     bool                `json:"verified" bson:"-"` // Whether the place was claimed by a verified manager
+
+	ModerationStatus string `json:"moderationStatus,omitempty" bson:"moderationStatus,omitempty"`
+	Followers        int64  `json:"followerCount" bson:"followerCount"`
 }
 
 // LocationRequest represents the incoming request to create or update a location
@@ -80,8 +95,9 @@ type SearchRequest struct {
 
 // SearchResult wraps a Location with additional distance information
 type SearchResult struct {
-	Location LocationResponse `json:"location"`
-	Distance float64          `json:"distance"` // Distance to the search point in meters
+	Location    LocationResponse `json:"location"`
+	Distance    float64          `json:"distance"`    // Distance to the search point in meters
+	IsFavorited bool             `json:"isFavorited"` // Whether the calling user saved the place in one of their lists
 }
 
 // ReverseGeocodeResponse represents the response from Tencent Maps API
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Lost pet report statuses
+const (
+	LostReportOpen     = "open"
+	LostReportResolved = "resolved"
+)
+
+// Ways a finder may contact the owner of a lost pet
+const (
+	ContactPreferencePhone  = "phone"
+	ContactPreferenceWechat = "wechat"
+	ContactPreferenceInApp  = "in_app"
+)
+
+// LostPetReport represents an alert that a pet has gone missing
+type LostPetReport struct {
+	ID                primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	PetID             primitive.ObjectID `json:"petId" bson:"petId"`
+	ReporterID        primitive.ObjectID `json:"reporterId" bson:"reporterId"`
+	PetName           string             `json:"petName" bson:"petName"`
+	Species           string             `json:"species" bson:"species"`
+	Breed             string             `json:"breed" bson:"breed"`
+	BreedID           primitive.ObjectID `json:"breedId,omitempty" bson:"breedId,omitempty"`
+	Location          GeoLocation        `json:"location" bson:"location"`
+	LastSeenAt        time.Time          `json:"lastSeenAt" bson:"lastSeenAt"`
+	Description       string             `json:"description" bson:"description"`
+	Photos            []string           `json:"photos" bson:"photos"`
+	ContactPreference string             `json:"contactPreference" bson:"contactPreference"`
+	ContactPhone      string             `json:"contactPhone,omitempty" bson:"contactPhone,omitempty"`
+	Status            string             `json:"status" bson:"status"`
+	Resolution        string             `json:"resolution,omitempty" bson:"resolution,omitempty"`
+	ResolvedAt        *time.Time         `json:"resolvedAt,omitempty" bson:"resolvedAt,omitempty"`
+	NotifiedCount     int                `json:"notifiedCount" bson:"notifiedCount"`
+	CreatedAt         time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt         time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// LostPetReportRequest represents the incoming request to report a lost pet
+type LostPetReportRequest struct {
+	PetID             primitive.ObjectID `json:"petId"`
+	Latitude          float64            `json:"latitude"`
+	Longitude         float64            `json:"longitude"`
+	LastSeenAt        time.Time          `json:"lastSeenAt"`
+	Description       string             `json:"description"`
+	Photos            []string           `json:"photos"`
+	ContactPreference string             `json:"contactPreference"`
+	ContactPhone      string             `json:"contactPhone"`
+}
+
+// LostPetResolveRequest represents the incoming request to close a lost pet report
+type LostPetResolveRequest struct {
+	Resolution string `json:"resolution"`
+}
+
+// FoundPetSighting represents a report of a pet seen or found without its owner
+type FoundPetSighting struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	ReporterID  primitive.ObjectID `json:"reporterId" bson:"reporterId"`
+	Species     string             `json:"species" bson:"species"`
+	Breed       string             `json:"breed" bson:"breed"`
+	BreedID     primitive.ObjectID `json:"breedId,omitempty" bson:"breedId,omitempty"`
+	Location    GeoLocation        `json:"location" bson:"location"`
+	SeenAt      time.Time          `json:"seenAt" bson:"seenAt"`
+	Description string             `json:"description" bson:"description"`
+	Photos      []string           `json:"photos" bson:"photos"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// FoundPetSightingRequest represents the incoming request to report a found pet
+type FoundPetSightingRequest struct {
+	Species     string    `json:"species"`
+	Breed       string    `json:"breed"`
+	Latitude    float64   `json:"latitude"`
+	Longitude   float64   `json:"longitude"`
+	SeenAt      time.Time `json:"seenAt"`
+	Description string    `json:"description"`
+	Photos      []string  `json:"photos"`
+}
+
+// LostPetMatch is an open lost pet report that may match a sighting
+type LostPetMatch struct {
+	Report     LostPetReport `json:"report"`
+	Distance   float64       `json:"distance"` // Distance to the sighting in meters
+	BreedMatch bool          `json:"breedMatch"`
+	Score      float64       `json:"score"`
+}
+
+// FoundPetSightingResponse is a stored sighting with the open reports it may match
+type FoundPetSightingResponse struct {
+	Sighting FoundPetSighting `json:"sighting"`
+	Matches  []LostPetMatch   `json:"matches"`
+}
+
+// LostPetSearchRequest represents a request to list open lost pet reports near a point
+type LostPetSearchRequest struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Radius    float64 `json:"radius"` // Search radius in meters, default 5000, at most 50000
+	Species   string  `json:"species"`
+	Limit     int64   `json:"limit"`
+}
+
+// LostPetSearchResult wraps a lost pet report with its distance to the search point
+type LostPetSearchResult struct {
+	Report   LostPetReport `json:"report"`
+	Distance float64       `json:"distance"` // Distance to the search point in meters
+}
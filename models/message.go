@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Message types
+const (
+	MessageTypeText  = "text"
+	MessageTypeImage = "image"
+	MessageTypePlace = "place"
+	MessageTypePet   = "pet"
+)
+
+// Message event types sent to connected clients
+const (
+	MessageEventMessage = "message"
+	MessageEventRead    = "read"
+)
+
+// Conversation is a direct message thread between two users.
+// Unread counts and read positions are keyed by the participant's hex ID.
+type Conversation struct {
+	ID             primitive.ObjectID            `json:"id,omitempty" bson:"_id,omitempty"`
+	ParticipantKey string                        `json:"-" bson:"participantKey"` // Sorted participant IDs, unique per pair
+	ParticipantIDs []primitive.ObjectID          `json:"participantIds" bson:"participantIds"`
+	LastMessage    *Message                      `json:"lastMessage,omitempty" bson:"lastMessage,omitempty"`
+	LastMessageAt  time.Time                     `json:"lastMessageAt" bson:"lastMessageAt"`
+	UnreadCounts   map[string]int64              `json:"-" bson:"unread,omitempty"`
+	ReadUpTo       map[string]primitive.ObjectID `json:"-" bson:"readUpTo,omitempty"`
+	CreatedAt      time.Time                     `json:"createdAt" bson:"createdAt"`
+}
+
+// ConversationResponse is a conversation as seen by one participant
+type ConversationResponse struct {
+	ID            primitive.ObjectID  `json:"id"`
+	OtherUser     ConversationUser    `json:"otherUser"`
+	LastMessage   *Message            `json:"lastMessage,omitempty"`
+	LastMessageAt time.Time           `json:"lastMessageAt"`
+	UnreadCount   int64               `json:"unreadCount"`
+	OtherReadUpTo *primitive.ObjectID `json:"otherReadUpTo,omitempty"` // Messages up to this ID were read by the other user
+}
+
+// ConversationUser is the public profile of the other participant
+type ConversationUser struct {
+	ID        primitive.ObjectID `json:"id"`
+	NickName  string             `json:"nickName"`
+	AvatarURL string             `json:"avatarUrl"`
+}
+
+// ConversationRequest represents the incoming request to open a conversation with a user
+type ConversationRequest struct {
+	UserID primitive.ObjectID `json:"userId"`
+}
+
+// Message is a direct message. Place and pet shares carry a snapshot of the shared name.
+type Message struct {
+	ID             primitive.ObjectID  `json:"id,omitempty" bson:"_id,omitempty"`
+	ConversationID primitive.ObjectID  `json:"conversationId" bson:"conversationId"`
+	SenderID       primitive.ObjectID  `json:"senderId" bson:"senderId"`
+	RecipientID    primitive.ObjectID  `json:"recipientId" bson:"recipientId"`
+	Type           string              `json:"type" bson:"type"`
+	Text           string              `json:"text,omitempty" bson:"text,omitempty"`
+	ImageURL       string              `json:"imageUrl,omitempty" bson:"imageUrl,omitempty"`
+	PlaceID        *primitive.ObjectID `json:"placeId,omitempty" bson:"placeId,omitempty"`
+	PetID          *primitive.ObjectID `json:"petId,omitempty" bson:"petId,omitempty"`
+	ShareName      string              `json:"shareName,omitempty" bson:"shareName,omitempty"`
+	ShareImageURL  string              `json:"shareImageUrl,omitempty" bson:"shareImageUrl,omitempty"`
+	CreatedAt      time.Time           `json:"createdAt" bson:"createdAt"`
+}
+
+// MessageRequest represents the incoming request to send a message.
+// ImageURL is a file the sender uploaded to /wechat/upload with kind "message".
+type MessageRequest struct {
+	Type     string              `json:"type"`
+	Text     string              `json:"text"`
+	ImageURL string              `json:"imageUrl"`
+	PlaceID  *primitive.ObjectID `json:"placeId"`
+	PetID    *primitive.ObjectID `json:"petId"`
+}
+
+// ReadReceipt tells a sender how far the other participant has read a conversation
+type ReadReceipt struct {
+	ConversationID primitive.ObjectID `json:"conversationId"`
+	UserID         primitive.ObjectID `json:"userId"`
+	ReadUpTo       primitive.ObjectID `json:"readUpTo"`
+	ReadAt         time.Time          `json:"readAt"`
+}
+
+// MessageEvent is pushed to a user's WebSocket connections and long polls
+type MessageEvent struct {
+	Type    string       `json:"type"`
+	Message *Message     `json:"message,omitempty"`
+	Read    *ReadReceipt `json:"read,omitempty"`
+}
+
+// MessagePollResponse is the result of a long poll, Cursor is passed as after in the next poll
+type MessagePollResponse struct {
+	Events []MessageEvent `json:"events"`
+	Cursor string         `json:"cursor,omitempty"`
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Moderation statuses of user content. Content without a status predates checks and counts as approved.
+const (
+	ModerationApproved = "approved"
+	ModerationPending  = "pending"
+	ModerationRejected = "rejected"
+	ModerationHidden   = "hidden"
+)
+
+// Content types that are checked and can be moderated
+const (
+	ContentTypeReview = "review"
+	ContentTypePet    = "pet"
+	ContentTypePlace  = "place"
+	ContentTypeUser   = "user"
+	ContentTypeMedia  = "media"
+)
+
+// Content check suggestions, matching the WeChat security API
+const (
+	ContentSuggestPass   = "pass"
+	ContentSuggestReview = "review"
+	ContentSuggestRisky  = "risky"
+)
+
+// ContentCheckResult is the outcome of checking a piece of user text
+type ContentCheckResult struct {
+	Suggest string `json:"suggest"`
+	Label   int    `json:"label,omitempty"`
+	Keyword string `json:"keyword,omitempty"`
+}
+
+// MediaCheck tracks an uploaded image sent for asynchronous checking
+type MediaCheck struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	TraceID   string             `json:"traceId" bson:"traceId"`
+	URL       string             `json:"url" bson:"url"`
+	Key       string             `json:"key" bson:"key"`
+	UserID    primitive.ObjectID `json:"userId" bson:"userId"`
+	Status    string             `json:"status" bson:"status"`
+	Suggest   string             `json:"suggest,omitempty" bson:"suggest,omitempty"`
+	Label     int                `json:"label,omitempty" bson:"label,omitempty"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// WechatMsgSecCheckRequest is the request body of the WeChat msg_sec_check API
+type WechatMsgSecCheckRequest struct {
+	Content string `json:"content"`
+	Version int    `json:"version"`
+	Scene   int    `json:"scene"`
+	OpenID  string `json:"openid"`
+}
+
+// WechatMediaCheckRequest is the request body of the WeChat media_check_async API
+type WechatMediaCheckRequest struct {
+	MediaURL  string `json:"media_url"`
+	MediaType int    `json:"media_type"`
+	Version   int    `json:"version"`
+	Scene     int    `json:"scene"`
+	OpenID    string `json:"openid"`
+}
+
+// WechatSecCheckResult is the overall verdict in WeChat security API responses and callbacks
+type WechatSecCheckResult struct {
+	Suggest string `json:"suggest"`
+	Label   int    `json:"label"`
+}
+
+// WechatSecCheckResponse is the response of the WeChat msg_sec_check and media_check_async APIs
+type WechatSecCheckResponse struct {
+	ErrCode int                  `json:"errcode"`
+	ErrMsg  string               `json:"errmsg"`
+	TraceID string               `json:"trace_id"`
+	Result  WechatSecCheckResult `json:"result"`
+}
+
+// WechatMediaCheckEvent is the wxa_media_check event WeChat pushes when an async media check finishes
+type WechatMediaCheckEvent struct {
+	MsgType string               `json:"MsgType"`
+	Event   string               `json:"Event"`
+	AppID   string               `json:"appid"`
+	TraceID string               `json:"trace_id"`
+	ErrCode int                  `json:"errcode"`
+	ErrMsg  string               `json:"errmsg"`
+	Result  WechatSecCheckResult `json:"result"`
+}
+
+// ModerationDecisionRequest represents a moderator's decision on held content
+type ModerationDecisionRequest struct {
+	Note string `json:"note"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notification types, each can be switched off in the user's preferences
+const (
+	NotificationReviewReply      = "review_reply"
+	NotificationPlaceReview      = "place_review"
+	NotificationEventReminder    = "event_reminder"
+	NotificationFollower         = "follower"
+	NotificationModerationResult = "moderation_result"
+)
+
+// NotificationTypes lists every notification type
+var NotificationTypes = []string{
+	NotificationReviewReply,
+	NotificationPlaceReview,
+	NotificationEventReminder,
+	NotificationFollower,
+	NotificationModerationResult,
+}
+
+// Notification is an entry in a user's inbox. ActorID is the user who caused it, if any,
+// and SourceType/SourceID point at the review, event, user or content it is about.
+type Notification struct {
+	ID         primitive.ObjectID  `json:"id,omitempty" bson:"_id,omitempty"`
+	UserID     primitive.ObjectID  `json:"userId" bson:"userId"`
+	Type       string              `json:"type" bson:"type"`
+	ActorID    *primitive.ObjectID `json:"actorId,omitempty" bson:"actorId,omitempty"`
+	ActorName  string              `json:"actorName,omitempty" bson:"actorName,omitempty"`
+	SourceType string              `json:"sourceType,omitempty" bson:"sourceType,omitempty"`
+	SourceID   primitive.ObjectID  `json:"sourceId,omitempty" bson:"sourceId,omitempty"`
+	Title      string              `json:"title" bson:"title"`
+	Body       string              `json:"body,omitempty" bson:"body,omitempty"`
+	Read       bool                `json:"read" bson:"read"`
+	ReadAt     *time.Time          `json:"readAt,omitempty" bson:"readAt,omitempty"`
+	CreatedAt  time.Time           `json:"createdAt" bson:"createdAt"`
+}
+
+// NotificationPage is a page of the inbox, NextCursor is passed as before to get the next page
+type NotificationPage struct {
+	Items      []Notification `json:"items"`
+	Unread     int64          `json:"unread"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
+
+// NotificationPreferences switches notification types on or off, types missing from the map are on
+type NotificationPreferences map[string]bool
@@ -1,34 +1,175 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Pet age stages used to filter pet lists
+const (
+	PetStagePuppy  = "puppy"
+	PetStageAdult  = "adult"
+	PetStageSenior = "senior"
+)
+
+// Pet energy levels
+const (
+	EnergyLow    = "low"
+	EnergyMedium = "medium"
+	EnergyHigh   = "high"
+)
+
+// Pet temperament tags
+const (
+	TemperamentFriendly    = "friendly"
+	TemperamentPlayful     = "playful"
+	TemperamentCalm        = "calm"
+	TemperamentGentle      = "gentle"
+	TemperamentShy         = "shy"
+	TemperamentDominant    = "dominant"
+	TemperamentReactive    = "reactive"
+	TemperamentIndependent = "independent"
+)
+
+// Pet member roles
+const (
+	PetRoleOwner   = "owner"
+	PetRoleCoOwner = "co_owner"
+	PetRoleSitter  = "sitter"
+)
+
+// PetMember is a user who belongs to a pet. Sitter access expires at ExpiresAt.
+type PetMember struct {
+	UserID    primitive.ObjectID `json:"userId" bson:"userId"`
+	Role      string             `json:"role" bson:"role"`
+	ExpiresAt *time.Time         `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+	AddedAt   time.Time          `json:"addedAt" bson:"addedAt"`
+}
+
+// IsActive reports whether the member's access has not expired
+func (m PetMember) IsActive(now time.Time) bool {
+	return m.ExpiresAt == nil || m.ExpiresAt.After(now)
+}
+
+// PetInvite is a shareable code that adds the user who redeems it as a member of a pet
+type PetInvite struct {
+	ID              primitive.ObjectID  `json:"id,omitempty" bson:"_id,omitempty"`
+	Code            string              `json:"code" bson:"code"`
+	PetID           primitive.ObjectID  `json:"petId" bson:"petId"`
+	Role            string              `json:"role" bson:"role"`
+	AccessExpiresAt *time.Time          `json:"accessExpiresAt,omitempty" bson:"accessExpiresAt,omitempty"`
+	ExpiresAt       time.Time           `json:"expiresAt" bson:"expiresAt"`
+	CreatedBy       primitive.ObjectID  `json:"createdBy" bson:"createdBy"`
+	UsedBy          *primitive.ObjectID `json:"usedBy,omitempty" bson:"usedBy,omitempty"`
+	UsedAt          *time.Time          `json:"usedAt,omitempty" bson:"usedAt,omitempty"`
+	CreatedAt       time.Time           `json:"createdAt" bson:"createdAt"`
+}
+
+// PetInviteRequest represents the incoming request to create an invite
+type PetInviteRequest struct {
+	Role            string     `json:"role"`
+	AccessExpiresAt *time.Time `json:"accessExpiresAt"`
+}
+
 // Pet represents a pet in the system
 type Pet struct {
-	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	Name      string             `json:"name" bson:"name"`
-	Gender    string             `json:"gender" bson:"gender"`
-	Size      string             `json:"size" bson:"size"`
-	Breed     string             `json:"breed" bson:"breed"`
-	Avatar    string             `json:"avatar" bson:"avatar"`
-	Character string             `json:"character" bson:"character"`
-	Age       int                `json:"age" bson:"age"`
-	OwnerID   primitive.ObjectID `json:"ownerId,omitempty" bson:"ownerId,omitempty"`
-	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
-	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
-}
-
-// PetRequest represents the incoming request to create or update a pet
+	ID                   primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name                 string             `json:"name" bson:"name"`
+	Gender               string             `json:"gender" bson:"gender"`
+	Species              string             `json:"species" bson:"species"`
+	Size                 string             `json:"size" bson:"size"`
+	Breed                string             `json:"breed" bson:"breed"`
+	BreedID              primitive.ObjectID `json:"breedId,omitempty" bson:"breedId,omitempty"`
+	Avatar               string             `json:"avatar" bson:"avatar"`
+	CoverPhoto           string             `json:"coverPhoto,omitempty" bson:"coverPhoto,omitempty"`
+	Character            string             `json:"character" bson:"character"`
+	Energy               string             `json:"energy,omitempty" bson:"energy,omitempty"`
+	Temperament          []string           `json:"temperament" bson:"temperament"`
+	BirthDate            *time.Time         `json:"birthDate,omitempty" bson:"birthDate,omitempty"`
+	BirthDateApproximate bool               `json:"birthDateApproximate" bson:"birthDateApproximate"`
+	OwnerID              primitive.ObjectID `json:"ownerId,omitempty" bson:"ownerId,omitempty"`
+	Members              []PetMember        `json:"members,omitempty" bson:"members"`
+	ModerationStatus     string             `json:"moderationStatus,omitempty" bson:"moderationStatus,omitempty"` // pending while a flagged name waits for a moderator
+	CreatedAt            time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt            time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// AgeInMonths returns the pet's age in whole months at the given time, and false if the birthdate is unknown
+func (p Pet) AgeInMonths(now time.Time) (int, bool) {
+	if p.BirthDate == nil {
+		return 0, false
+	}
+
+	birth := *p.BirthDate
+	months := (now.Year()-birth.Year())*12 + int(now.Month()) - int(birth.Month())
+	if now.Day() < birth.Day() {
+		months--
+	}
+	if months < 0 {
+		months = 0
+	}
+
+	return months, true
+}
+
+// MarshalJSON adds the computed age to the serialized pet
+func (p Pet) MarshalJSON() ([]byte, error) {
+	type petAlias Pet
+	out := struct {
+		petAlias
+		Age       *int `json:"age"`
+		AgeMonths *int `json:"ageMonths"`
+	}{petAlias: petAlias(p)}
+
+	if months, ok := p.AgeInMonths(time.Now()); ok {
+		years := months / 12
+		out.Age = &years
+		out.AgeMonths = &months
+	}
+
+	return json.Marshal(out)
+}
+
+// PetRequest represents the incoming request to create or update a pet.
+// Age is still accepted from older clients and converted into an approximate birthdate.
+// Energy and temperament are parsed from the character text when they are not given.
 type PetRequest struct {
-	Name      string             `json:"name"`
-	Gender    string             `json:"gender"`
-	Size      string             `json:"size"`
-	Breed     string             `json:"breed"`
-	Avatar    string             `json:"avatar"`
-	Character string             `json:"character"`
-	Age       int                `json:"age"`
-	OwnerID   primitive.ObjectID `json:"ownerId,omitempty"`
+	Name                 string             `json:"name"`
+	Gender               string             `json:"gender"`
+	Species              string             `json:"species"`
+	Size                 string             `json:"size"`
+	Breed                string             `json:"breed"`
+	Avatar               string             `json:"avatar"`
+	Character            string             `json:"character"`
+	Energy               string             `json:"energy"`
+	Temperament          []string           `json:"temperament"`
+	BirthDate            *time.Time         `json:"birthDate"`
+	BirthDateApproximate bool               `json:"birthDateApproximate"`
+	Age                  int                `json:"age"`
+	OwnerID              primitive.ObjectID `json:"ownerId,omitempty"`
+}
+
+// PlaymateProfile is the public view of a pet suggested as a playmate
+type PlaymateProfile struct {
+	ID          primitive.ObjectID `json:"id"`
+	Name        string             `json:"name"`
+	Species     string             `json:"species"`
+	Size        string             `json:"size"`
+	Breed       string             `json:"breed"`
+	Avatar      string             `json:"avatar"`
+	CoverPhoto  string             `json:"coverPhoto,omitempty"`
+	Age         *int               `json:"age"`
+	Energy      string             `json:"energy,omitempty"`
+	Temperament []string           `json:"temperament"`
+}
+
+// PlaymateSuggestion is a nearby pet with its compatibility score. DistanceKm is rounded up
+// to a whole kilometer between the owners' approximate home areas.
+type PlaymateSuggestion struct {
+	Pet        PlaymateProfile `json:"pet"`
+	Score      int             `json:"score"`
+	Reasons    []string        `json:"reasons"`
+	DistanceKm int             `json:"distanceKm"`
 }
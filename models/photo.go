@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PetPhoto represents a photo in a pet's gallery
+type PetPhoto struct {
+	ID         primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	PetID      primitive.ObjectID `json:"petId" bson:"petId"`
+	URL        string             `json:"url" bson:"url"`
+	Key        string             `json:"-" bson:"key"`
+	Album      string             `json:"album,omitempty" bson:"album,omitempty"`
+	Caption    string             `json:"caption" bson:"caption"`
+	Order      int                `json:"order" bson:"order"`
+	IsCover    bool               `json:"isCover" bson:"isCover"`
+	TakenAt    *time.Time         `json:"takenAt,omitempty" bson:"takenAt,omitempty"`
+	UploadedBy primitive.ObjectID `json:"uploadedBy" bson:"uploadedBy"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt  time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// PetPhotoRequest represents the incoming request to update a photo's details
+type PetPhotoRequest struct {
+	Album   string     `json:"album"`
+	Caption string     `json:"caption"`
+	TakenAt *time.Time `json:"takenAt"`
+	IsCover bool       `json:"isCover"`
+}
+
+// PetPhotoOrderRequest represents the incoming request to reorder a pet's photos
+type PetPhotoOrderRequest struct {
+	PhotoIDs []primitive.ObjectID `json:"photoIds"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,4 +16,112 @@ type Review struct {
 	Content    string             `json:"content" bson:"content"`
 	Rating     int                `json:"rating" bson:"rating"`
 	Date       time.Time          `json:"date" bson:"date"`
+
+	// VerifiedVisit is set when the user checked in at the place before reviewing it.
+	// Clients set RequireCheckIn to reject the review when there was no check-in.
+	VerifiedVisit bool `json:"verified_visit" bson:"verifiedVisit"`
+
+	// ModerationStatus is pending while flagged content waits for a moderator
+	ModerationStatus string `json:"moderation_status,omitempty" bson:"moderationStatus,omitempty"`
+	RequireCheckIn   bool   `json:"require_checkin,omitempty" bson:"-"`
+
+	// Helpfulness votes, HelpfulScore is the Wilson score lower bound used to rank by usefulness
+	HelpfulCount   int     `json:"helpful_count" bson:"helpfulCount"`
+	UnhelpfulCount int     `json:"unhelpful_count" bson:"unhelpfulCount"`
+	HelpfulScore   float64 `json:"helpful_score" bson:"helpfulScore"`
+
+	// Replies from the place manager and the reviewer, in the order they were posted
+	Replies []ReviewReply `json:"replies" bson:"replies,omitempty"`
+
+	// Photos are uploaded to COS first, clients send their URLs in PhotoURLs
+	Photos    []ReviewPhoto `json:"photos" bson:"photos,omitempty"`
+	PhotoURLs []string      `json:"photo_urls,omitempty" bson:"-"`
+}
+
+// MarshalJSON serializes a review with empty reply and photo lists instead of null
+func (r Review) MarshalJSON() ([]byte, error) {
+	type reviewAlias Review
+	out := reviewAlias(r)
+	if out.Replies == nil {
+		out.Replies = []ReviewReply{}
+	}
+	if out.Photos == nil {
+		out.Photos = []ReviewPhoto{}
+	}
+	return json.Marshal(out)
+}
+
+// MaxReviewPhotos is the most photos a single review can have
+const MaxReviewPhotos = 9
+
+// ReviewPhoto is a photo attached to a review, the thumbnail is generated after the review is saved
+type ReviewPhoto struct {
+	URL          string `json:"url" bson:"url"`
+	Key          string `json:"-" bson:"key"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty" bson:"thumbnailUrl,omitempty"`
+	ThumbnailKey string `json:"-" bson:"thumbnailKey,omitempty"`
+}
+
+// PlaceReviewPhoto is a review photo listed across all reviews of a place
+type PlaceReviewPhoto struct {
+	ReviewPhoto `bson:",inline"`
+	ReviewID    primitive.ObjectID `json:"review_id" bson:"reviewId"`
+	UserID      string             `json:"user_id" bson:"userId"`
+	UserName    string             `json:"user_name" bson:"userName"`
+	Date        time.Time          `json:"date" bson:"date"`
+}
+
+// PlaceReviewPhotoPage is one page of a place's review photos
+type PlaceReviewPhotoPage struct {
+	Photos  []PlaceReviewPhoto `json:"photos"`
+	Page    int64              `json:"page"`
+	Limit   int64              `json:"limit"`
+	HasMore bool               `json:"has_more"`
+}
+
+// Review reply author roles
+const (
+	ReplyRoleManager  = "manager"
+	ReplyRoleReviewer = "reviewer"
+)
+
+// ReviewReply is a reply in a review's thread, ParentID points at the reply it answers
+type ReviewReply struct {
+	ID         primitive.ObjectID  `json:"id" bson:"_id"`
+	ParentID   *primitive.ObjectID `json:"parent_id,omitempty" bson:"parentId,omitempty"`
+	UserID     string              `json:"user_id" bson:"userId"`
+	UserName   string              `json:"user_name" bson:"userName"`
+	UserAvatar string              `json:"user_avatar" bson:"userAvatar"`
+	Role       string              `json:"role" bson:"role"`
+	Content    string              `json:"content" bson:"content"`
+	Date       time.Time           `json:"date" bson:"date"`
+}
+
+// ReviewReplyRequest represents the incoming request to reply to a review
+type ReviewReplyRequest struct {
+	Content  string              `json:"content"`
+	ParentID *primitive.ObjectID `json:"parent_id,omitempty"`
+}
+
+// Review sort modes
+const (
+	ReviewSortNewest       = "newest"
+	ReviewSortHighestRated = "highest_rated"
+	ReviewSortLowestRated  = "lowest_rated"
+	ReviewSortMostHelpful  = "most_helpful"
+)
+
+// ReviewVote records whether a user found a review helpful, each user has at most one vote per review
+type ReviewVote struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	ReviewID  primitive.ObjectID `json:"review_id" bson:"reviewId"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"userId"`
+	Helpful   bool               `json:"helpful" bson:"helpful"`
+	CreatedAt time.Time          `json:"created_at" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updatedAt"`
+}
+
+// ReviewVoteRequest represents the incoming request to vote on a review
+type ReviewVoteRequest struct {
+	Helpful *bool `json:"helpful"`
 }
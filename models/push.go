@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Push event types
+const (
+	PushEventPlaceReview = "place_review"
+	PushEventRSVP        = "rsvp"
+	PushEventMessage     = "message"
+	PushEventMessageRead = "message_read"
+	PushEventLostPet     = "lost_pet_nearby"
+
+	// PushEventNotification is pushed when a notification lands in the user's inbox
+	PushEventNotification = "notification"
+)
+
+// PushEvent is sent to a user's open push connections. Data holds the event specific payload.
+type PushEvent struct {
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// PlaceReviewEvent tells the creator and manager of a place about a new review
+type PlaceReviewEvent struct {
+	PlaceID   string             `json:"placeId"`
+	PlaceName string             `json:"placeName"`
+	ReviewID  primitive.ObjectID `json:"reviewId"`
+	UserID    string             `json:"userId"`
+	UserName  string             `json:"userName"`
+	Rating    int                `json:"rating"`
+}
+
+// RSVPEvent tells the host about RSVP changes and attendees about their own RSVP changing
+type RSVPEvent struct {
+	EventID    primitive.ObjectID `json:"eventId"`
+	EventTitle string             `json:"eventTitle"`
+	UserID     primitive.ObjectID `json:"userId"`
+	Status     string             `json:"status"`
+	PetCount   int                `json:"petCount"`
+}
+
+// LostPetEvent alerts a user that a pet was lost inside their home area
+type LostPetEvent struct {
+	ReportID       primitive.ObjectID `json:"reportId"`
+	PetName        string             `json:"petName"`
+	Species        string             `json:"species"`
+	Breed          string             `json:"breed"`
+	LastSeenAt     time.Time          `json:"lastSeenAt"`
+	DistanceMeters float64            `json:"distanceMeters"`
+}
+
+// SessionTokenRequest exchanges a WeChat login code for a push session token
+type SessionTokenRequest struct {
+	Code string `json:"code"`
+}
+
+// SessionToken authenticates long-lived push connections, which cannot always send custom headers
+type SessionToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
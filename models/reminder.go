@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reminder kinds, each backed by its own subscribe message template
+const (
+	ReminderKindVaccination = "vaccination"
+	ReminderKindDeworming   = "deworming"
+	ReminderKindBirthday    = "birthday"
+	ReminderKindLostPet     = "lost_pet"
+	ReminderKindEvent       = "event"
+	ReminderKindReviewReply = "review_reply"
+)
+
+// Reminder delivery statuses
+const (
+	ReminderStatusSending = "sending"
+	ReminderStatusSent    = "sent"
+	ReminderStatusDryRun  = "dry_run"
+	ReminderStatusFailed  = "failed"
+)
+
+// Subscription tracks how many subscribe messages a user has consented to for a template.
+// WeChat one-time subscriptions allow one message per acceptance, so each consent adds one send.
+type Subscription struct {
+	ID         primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `json:"userId" bson:"userId"`
+	Kind       string             `json:"kind" bson:"kind"`
+	TemplateID string             `json:"templateId" bson:"templateId"`
+	Remaining  int                `json:"remaining" bson:"remaining"`
+	UpdatedAt  time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// SubscriptionRequest records the result of wx.requestSubscribeMessage for one template
+type SubscriptionRequest struct {
+	TemplateID string `json:"templateId"`
+	Accepted   bool   `json:"accepted"`
+}
+
+// ReminderLog records a single reminder delivery attempt
+type ReminderLog struct {
+	ID         primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `json:"userId" bson:"userId"`
+	PetID      primitive.ObjectID `json:"petId" bson:"petId"`
+	RecordID   primitive.ObjectID `json:"recordId,omitempty" bson:"recordId,omitempty"`
+	Kind       string             `json:"kind" bson:"kind"`
+	Stage      string             `json:"stage" bson:"stage"`
+	TemplateID string             `json:"templateId" bson:"templateId"`
+	DueDate    time.Time          `json:"dueDate" bson:"dueDate"`
+	Status     string             `json:"status" bson:"status"`
+	ErrCode    int                `json:"errCode,omitempty" bson:"errCode,omitempty"`
+	ErrMsg     string             `json:"errMsg,omitempty" bson:"errMsg,omitempty"`
+	SentAt     time.Time          `json:"sentAt" bson:"sentAt"`
+	// Claimed is set while the log blocks further deliveries of the same reminder, failed attempts release it
+	Claimed bool `json:"-" bson:"claimed,omitempty"`
+}
+
+// SubscribeMessageValue is a single template field value
+type SubscribeMessageValue struct {
+	Value string `json:"value"`
+}
+
+// SubscribeMessageRequest is the request body of the WeChat subscribe message API
+type SubscribeMessageRequest struct {
+	ToUser           string                           `json:"touser"`
+	TemplateID       string                           `json:"template_id"`
+	Page             string                           `json:"page,omitempty"`
+	MiniprogramState string                           `json:"miniprogram_state,omitempty"`
+	Data             map[string]SubscribeMessageValue `json:"data"`
+}
+
+// SubscribeMessageResponse is the response of the WeChat subscribe message API
+type SubscribeMessageResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
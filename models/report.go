@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Report reason codes
+const (
+	ReportReasonSpam          = "spam"
+	ReportReasonHarassment    = "harassment"
+	ReportReasonInappropriate = "inappropriate"
+	ReportReasonMisleading    = "misleading"
+	ReportReasonAnimalWelfare = "animal_welfare"
+	ReportReasonOther         = "other"
+)
+
+// Report statuses
+const (
+	ReportOpen      = "open"
+	ReportDismissed = "dismissed"
+	ReportActioned  = "actioned"
+)
+
+// Moderation actions
+const (
+	ModerationActionApprove  = "approve"
+	ModerationActionReject   = "reject"
+	ModerationActionAutoHide = "auto_hide"
+	ModerationActionDismiss  = "dismiss"
+	ModerationActionHide     = "hide"
+	ModerationActionDelete   = "delete"
+	ModerationActionBan      = "ban"
+)
+
+// Report is a user's abuse report against a review, place, pet or user.
+// A reporter has at most one open report per target.
+type Report struct {
+	ID         primitive.ObjectID  `json:"id,omitempty" bson:"_id,omitempty"`
+	TargetType string              `json:"targetType" bson:"targetType"`
+	TargetID   primitive.ObjectID  `json:"targetId" bson:"targetId"`
+	ReporterID primitive.ObjectID  `json:"reporterId" bson:"reporterId"`
+	Reason     string              `json:"reason" bson:"reason"`
+	Details    string              `json:"details" bson:"details"`
+	Status     string              `json:"status" bson:"status"`
+	Trusted    bool                `json:"trusted" bson:"trusted"` // false when the reporter's account was too new to count towards an auto-hide
+	Resolution string              `json:"resolution,omitempty" bson:"resolution,omitempty"`
+	ResolvedBy *primitive.ObjectID `json:"resolvedBy,omitempty" bson:"resolvedBy,omitempty"`
+	ResolvedAt *time.Time          `json:"resolvedAt,omitempty" bson:"resolvedAt,omitempty"`
+	CreatedAt  time.Time           `json:"createdAt" bson:"createdAt"`
+}
+
+// ReportRequest represents the incoming request to report something
+type ReportRequest struct {
+	TargetType string             `json:"targetType"`
+	TargetID   primitive.ObjectID `json:"targetId"`
+	Reason     string             `json:"reason"`
+	Details    string             `json:"details"`
+}
+
+// ReportCase groups the open reports against one target in the moderation queue
+type ReportCase struct {
+	TargetType      string             `json:"targetType" bson:"targetType"`
+	TargetID        primitive.ObjectID `json:"targetId" bson:"targetId"`
+	OpenReports     int                `json:"openReports" bson:"openReports"`
+	Reasons         []string           `json:"reasons" bson:"reasons"`
+	FirstReportedAt time.Time          `json:"firstReportedAt" bson:"firstReportedAt"`
+	LastReportedAt  time.Time          `json:"lastReportedAt" bson:"lastReportedAt"`
+}
+
+// ModerationActionRequest represents a moderator's action on a reported target
+type ModerationActionRequest struct {
+	TargetType string             `json:"targetType"`
+	TargetID   primitive.ObjectID `json:"targetId"`
+	Action     string             `json:"action"`
+	Note       string             `json:"note"`
+}
+
+// ModerationAction is an audit trail entry for a moderation decision.
+// Automatic actions have no moderator.
+type ModerationAction struct {
+	ID          primitive.ObjectID  `json:"id,omitempty" bson:"_id,omitempty"`
+	ModeratorID *primitive.ObjectID `json:"moderatorId,omitempty" bson:"moderatorId,omitempty"`
+	Action      string              `json:"action" bson:"action"`
+	TargetType  string              `json:"targetType" bson:"targetType"`
+	TargetID    primitive.ObjectID  `json:"targetId" bson:"targetId"`
+	Note        string              `json:"note,omitempty" bson:"note,omitempty"`
+	Reports     int64               `json:"reports" bson:"reports"`
+	CreatedAt   time.Time           `json:"createdAt" bson:"createdAt"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Upload records a file a user uploaded to COS
+type Upload struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `json:"userId,omitempty" bson:"userId,omitempty"`
+	URL         string             `json:"url" bson:"url"`
+	Filename    string             `json:"filename" bson:"filename"`
+	ContentType string             `json:"contentType" bson:"contentType"`
+	Size        int64              `json:"size" bson:"size"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+}
@@ -8,14 +8,22 @@ import (
 
 // User represents a user in the system
 type User struct {
-	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	NickName    string             `json:"nickName" bson:"nickName"`
-	PhoneNumber string             `json:"phoneNumber" bson:"phoneNumber"`
-	AvatarURL   string             `json:"avatarUrl" bson:"avatarUrl"`
-	OpenID      string             `json:"openId" bson:"openId"`
-	UnionID     string             `json:"unionId" bson:"unionId"`
-	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
-	UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
+	ID          primitive.ObjectID   `json:"id,omitempty" bson:"_id,omitempty"`
+	NickName    string               `json:"nickName" bson:"nickName"`
+	PhoneNumber string               `json:"phoneNumber" bson:"phoneNumber"`
+	AvatarURL   string               `json:"avatarUrl" bson:"avatarUrl"`
+	OpenID      string               `json:"openId" bson:"openId"`
+	UnionID     string               `json:"unionId" bson:"unionId"`
+	HomeArea    *UserHomeArea        `json:"-" bson:"homeArea,omitempty"`
+	Banned      bool                 `json:"banned,omitempty" bson:"banned,omitempty"`
+	BannedAt    *time.Time           `json:"bannedAt,omitempty" bson:"bannedAt,omitempty"`
+	BlockedIDs  []primitive.ObjectID `json:"-" bson:"blockedUserIds,omitempty"`
+	MutedIDs    []primitive.ObjectID `json:"-" bson:"mutedUserIds,omitempty"`
+	NotifyPrefs map[string]bool      `json:"-" bson:"notificationPreferences,omitempty"`
+	Followers   int64                `json:"followerCount" bson:"followerCount"`
+	Following   int64                `json:"followingCount" bson:"followingCount"`
+	CreatedAt   time.Time            `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time            `json:"updatedAt" bson:"updatedAt"`
 }
 
 // UserRequest represents the incoming request to create a user
@@ -26,3 +34,39 @@ type UserRequest struct {
 	OpenID      string `json:"openId"`
 	UnionID     string `json:"unionId"`
 }
+
+// UserBlockList is the private list of users a user has blocked or muted.
+// Blocked users cannot interact with the user, muted users' reviews are only hidden from them.
+type UserBlockList struct {
+	Blocked []primitive.ObjectID `json:"blocked"`
+	Muted   []primitive.ObjectID `json:"muted"`
+}
+
+// UserHomeArea is an opt-in approximate home location used for nearby features such as lost pet alerts.
+// It is never included in user responses, only its owner can read it back.
+type UserHomeArea struct {
+	Location         GeoLocation `json:"location" bson:"location"`
+	RadiusMeters     float64     `json:"radiusMeters" bson:"radiusMeters"`
+	LostPetAlerts    bool        `json:"lostPetAlerts" bson:"lostPetAlerts"`
+	PlaymateMatching bool        `json:"playmateMatching" bson:"playmateMatching"`
+	UpdatedAt        time.Time   `json:"updatedAt" bson:"updatedAt"`
+}
+
+// UserHomeAreaRequest represents the incoming request to set a user's home area
+type UserHomeAreaRequest struct {
+	Latitude         float64 `json:"latitude"`
+	Longitude        float64 `json:"longitude"`
+	RadiusMeters     float64 `json:"radiusMeters"`
+	LostPetAlerts    bool    `json:"lostPetAlerts"`
+	PlaymateMatching bool    `json:"playmateMatching"`
+}
+
+// UserHomeAreaResponse represents the API response for a user's home area
+type UserHomeAreaResponse struct {
+	Latitude         float64   `json:"latitude"`
+	Longitude        float64   `json:"longitude"`
+	RadiusMeters     float64   `json:"radiusMeters"`
+	LostPetAlerts    bool      `json:"lostPetAlerts"`
+	PlaymateMatching bool      `json:"playmateMatching"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GeoLineString represents a GeoJSON LineString, coordinates are [longitude, latitude] pairs
+type GeoLineString struct {
+	Type        string      `json:"type" bson:"type"`
+	Coordinates [][]float64 `json:"coordinates" bson:"coordinates"`
+}
+
+// WalkPoint is a timestamped position recorded during a walk
+type WalkPoint struct {
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Time      time.Time `json:"time"`
+}
+
+// WalkPlace is a place the walk route passed near
+type WalkPlace struct {
+	PlaceID        primitive.ObjectID `json:"placeId" bson:"placeId"`
+	Name           string             `json:"name" bson:"name"`
+	Category       string             `json:"category" bson:"category"`
+	DistanceMeters float64            `json:"distanceMeters" bson:"distanceMeters"`
+}
+
+// Walk is a logged walk with one or more pets. Route and Timestamps have one entry per recorded point.
+// Distance, duration and pace are computed from the route on the server.
+type Walk struct {
+	ID               primitive.ObjectID   `json:"id,omitempty" bson:"_id,omitempty"`
+	UserID           primitive.ObjectID   `json:"userId" bson:"userId"`
+	PetIDs           []primitive.ObjectID `json:"petIds" bson:"petIds"`
+	Title            string               `json:"title,omitempty" bson:"title,omitempty"`
+	Route            GeoLineString        `json:"route" bson:"route"`
+	Timestamps       []time.Time          `json:"timestamps" bson:"timestamps"`
+	StartedAt        time.Time            `json:"startedAt" bson:"startedAt"`
+	EndedAt          time.Time            `json:"endedAt" bson:"endedAt"`
+	DistanceMeters   float64              `json:"distanceMeters" bson:"distanceMeters"`
+	DurationSeconds  int64                `json:"durationSeconds" bson:"durationSeconds"`
+	PaceSecondsPerKm float64              `json:"paceSecondsPerKm" bson:"paceSecondsPerKm"`
+	Places           []WalkPlace          `json:"places" bson:"places"`
+	CreatedAt        time.Time            `json:"createdAt" bson:"createdAt"`
+}
+
+// WalkRequest represents the incoming request to log a walk from recorded points
+type WalkRequest struct {
+	PetIDs []primitive.ObjectID `json:"petIds"`
+	Title  string               `json:"title"`
+	Points []WalkPoint          `json:"points"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WeightRecord represents a single dated weight measurement of a pet
+type WeightRecord struct {
+	ID         primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	PetID      primitive.ObjectID `json:"petId" bson:"petId"`
+	WeightKg   float64            `json:"weightKg" bson:"weightKg"`
+	MeasuredAt time.Time          `json:"measuredAt" bson:"measuredAt"`
+	Notes      string             `json:"notes,omitempty" bson:"notes,omitempty"`
+	CreatedBy  primitive.ObjectID `json:"createdBy" bson:"createdBy"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// WeightRequest represents the incoming request to record a weight measurement
+type WeightRequest struct {
+	WeightKg   float64   `json:"weightKg"`
+	MeasuredAt time.Time `json:"measuredAt"`
+	Notes      string    `json:"notes"`
+}
+
+// WeightPoint is a point of the weight time series
+type WeightPoint struct {
+	ID            primitive.ObjectID `json:"id"`
+	MeasuredAt    time.Time          `json:"measuredAt"`
+	WeightKg      float64            `json:"weightKg"`
+	MovingAverage float64            `json:"movingAverage"`
+}
+
+// BreedWeightRange is the typical adult weight range of a breed and how fast weight may change
+type BreedWeightRange struct {
+	MinKg               float64 `json:"minKg"`
+	MaxKg               float64 `json:"maxKg"`
+	MaxMonthlyChangePct float64 `json:"maxMonthlyChangePct"`
+}
+
+// WeightSummary is the weight series of a pet with trend statistics
+type WeightSummary struct {
+	PetID          primitive.ObjectID `json:"petId"`
+	Series         []WeightPoint      `json:"series"`
+	LatestKg       float64            `json:"latestKg"`
+	Change30dPct   *float64           `json:"change30dPct"`
+	Change90dPct   *float64           `json:"change90dPct"`
+	TypicalRange   *BreedWeightRange  `json:"typicalRange"`
+	Abnormal       bool               `json:"abnormal"`
+	AbnormalReason string             `json:"abnormalReason,omitempty"`
+}
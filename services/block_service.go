@@ -0,0 +1,155 @@
+package services
+
+import (
+	"fmt"
+	"playtime-go/models"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxBlockedUsers caps the block and mute lists, they are embedded in the user document
+const maxBlockedUsers = 1000
+
+// GetUserBlockList returns the users the user has blocked and muted
+func GetUserBlockList(userID primitive.ObjectID) (*models.UserBlockList, error) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &models.UserBlockList{Blocked: user.BlockedIDs, Muted: user.MutedIDs}
+	if list.Blocked == nil {
+		list.Blocked = []primitive.ObjectID{}
+	}
+	if list.Muted == nil {
+		list.Muted = []primitive.ObjectID{}
+	}
+	return list, nil
+}
+
+// BlockUser adds a user to the user's block list.
+// The two users stop following each other, so neither sees the other's activity in their feed.
+func BlockUser(userID primitive.ObjectID, blockedID primitive.ObjectID) (*models.UserBlockList, error) {
+	list, err := addToBlockList(userID, blockedID, "blockedUserIds")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := removeFollowsBetween(userID, blockedID); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// UnblockUser removes a user from the user's block list
+func UnblockUser(userID primitive.ObjectID, blockedID primitive.ObjectID) (*models.UserBlockList, error) {
+	return removeFromBlockList(userID, blockedID, "blockedUserIds")
+}
+
+// MuteUser adds a user to the user's mute list
+func MuteUser(userID primitive.ObjectID, mutedID primitive.ObjectID) (*models.UserBlockList, error) {
+	return addToBlockList(userID, mutedID, "mutedUserIds")
+}
+
+// UnmuteUser removes a user from the user's mute list
+func UnmuteUser(userID primitive.ObjectID, mutedID primitive.ObjectID) (*models.UserBlockList, error) {
+	return removeFromBlockList(userID, mutedID, "mutedUserIds")
+}
+
+// addToBlockList adds the other user to one of the user's lists, adding someone already listed is a no-op
+func addToBlockList(userID primitive.ObjectID, otherID primitive.ObjectID, field string) (*models.UserBlockList, error) {
+	if userID == otherID {
+		return nil, fmt.Errorf("invalid block: cannot block or mute yourself")
+	}
+	if _, err := GetUserByID(otherID); err != nil {
+		return nil, err
+	}
+
+	// The size check keeps the list bounded, a user already listed matches the first clause
+	filter := bson.M{"_id": userID, "$or": []bson.M{
+		{field: otherID},
+		{fmt.Sprintf("%s.%d", field, maxBlockedUsers-1): bson.M{"$exists": false}},
+	}}
+	update := bson.M{"$addToSet": bson.M{field: otherID}, "$set": bson.M{"updatedAt": time.Now()}}
+	changed, err := UpdateMany(userCollection, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update block list: %v", err)
+	}
+	if changed == 0 {
+		if _, err := GetUserByID(userID); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("invalid block: at most %d users can be listed", maxBlockedUsers)
+	}
+
+	return GetUserBlockList(userID)
+}
+
+// removeFromBlockList removes the other user from one of the user's lists
+func removeFromBlockList(userID primitive.ObjectID, otherID primitive.ObjectID, field string) (*models.UserBlockList, error) {
+	update := bson.M{"$pull": bson.M{field: otherID}, "$set": bson.M{"updatedAt": time.Now()}}
+	if err := UpdateOne(userCollection, bson.M{"_id": userID}, update); err != nil {
+		return nil, fmt.Errorf("failed to update block list: %v", err)
+	}
+
+	return GetUserBlockList(userID)
+}
+
+// hiddenAuthorFilter returns the userId condition that drops reviews by users the viewer blocked or muted,
+// or nil when there is nothing to hide
+func hiddenAuthorFilter(viewerID *primitive.ObjectID) (bson.M, error) {
+	if viewerID == nil {
+		return nil, nil
+	}
+
+	user, err := GetUserByID(*viewerID)
+	if err != nil {
+		// An unknown viewer has no block list
+		if strings.HasPrefix(err.Error(), "no user found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	hidden := make([]string, 0, len(user.BlockedIDs)+len(user.MutedIDs))
+	for _, id := range append(user.BlockedIDs, user.MutedIDs...) {
+		hidden = append(hidden, id.Hex())
+	}
+	if len(hidden) == 0 {
+		return nil, nil
+	}
+
+	return bson.M{"$nin": hidden}, nil
+}
+
+// usersBlocking returns the users who have blocked the user
+func usersBlocking(userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	var users []models.User
+	findOptions := options.Find().SetProjection(bson.M{"_id": 1})
+	if err := FindMany(userCollection, bson.M{"blockedUserIds": userID}, &users, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to find blocking users: %v", err)
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids, nil
+}
+
+// ensureNotBlockedBy returns an error when the owner has blocked the user
+func ensureNotBlockedBy(ownerID primitive.ObjectID, userID primitive.ObjectID) error {
+	count, err := Count(userCollection, bson.M{"_id": ownerID, "blockedUserIds": userID})
+	if err != nil {
+		return fmt.Errorf("failed to check block list: %v", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("blocked by user: %s", ownerID.Hex())
+	}
+	return nil
+}
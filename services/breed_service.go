@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"playtime-go/db"
+	"playtime-go/models"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const breedCollection = "breeds"
+
+// breedSeed is the initial content of the breed catalog
+var breedSeed = []models.Breed{
+	{Species: models.SpeciesDog, NameZh: "吉娃娃", NameEn: "Chihuahua", Aliases: []string{"芝娃娃"}, SizeClass: models.SizeSmall,
+		WeightRange: &models.BreedWeightRange{MinKg: 1.5, MaxKg: 3, MaxMonthlyChangePct: 10}},
+	{Species: models.SpeciesDog, NameZh: "博美", NameEn: "Pomeranian", Aliases: []string{"博美犬"}, SizeClass: models.SizeSmall,
+		WeightRange: &models.BreedWeightRange{MinKg: 1.8, MaxKg: 3.5, MaxMonthlyChangePct: 10}},
+	{Species: models.SpeciesDog, NameZh: "泰迪", NameEn: "Toy Poodle", Aliases: []string{"贵宾", "贵宾犬", "Teddy"}, SizeClass: models.SizeSmall,
+		WeightRange: &models.BreedWeightRange{MinKg: 2, MaxKg: 4, MaxMonthlyChangePct: 10}},
+	{Species: models.SpeciesDog, NameZh: "比熊", NameEn: "Bichon Frise", Aliases: []string{"比熊犬", "Bichon"}, SizeClass: models.SizeSmall,
+		WeightRange: &models.BreedWeightRange{MinKg: 3, MaxKg: 6, MaxMonthlyChangePct: 10}},
+	{Species: models.SpeciesDog, NameZh: "法国斗牛犬", NameEn: "French Bulldog", Aliases: []string{"法斗", "Frenchie"}, SizeClass: models.SizeSmall,
+		WeightRange: &models.BreedWeightRange{MinKg: 8, MaxKg: 14, MaxMonthlyChangePct: 8}},
+	{Species: models.SpeciesDog, NameZh: "柴犬", NameEn: "Shiba Inu", Aliases: []string{"Shiba"}, SizeClass: models.SizeMedium,
+		WeightRange: &models.BreedWeightRange{MinKg: 8, MaxKg: 11, MaxMonthlyChangePct: 8}},
+	{Species: models.SpeciesDog, NameZh: "柯基", NameEn: "Corgi", Aliases: []string{"柯基犬", "Pembroke Welsh Corgi"}, SizeClass: models.SizeMedium,
+		WeightRange: &models.BreedWeightRange{MinKg: 10, MaxKg: 14, MaxMonthlyChangePct: 8}},
+	{Species: models.SpeciesDog, NameZh: "中华田园犬", NameEn: "Chinese Rural Dog", Aliases: []string{"土狗", "田园犬"}, SizeClass: models.SizeMedium,
+		WeightRange: &models.BreedWeightRange{MinKg: 10, MaxKg: 20, MaxMonthlyChangePct: 8}},
+	{Species: models.SpeciesDog, NameZh: "边境牧羊犬", NameEn: "Border Collie", Aliases: []string{"边牧"}, SizeClass: models.SizeMedium,
+		WeightRange: &models.BreedWeightRange{MinKg: 14, MaxKg: 20, MaxMonthlyChangePct: 7}},
+	{Species: models.SpeciesDog, NameZh: "萨摩耶", NameEn: "Samoyed", Aliases: []string{"萨摩"}, SizeClass: models.SizeLarge,
+		WeightRange: &models.BreedWeightRange{MinKg: 16, MaxKg: 30, MaxMonthlyChangePct: 6}},
+	{Species: models.SpeciesDog, NameZh: "哈士奇", NameEn: "Siberian Husky", Aliases: []string{"二哈", "Husky"}, SizeClass: models.SizeLarge,
+		WeightRange: &models.BreedWeightRange{MinKg: 16, MaxKg: 27, MaxMonthlyChangePct: 6}},
+	{Species: models.SpeciesDog, NameZh: "拉布拉多", NameEn: "Labrador Retriever", Aliases: []string{"拉布拉多犬", "Labrador"}, SizeClass: models.SizeLarge,
+		WeightRange: &models.BreedWeightRange{MinKg: 25, MaxKg: 36, MaxMonthlyChangePct: 6}},
+	{Species: models.SpeciesDog, NameZh: "金毛", NameEn: "Golden Retriever", Aliases: []string{"金毛寻回犬", "Golden"}, SizeClass: models.SizeLarge,
+		WeightRange: &models.BreedWeightRange{MinKg: 25, MaxKg: 34, MaxMonthlyChangePct: 6}},
+	{Species: models.SpeciesDog, NameZh: "德国牧羊犬", NameEn: "German Shepherd", Aliases: []string{"德牧"}, SizeClass: models.SizeLarge,
+		WeightRange: &models.BreedWeightRange{MinKg: 22, MaxKg: 40, MaxMonthlyChangePct: 6}},
+	{Species: models.SpeciesCat, NameZh: "中华田园猫", NameEn: "Chinese Domestic Cat", Aliases: []string{"土猫", "田园猫"}, SizeClass: models.SizeSmall,
+		WeightRange: &models.BreedWeightRange{MinKg: 3, MaxKg: 6, MaxMonthlyChangePct: 8}},
+	{Species: models.SpeciesCat, NameZh: "狸花猫", NameEn: "Chinese Li Hua", Aliases: []string{"狸花", "Dragon Li"}, SizeClass: models.SizeSmall,
+		WeightRange: &models.BreedWeightRange{MinKg: 3.5, MaxKg: 6, MaxMonthlyChangePct: 8}},
+	{Species: models.SpeciesCat, NameZh: "英国短毛猫", NameEn: "British Shorthair", Aliases: []string{"英短", "蓝猫"}, SizeClass: models.SizeSmall,
+		WeightRange: &models.BreedWeightRange{MinKg: 4, MaxKg: 8, MaxMonthlyChangePct: 8}},
+	{Species: models.SpeciesCat, NameZh: "美国短毛猫", NameEn: "American Shorthair", Aliases: []string{"美短"}, SizeClass: models.SizeSmall,
+		WeightRange: &models.BreedWeightRange{MinKg: 3.5, MaxKg: 7, MaxMonthlyChangePct: 8}},
+	{Species: models.SpeciesCat, NameZh: "布偶猫", NameEn: "Ragdoll", Aliases: []string{"布偶"}, SizeClass: models.SizeMedium,
+		WeightRange: &models.BreedWeightRange{MinKg: 4.5, MaxKg: 9, MaxMonthlyChangePct: 8}},
+	{Species: models.SpeciesCat, NameZh: "缅因猫", NameEn: "Maine Coon", Aliases: []string{"缅因"}, SizeClass: models.SizeMedium,
+		WeightRange: &models.BreedWeightRange{MinKg: 5, MaxKg: 11, MaxMonthlyChangePct: 8}},
+}
+
+// sizeAliases maps free-text sizes used by older clients to size classes
+var sizeAliases = map[string]string{
+	"small":  models.SizeSmall,
+	"s":      models.SizeSmall,
+	"小":      models.SizeSmall,
+	"小型":     models.SizeSmall,
+	"小型犬":    models.SizeSmall,
+	"medium": models.SizeMedium,
+	"m":      models.SizeMedium,
+	"中":      models.SizeMedium,
+	"中型":     models.SizeMedium,
+	"中型犬":    models.SizeMedium,
+	"large":  models.SizeLarge,
+	"l":      models.SizeLarge,
+	"大":      models.SizeLarge,
+	"大型":     models.SizeLarge,
+	"大型犬":    models.SizeLarge,
+}
+
+// NormalizeSize converts a free-text size into a size class, returning "" if it is not recognised
+func NormalizeSize(size string) string {
+	return sizeAliases[strings.ToLower(strings.TrimSpace(size))]
+}
+
+// SeedBreeds inserts catalog entries that are missing and refreshes existing ones
+func SeedBreeds() error {
+	for _, breed := range breedSeed {
+		filter := bson.M{"nameEn": breed.NameEn}
+		update := bson.M{"$set": bson.M{
+			"species":     breed.Species,
+			"nameZh":      breed.NameZh,
+			"aliases":     breed.Aliases,
+			"sizeClass":   breed.SizeClass,
+			"weightRange": breed.WeightRange,
+		}}
+		if err := UpsertOne(breedCollection, filter, update); err != nil {
+			return fmt.Errorf("failed to seed breed %s: %v", breed.NameEn, err)
+		}
+	}
+
+	return nil
+}
+
+// GetBreedByID retrieves a breed by ID
+func GetBreedByID(id primitive.ObjectID) (*models.Breed, error) {
+	filter := bson.M{"_id": id}
+	var breed models.Breed
+
+	err := FindOne(breedCollection, filter, &breed)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no breed found with ID: %s", id.Hex())
+		}
+		return nil, fmt.Errorf("failed to get breed by ID: %v", err)
+	}
+
+	return &breed, nil
+}
+
+// FindBreedByName finds the catalog entry whose Chinese name, English name or alias matches exactly
+func FindBreedByName(name string) (*models.Breed, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil
+	}
+
+	exact := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(name) + "$", Options: "i"}
+	filter := bson.M{"$or": []bson.M{
+		{"nameZh": exact},
+		{"nameEn": exact},
+		{"aliases": exact},
+	}}
+	var breed models.Breed
+
+	err := FindOne(breedCollection, filter, &breed)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find breed by name: %v", err)
+	}
+
+	return &breed, nil
+}
+
+// SearchBreeds finds breeds whose names or aliases contain the keyword
+func SearchBreeds(keyword string, species string, limit int64) ([]models.Breed, error) {
+	filter := breedNameFilter(regexp.QuoteMeta(strings.TrimSpace(keyword)), species)
+	var breeds []models.Breed
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "species", Value: 1}, {Key: "nameEn", Value: 1}})
+	if limit > 0 {
+		findOptions.SetLimit(limit)
+	} else {
+		findOptions.SetLimit(100) // Default limit
+	}
+
+	err := FindMany(breedCollection, filter, &breeds, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search breeds: %v", err)
+	}
+
+	return breeds, nil
+}
+
+// AutocompleteBreeds suggests breeds whose names or aliases start with the prefix
+func AutocompleteBreeds(prefix string, species string, limit int64) ([]models.BreedSuggestion, error) {
+	filter := breedNameFilter("^"+regexp.QuoteMeta(strings.TrimSpace(prefix)), species)
+	var suggestions []models.BreedSuggestion
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "nameEn", Value: 1}})
+	findOptions.SetProjection(bson.M{"species": 1, "nameZh": 1, "nameEn": 1, "sizeClass": 1})
+	if limit > 0 {
+		findOptions.SetLimit(limit)
+	} else {
+		findOptions.SetLimit(10) // Default limit
+	}
+
+	err := FindMany(breedCollection, filter, &suggestions, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to autocomplete breeds: %v", err)
+	}
+
+	return suggestions, nil
+}
+
+// breedNameFilter builds a filter matching a regex against all breed names
+func breedNameFilter(pattern string, species string) bson.M {
+	filter := bson.M{}
+	if pattern != "" && pattern != "^" {
+		regex := primitive.Regex{Pattern: pattern, Options: "i"}
+		filter["$or"] = []bson.M{
+			{"nameZh": regex},
+			{"nameEn": regex},
+			{"aliases": regex},
+		}
+	}
+	if species != "" {
+		filter["species"] = species
+	}
+	return filter
+}
+
+// normalizePetBreed resolves breed, species and size of a pet request against the catalog
+func normalizePetBreed(request *models.PetRequest) (*models.Breed, error) {
+	breed, err := FindBreedByName(request.Breed)
+	if err != nil {
+		return nil, err
+	}
+
+	// Requests with sizes that are not recognised are rejected by the handler, so only spellings of a size class arrive here
+	if size := NormalizeSize(request.Size); size != "" {
+		request.Size = size
+	}
+	if breed == nil {
+		return nil, nil
+	}
+
+	request.Breed = breed.NameZh
+	if request.Size == "" {
+		request.Size = breed.SizeClass
+	}
+
+	return breed, nil
+}
+
+// MigratePetSizes converts free-text sizes of existing pets into size classes.
+// Pets whose size is not recognised take the size class of their catalog breed, or are left as they are.
+func MigratePetSizes() (int, error) {
+	filter := bson.M{"size": bson.M{"$nin": []string{"", models.SizeSmall, models.SizeMedium, models.SizeLarge}}}
+	var pets []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Breed string             `bson:"breed"`
+		Size  string             `bson:"size"`
+	}
+
+	if err := FindMany(petCollection, filter, &pets); err != nil {
+		return 0, fmt.Errorf("failed to find pets to migrate: %v", err)
+	}
+
+	migrated := 0
+	for _, pet := range pets {
+		size := NormalizeSize(pet.Size)
+		if size == "" {
+			breed, err := FindBreedByName(pet.Breed)
+			if err != nil {
+				return migrated, err
+			}
+			if breed == nil {
+				continue
+			}
+			size = breed.SizeClass
+		}
+
+		if err := UpdateOne(petCollection, bson.M{"_id": pet.ID}, bson.M{"$set": bson.M{"size": size}}); err != nil {
+			return migrated, fmt.Errorf("failed to migrate pet %s: %v", pet.ID.Hex(), err)
+		}
+		migrated++
+	}
+
+	return migrated, nil
+}
+
+// EnsureBreedIndexes creates the indexes used for breed lookups
+func EnsureBreedIndexes() error {
+	collection := db.GetCollection(breedCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "nameEn", Value: 1}},
+			Options: options.Index().SetName("nameEn_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "nameZh", Value: 1}},
+			Options: options.Index().SetName("nameZh"),
+		},
+		{
+			Keys:    bson.D{{Key: "aliases", Value: 1}},
+			Options: options.Index().SetName("aliases"),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexModels)
+	if err != nil {
+		return fmt.Errorf("failed to create breed indexes: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"playtime-go/db"
+	"playtime-go/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	checkInCollection   = "checkins"
+	checkInRadius       = 200 // meters a reported position may be from the place
+	checkInCooldown     = time.Hour
+	recentVisitWindow   = 30 // days
+	verifiedVisitWindow = 90 * 24 * time.Hour
+)
+
+// CheckInAtPlace records a visit to a place. The reported position has to be within
+// checkInRadius of the place, and a user can check in at the same place once per cooldown.
+func CheckInAtPlace(placeID primitive.ObjectID, userID primitive.ObjectID, request models.CheckInRequest) (*models.CheckIn, error) {
+	place, err := GetLocationByID(placeID)
+	if err != nil {
+		return nil, err
+	}
+
+	distance := distanceMeters(request.Longitude, request.Latitude, place.Longitude, place.Latitude)
+	if distance > checkInRadius {
+		return nil, fmt.Errorf("too far from place to check in: %.0f meters away", distance)
+	}
+
+	for _, petID := range request.PetIDs {
+		if _, err := AuthorizePet(petID, userID, PetAccessCare); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	recent, err := Count(checkInCollection, bson.M{
+		"placeId":   placeID,
+		"userId":    userID,
+		"createdAt": bson.M{"$gt": now.Add(-checkInCooldown)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check recent check-ins: %v", err)
+	}
+	if recent > 0 {
+		return nil, fmt.Errorf("already checked in at place: %s", placeID.Hex())
+	}
+
+	petIDs := request.PetIDs
+	if petIDs == nil {
+		petIDs = []primitive.ObjectID{}
+	}
+
+	checkIn := models.CheckIn{
+		PlaceID:   placeID,
+		PlaceName: place.Name,
+		UserID:    userID,
+		PetIDs:    petIDs,
+		Distance:  math.Round(distance),
+		CreatedAt: now,
+	}
+
+	id, err := InsertOne(checkInCollection, checkIn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create check-in: %v", err)
+	}
+
+	checkIn.ID = id
+
+	// Share the check-in with the user's followers
+	go publishCheckInActivity(checkIn)
+
+	return &checkIn, nil
+}
+
+// ListUserVisits returns a user's check-ins, newest first. A limit of 0 returns all of them.
+func ListUserVisits(userID primitive.ObjectID, limit int64) ([]models.CheckIn, error) {
+	filter := bson.M{"userId": userID}
+	var checkIns []models.CheckIn
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	if limit > 0 {
+		findOptions.SetLimit(limit)
+	}
+
+	if err := FindMany(checkInCollection, filter, &checkIns, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list visits: %v", err)
+	}
+
+	return checkIns, nil
+}
+
+// GetPlaceVisitStats counts a place's visits, distinct visitors and the pets that visited recently
+func GetPlaceVisitStats(placeID primitive.ObjectID) (*models.PlaceVisitStats, error) {
+	if _, err := GetLocationByID(placeID); err != nil {
+		return nil, err
+	}
+
+	collection := db.GetCollection(checkInCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	visitCount, err := collection.CountDocuments(ctx, bson.M{"placeId": placeID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count visits: %v", err)
+	}
+
+	visitors, err := collection.Distinct(ctx, "userId", bson.M{"placeId": placeID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count visitors: %v", err)
+	}
+
+	since := time.Now().AddDate(0, 0, -recentVisitWindow)
+	recentPets, err := collection.Distinct(ctx, "petIds", bson.M{"placeId": placeID, "createdAt": bson.M{"$gt": since}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count recent pets: %v", err)
+	}
+
+	return &models.PlaceVisitStats{
+		PlaceID:          placeID,
+		VisitCount:       visitCount,
+		VisitorCount:     len(visitors),
+		RecentPetCount:   len(recentPets),
+		RecentWindowDays: recentVisitWindow,
+	}, nil
+}
+
+// hasRecentCheckIn reports whether the user checked in at the place within the verified visit window
+func hasRecentCheckIn(placeID primitive.ObjectID, userID primitive.ObjectID) (bool, error) {
+	count, err := Count(checkInCollection, bson.M{
+		"placeId":   placeID,
+		"userId":    userID,
+		"createdAt": bson.M{"$gt": time.Now().Add(-verifiedVisitWindow)},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check visits: %v", err)
+	}
+
+	return count > 0, nil
+}
+
+// EnsureCheckInIndexes creates the indexes for place and user visit lookups
+func EnsureCheckInIndexes() error {
+	collection := db.GetCollection(checkInCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "placeId", Value: 1}, {Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("placeId_userId_createdAt"),
+		},
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("userId_createdAt"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create check-in indexes: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"playtime-go/db"
+	"playtime-go/models"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	claimCollection = "place_claims"
+
+	maxClaimEvidence     = 10
+	claimEvidenceLinkTTL = time.Hour // lifetime of the presigned links to evidence documents
+)
+
+// CreatePlaceClaim opens a claim on a place after verifying the claimant's phone number
+func CreatePlaceClaim(userID primitive.ObjectID, request models.PlaceClaimRequest) (*models.PlaceClaim, error) {
+	location, err := GetLocationByID(request.PlaceID)
+	if err != nil {
+		return nil, err
+	}
+	if location.ManagerID != nil {
+		return nil, fmt.Errorf("invalid claim: place is already claimed")
+	}
+
+	pending, err := Count(claimCollection, bson.M{"placeId": request.PlaceID, "userId": userID, "status": models.ClaimPending})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing claims: %v", err)
+	}
+	if pending > 0 {
+		return nil, fmt.Errorf("invalid claim: you already have a pending claim for this place")
+	}
+
+	// Verify the phone number through the mini program's getPhoneNumber code
+	phone, err := GetPhoneNumber(request.PhoneCode)
+	if err != nil {
+		return nil, fmt.Errorf("phone verification failed: %v", err)
+	}
+
+	now := time.Now()
+	claim := models.PlaceClaim{
+		PlaceID:      request.PlaceID,
+		PlaceName:    location.Name,
+		UserID:       userID,
+		BusinessName: request.BusinessName,
+		ContactName:  request.ContactName,
+		PhoneNumber:  phone.PhoneInfo.PurePhoneNumber,
+		Evidence:     []string{},
+		Note:         request.Note,
+		Status:       models.ClaimPending,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	id, err := InsertOne(claimCollection, claim)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create claim: %v", err)
+	}
+
+	claim.ID = id
+	return &claim, nil
+}
+
+// GetPlaceClaim retrieves a claim, visible to the claimant and moderators
+func GetPlaceClaim(id primitive.ObjectID, userID primitive.ObjectID) (*models.PlaceClaim, error) {
+	claim, err := getPlaceClaim(id)
+	if err != nil {
+		return nil, err
+	}
+	if claim.UserID != userID && !IsModerator(userID) {
+		return nil, fmt.Errorf("not authorized to view claim: %s", id.Hex())
+	}
+
+	return withEvidenceURLs(claim)
+}
+
+// ListUserPlaceClaims lists the claims a user has made, newest first
+func ListUserPlaceClaims(userID primitive.ObjectID) ([]models.PlaceClaim, error) {
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	claims := []models.PlaceClaim{}
+	if err := FindMany(claimCollection, bson.M{"userId": userID}, &claims, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list claims: %v", err)
+	}
+
+	return withClaimsEvidenceURLs(claims)
+}
+
+// ListPlaceClaimsForReview lists claims in a status for moderators, oldest first so the queue is fair
+func ListPlaceClaimsForReview(moderatorID primitive.ObjectID, status string, limit int64) ([]models.PlaceClaim, error) {
+	if !IsModerator(moderatorID) {
+		return nil, fmt.Errorf("not authorized to review claims")
+	}
+	if status == "" {
+		status = models.ClaimPending
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	if limit > 0 {
+		findOptions.SetLimit(limit)
+	} else {
+		findOptions.SetLimit(100) // Default limit
+	}
+
+	claims := []models.PlaceClaim{}
+	if err := FindMany(claimCollection, bson.M{"status": status}, &claims, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list claims: %v", err)
+	}
+
+	return withClaimsEvidenceURLs(claims)
+}
+
+// AddPlaceClaimEvidence uploads an evidence document, such as a business license, to a pending claim.
+// Evidence is stored privately under its COS key and only handed out as presigned links.
+func AddPlaceClaimEvidence(id primitive.ObjectID, userID primitive.ObjectID, fileReader io.Reader, filename string, contentType string) (*models.PlaceClaim, error) {
+	claim, err := getPendingOwnClaim(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(claim.Evidence) >= maxClaimEvidence {
+		return nil, fmt.Errorf("invalid claim: at most %d evidence documents are allowed", maxClaimEvidence)
+	}
+
+	key := claimEvidenceKey(id, filename, contentType)
+	if err := PutPrivateObjectToCOS(key, fileReader, contentType); err != nil {
+		return nil, err
+	}
+
+	updateData := bson.M{
+		"$push": bson.M{"evidence": key},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
+	if err := UpdateOne(claimCollection, bson.M{"_id": id}, updateData); err != nil {
+		return nil, fmt.Errorf("failed to add evidence: %v", err)
+	}
+
+	return getPlaceClaimWithEvidenceURLs(id)
+}
+
+// WithdrawPlaceClaim lets the claimant withdraw a pending claim
+func WithdrawPlaceClaim(id primitive.ObjectID, userID primitive.ObjectID) (*models.PlaceClaim, error) {
+	if _, err := getPendingOwnClaim(id, userID); err != nil {
+		return nil, err
+	}
+
+	update := bson.M{"$set": bson.M{"status": models.ClaimWithdrawn, "updatedAt": time.Now()}}
+	if err := UpdateOne(claimCollection, bson.M{"_id": id, "status": models.ClaimPending}, update); err != nil {
+		return nil, fmt.Errorf("failed to withdraw claim: %v", err)
+	}
+
+	return getPlaceClaimWithEvidenceURLs(id)
+}
+
+// ApprovePlaceClaim makes the claimant the verified manager of the place and rejects competing claims
+func ApprovePlaceClaim(id primitive.ObjectID, moderatorID primitive.ObjectID, request models.PlaceClaimReviewRequest) (*models.PlaceClaim, error) {
+	claim, err := getPendingClaimForReview(id, moderatorID)
+	if err != nil {
+		return nil, err
+	}
+	if len(claim.Evidence) == 0 {
+		return nil, fmt.Errorf("invalid claim: no evidence has been uploaded")
+	}
+
+	location, err := GetLocationByID(claim.PlaceID)
+	if err != nil {
+		return nil, err
+	}
+	if location.ManagerID != nil {
+		return nil, fmt.Errorf("invalid claim: place is already claimed")
+	}
+
+	// Approve first so a claim withdrawn in the meantime does not hand over the place,
+	// and put the claim back if another manager got there first
+	if err := decidePlaceClaim(id, moderatorID, models.ClaimApproved, request.Note); err != nil {
+		return nil, err
+	}
+	if err := SetPlaceManager(claim.PlaceID, claim.UserID); err != nil {
+		reopen := bson.M{
+			"$set":   bson.M{"status": models.ClaimPending, "updatedAt": time.Now()},
+			"$unset": bson.M{"reviewerId": "", "reviewNote": "", "reviewedAt": ""},
+		}
+		if err := UpdateOne(claimCollection, bson.M{"_id": id, "status": models.ClaimApproved}, reopen); err != nil {
+			log.Printf("Failed to reopen claim %s: %v", id.Hex(), err)
+		}
+		if strings.Contains(err.Error(), "already has a manager") {
+			return nil, fmt.Errorf("invalid claim: place is already claimed")
+		}
+		return nil, err
+	}
+
+	// The place has a manager now, close the other claims on it
+	now := time.Now()
+	filter := bson.M{"placeId": claim.PlaceID, "status": models.ClaimPending, "_id": bson.M{"$ne": id}}
+	update := bson.M{"$set": bson.M{
+		"status":     models.ClaimRejected,
+		"reviewerId": moderatorID,
+		"reviewNote": "another claim on this place was approved",
+		"reviewedAt": now,
+		"updatedAt":  now,
+	}}
+	if _, err := UpdateMany(claimCollection, filter, update); err != nil {
+		log.Printf("Failed to reject competing claims on place %s: %v", claim.PlaceID.Hex(), err)
+	}
+
+	return getPlaceClaimWithEvidenceURLs(id)
+}
+
+// RejectPlaceClaim rejects a pending claim with the moderator's reason
+func RejectPlaceClaim(id primitive.ObjectID, moderatorID primitive.ObjectID, request models.PlaceClaimReviewRequest) (*models.PlaceClaim, error) {
+	if _, err := getPendingClaimForReview(id, moderatorID); err != nil {
+		return nil, err
+	}
+
+	if err := decidePlaceClaim(id, moderatorID, models.ClaimRejected, request.Note); err != nil {
+		return nil, err
+	}
+
+	return getPlaceClaimWithEvidenceURLs(id)
+}
+
+// decidePlaceClaim records a moderator's decision on a claim, failing if it is no longer pending
+func decidePlaceClaim(id primitive.ObjectID, moderatorID primitive.ObjectID, status string, note string) error {
+	collection := db.GetCollection(claimCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	now := time.Now()
+	update := bson.M{"$set": bson.M{
+		"status":     status,
+		"reviewerId": moderatorID,
+		"reviewNote": note,
+		"reviewedAt": now,
+		"updatedAt":  now,
+	}}
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": id, "status": models.ClaimPending}, update)
+	if err != nil {
+		return fmt.Errorf("failed to review claim: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("invalid claim: claim is no longer pending")
+	}
+	return nil
+}
+
+// getPendingOwnClaim returns a claim the user made that is still pending
+func getPendingOwnClaim(id primitive.ObjectID, userID primitive.ObjectID) (*models.PlaceClaim, error) {
+	claim, err := getPlaceClaim(id)
+	if err != nil {
+		return nil, err
+	}
+	if claim.UserID != userID {
+		return nil, fmt.Errorf("not authorized to modify claim: %s", id.Hex())
+	}
+	if claim.Status != models.ClaimPending {
+		return nil, fmt.Errorf("invalid claim: claim is already %s", claim.Status)
+	}
+
+	return claim, nil
+}
+
+// getPendingClaimForReview returns a pending claim if the user is a moderator
+func getPendingClaimForReview(id primitive.ObjectID, moderatorID primitive.ObjectID) (*models.PlaceClaim, error) {
+	if !IsModerator(moderatorID) {
+		return nil, fmt.Errorf("not authorized to review claims")
+	}
+
+	claim, err := getPlaceClaim(id)
+	if err != nil {
+		return nil, err
+	}
+	if claim.Status != models.ClaimPending {
+		return nil, fmt.Errorf("invalid claim: claim is already %s", claim.Status)
+	}
+
+	return claim, nil
+}
+
+// getPlaceClaim retrieves a claim by ID
+func getPlaceClaim(id primitive.ObjectID) (*models.PlaceClaim, error) {
+	var claim models.PlaceClaim
+	if err := FindOne(claimCollection, bson.M{"_id": id}, &claim); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no claim found with ID: %s", id.Hex())
+		}
+		return nil, fmt.Errorf("failed to get claim: %v", err)
+	}
+
+	return &claim, nil
+}
+
+// getPlaceClaimWithEvidenceURLs retrieves a claim by ID with presigned links to its evidence
+func getPlaceClaimWithEvidenceURLs(id primitive.ObjectID) (*models.PlaceClaim, error) {
+	claim, err := getPlaceClaim(id)
+	if err != nil {
+		return nil, err
+	}
+	return withEvidenceURLs(claim)
+}
+
+// withEvidenceURLs replaces the evidence keys of a claim with presigned links
+func withEvidenceURLs(claim *models.PlaceClaim) (*models.PlaceClaim, error) {
+	for i, evidence := range claim.Evidence {
+		// Evidence uploaded before it was stored privately is still referenced by its URL
+		key := evidence
+		if strings.HasPrefix(evidence, "https://") {
+			if urlKey, err := cosKeyFromURL(evidence); err == nil {
+				key = urlKey
+			}
+		}
+
+		link, err := GetPresignedCOSURL(key, claimEvidenceLinkTTL)
+		if err != nil {
+			return nil, err
+		}
+		claim.Evidence[i] = link
+	}
+	return claim, nil
+}
+
+// withClaimsEvidenceURLs replaces the evidence keys of every claim with presigned links
+func withClaimsEvidenceURLs(claims []models.PlaceClaim) ([]models.PlaceClaim, error) {
+	for i := range claims {
+		if _, err := withEvidenceURLs(&claims[i]); err != nil {
+			return nil, err
+		}
+	}
+	return claims, nil
+}
+
+// claimEvidenceKey returns a new COS key for an evidence document of a claim
+func claimEvidenceKey(id primitive.ObjectID, filename string, contentType string) string {
+	fileExt := filepath.Ext(filename)
+	if fileExt == "" {
+		switch {
+		case strings.HasPrefix(contentType, "image/jpeg"):
+			fileExt = ".jpg"
+		case strings.HasPrefix(contentType, "image/png"):
+			fileExt = ".png"
+		case contentType == "application/pdf":
+			fileExt = ".pdf"
+		default:
+			fileExt = ".bin"
+		}
+	}
+	return fmt.Sprintf("claim/%s/evidence/%d%s", id.Hex(), time.Now().UnixNano(), fileExt)
+}
+
+// MigrateClaimEvidence makes evidence uploaded as public files private and stores it by COS key
+func MigrateClaimEvidence() (int, error) {
+	var claims []models.PlaceClaim
+	if err := FindMany(claimCollection, bson.M{"evidence": bson.M{"$regex": "^https://"}}, &claims); err != nil {
+		return 0, fmt.Errorf("failed to find claims with public evidence: %v", err)
+	}
+
+	migrated := 0
+	for _, claim := range claims {
+		keys := make([]string, 0, len(claim.Evidence))
+		for _, evidence := range claim.Evidence {
+			key := evidence
+			if strings.HasPrefix(evidence, "https://") {
+				urlKey, err := cosKeyFromURL(evidence)
+				if err != nil {
+					return migrated, err
+				}
+				if err := SetCOSObjectACL(urlKey, "private"); err != nil {
+					return migrated, err
+				}
+				key = urlKey
+			}
+			keys = append(keys, key)
+		}
+
+		if err := UpdateOne(claimCollection, bson.M{"_id": claim.ID}, bson.M{"$set": bson.M{"evidence": keys}}); err != nil {
+			return migrated, fmt.Errorf("failed to migrate claim evidence: %v", err)
+		}
+		migrated++
+	}
+
+	return migrated, nil
+}
+
+// EnsurePlaceClaimIndexes creates the indexes for claimant and moderation queue lookups
+func EnsurePlaceClaimIndexes() error {
+	collection := db.GetCollection(claimCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("userId_createdAt"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: 1}},
+			Options: options.Index().SetName("status_createdAt"),
+		},
+		{
+			Keys:    bson.D{{Key: "placeId", Value: 1}, {Key: "status", Value: 1}},
+			Options: options.Index().SetName("placeId_status"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create place claim indexes: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,454 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"playtime-go/config"
+	"playtime-go/db"
+	"playtime-go/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	mediaCheckCollection = "media_checks"
+
+	// WeChat security API scenes
+	wechatSceneProfile = 1
+	wechatSceneComment = 2
+)
+
+// ContentChecker checks user content before it goes live.
+// Text is checked synchronously, media checks are submitted and their result arrives later.
+type ContentChecker interface {
+	CheckText(text string, openID string) (models.ContentCheckResult, error)
+	SubmitMediaCheck(mediaURL string, openID string) (string, error)
+}
+
+// contentChecker returns the configured content check backend, the keyword backend when WeChat is not set up
+func contentChecker() ContentChecker {
+	cfg := config.GetConfig()
+	if cfg.ContentCheckBackend == "keyword" || cfg.AppID == "" {
+		return keywordChecker{}
+	}
+	return wechatChecker{}
+}
+
+// keywordChecker flags text containing a word from the local blocklist. It cannot check media.
+type keywordChecker struct{}
+
+var (
+	blocklist     []string
+	blocklistOnce sync.Once
+)
+
+// CheckText looks for blocklisted keywords, ignoring case and whitespace used to split them up
+func (keywordChecker) CheckText(text string, openID string) (models.ContentCheckResult, error) {
+	blocklistOnce.Do(loadBlocklist)
+
+	normalized := normalizeCheckText(text)
+	for _, keyword := range blocklist {
+		if strings.Contains(normalized, keyword) {
+			return models.ContentCheckResult{Suggest: models.ContentSuggestReview, Keyword: keyword}, nil
+		}
+	}
+
+	return models.ContentCheckResult{Suggest: models.ContentSuggestPass}, nil
+}
+
+// SubmitMediaCheck does nothing, offline media is left to moderators and abuse reports
+func (keywordChecker) SubmitMediaCheck(mediaURL string, openID string) (string, error) {
+	return "", nil
+}
+
+// loadBlocklist reads the blocklist file, one keyword per line, lines starting with # are comments
+func loadBlocklist() {
+	path := config.GetConfig().ContentBlocklistFile
+	if path == "" {
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Printf("Failed to open content blocklist %s: %v", path, err)
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		blocklist = append(blocklist, normalizeCheckText(line))
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("Failed to read content blocklist %s: %v", path, err)
+	}
+
+	log.Printf("Loaded %d blocklist keywords", len(blocklist))
+}
+
+// normalizeCheckText lowercases text and drops whitespace so spaced out keywords still match
+func normalizeCheckText(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, text)
+}
+
+// wechatChecker uses the WeChat msg_sec_check and media_check_async APIs
+type wechatChecker struct{}
+
+// CheckText checks text with msg_sec_check, which needs the openid of a user active in the last two hours
+func (wechatChecker) CheckText(text string, openID string) (models.ContentCheckResult, error) {
+	request := models.WechatMsgSecCheckRequest{
+		Content: text,
+		Version: 2,
+		Scene:   wechatSceneComment,
+		OpenID:  openID,
+	}
+
+	response, err := postWechatSecCheck("msg_sec_check", request)
+	if err != nil {
+		return models.ContentCheckResult{}, err
+	}
+
+	return models.ContentCheckResult{Suggest: response.Result.Suggest, Label: response.Result.Label}, nil
+}
+
+// SubmitMediaCheck submits an image to media_check_async and returns the trace ID of the check
+func (wechatChecker) SubmitMediaCheck(mediaURL string, openID string) (string, error) {
+	request := models.WechatMediaCheckRequest{
+		MediaURL:  mediaURL,
+		MediaType: 2, // image
+		Version:   2,
+		Scene:     wechatSceneProfile,
+		OpenID:    openID,
+	}
+
+	response, err := postWechatSecCheck("media_check_async", request)
+	if err != nil {
+		return "", err
+	}
+
+	return response.TraceID, nil
+}
+
+// postWechatSecCheck calls a WeChat security API with the shared access token
+func postWechatSecCheck(api string, request interface{}) (*models.WechatSecCheckResponse, error) {
+	token, err := GetToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %v", err)
+	}
+
+	jsonBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %v", err)
+	}
+
+	url := fmt.Sprintf("https://api.weixin.qq.com/wxa/%s?access_token=%s", api, token.AccessToken)
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Post(url, "application/json", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %v", api, err)
+	}
+	defer resp.Body.Close()
+
+	var response models.WechatSecCheckResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %v", api, err)
+	}
+	if response.ErrCode != 0 {
+		return nil, fmt.Errorf("WeChat API error: %d - %s", response.ErrCode, response.ErrMsg)
+	}
+
+	return &response, nil
+}
+
+// checkUserText checks the text a user wrote and returns the moderation status the content should get.
+// The local blocklist always applies, anything flagged or left unchecked by an error is held for a moderator.
+func checkUserText(userID primitive.ObjectID, texts ...string) string {
+	text := strings.TrimSpace(strings.Join(texts, "\n"))
+	if text == "" {
+		return models.ModerationApproved
+	}
+
+	result, _ := keywordChecker{}.CheckText(text, "")
+	if result.Suggest != models.ContentSuggestPass {
+		log.Printf("Holding content of user %s, matched blocklist keyword %q", userID.Hex(), result.Keyword)
+		return models.ModerationPending
+	}
+
+	checker := contentChecker()
+	if _, ok := checker.(keywordChecker); ok {
+		return models.ModerationApproved
+	}
+
+	openID := ""
+	if user, err := GetUserByID(userID); err == nil {
+		openID = user.OpenID
+	}
+
+	result, err := checker.CheckText(text, openID)
+	if err != nil {
+		log.Printf("Holding content of user %s, text check failed: %v", userID.Hex(), err)
+		return models.ModerationPending
+	}
+	if result.Suggest != models.ContentSuggestPass {
+		log.Printf("Holding content of user %s, flagged %s with label %d", userID.Hex(), result.Suggest, result.Label)
+		return models.ModerationPending
+	}
+
+	return models.ModerationApproved
+}
+
+// SubmitUploadCheck sends an uploaded image for an asynchronous check, the result arrives at HandleMediaCheckEvent
+func SubmitUploadCheck(userID primitive.ObjectID, upload *UploadResponse) error {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	traceID, err := contentChecker().SubmitMediaCheck(upload.URL, user.OpenID)
+	if err != nil {
+		return fmt.Errorf("failed to submit media check: %v", err)
+	}
+	if traceID == "" {
+		return nil
+	}
+
+	now := time.Now()
+	check := models.MediaCheck{
+		TraceID:   traceID,
+		URL:       upload.URL,
+		Key:       upload.Filename,
+		UserID:    userID,
+		Status:    models.ModerationPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := InsertOne(mediaCheckCollection, check); err != nil {
+		return fmt.Errorf("failed to record media check: %v", err)
+	}
+
+	return nil
+}
+
+// HandleMediaCheckEvent applies the result of an asynchronous media check.
+// A risky image is deleted from storage and removed from wherever it was used.
+func HandleMediaCheckEvent(event models.WechatMediaCheckEvent) error {
+	var check models.MediaCheck
+	if err := FindOne(mediaCheckCollection, bson.M{"traceId": event.TraceID}, &check); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return fmt.Errorf("no media check found with trace ID: %s", event.TraceID)
+		}
+		return fmt.Errorf("failed to get media check: %v", err)
+	}
+
+	status := models.ModerationApproved
+	if event.ErrCode != 0 || event.Result.Suggest == models.ContentSuggestReview {
+		status = models.ModerationPending
+	}
+	if event.Result.Suggest == models.ContentSuggestRisky {
+		status = models.ModerationRejected
+	}
+
+	update := bson.M{"$set": bson.M{
+		"status":    status,
+		"suggest":   event.Result.Suggest,
+		"label":     event.Result.Label,
+		"updatedAt": time.Now(),
+	}}
+	if err := UpdateOne(mediaCheckCollection, bson.M{"_id": check.ID}, update); err != nil {
+		return fmt.Errorf("failed to update media check: %v", err)
+	}
+
+	switch status {
+	case models.ModerationRejected:
+		removeRejectedMedia(check)
+	case models.ModerationPending:
+		holdMedia(check)
+	}
+
+	return nil
+}
+
+// holdMedia takes an image the check could not clear out of public view until a moderator decides.
+// The image and its review thumbnail are made private, so every page that shows them stops loading them.
+func holdMedia(check models.MediaCheck) {
+	log.Printf("Holding image %s uploaded by user %s for moderation", check.Key, check.UserID.Hex())
+
+	for _, key := range mediaObjectKeys(check) {
+		if err := SetCOSObjectACL(key, "private"); err != nil {
+			log.Printf("Failed to hide held image %s: %v", key, err)
+		}
+	}
+}
+
+// releaseMedia makes an approved image public again and creates the review thumbnails skipped while it was held
+func releaseMedia(check models.MediaCheck) {
+	for _, key := range mediaObjectKeys(check) {
+		if err := SetCOSObjectACL(key, "default"); err != nil {
+			log.Printf("Failed to release approved image %s: %v", key, err)
+		}
+	}
+
+	var reviews []models.Review
+	if err := FindMany(reviewCollection, bson.M{"photos.url": check.URL}, &reviews); err != nil {
+		log.Printf("Failed to find reviews using approved image %s: %v", check.Key, err)
+		return
+	}
+	for _, review := range reviews {
+		generateReviewThumbnails(review.ID, review.Photos)
+	}
+}
+
+// mediaObjectKeys returns the COS keys of a checked image and of the thumbnail made from it
+func mediaObjectKeys(check models.MediaCheck) []string {
+	keys := []string{check.Key}
+	if isReviewUploadKey(check.Key) {
+		keys = append(keys, reviewThumbnailKey(check.Key))
+	}
+	return keys
+}
+
+// isMediaHeld reports whether an image is waiting for a moderator after its check
+func isMediaHeld(mediaURL string) bool {
+	count, err := Count(mediaCheckCollection, bson.M{"url": mediaURL, "status": models.ModerationPending})
+	return err == nil && count > 0
+}
+
+// listHeldMedia lists images held for moderation, oldest first
+func listHeldMedia(limit int64) ([]models.MediaCheck, error) {
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "_id", Value: 1}})
+	if limit > 0 {
+		findOptions.SetLimit(limit)
+	} else {
+		findOptions.SetLimit(100) // Default limit
+	}
+
+	checks := []models.MediaCheck{}
+	if err := FindMany(mediaCheckCollection, bson.M{"status": models.ModerationPending}, &checks, findOptions); err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+// moderateHeldMedia approves or rejects an image held for moderation.
+// An approved image is made public again, a rejected one is removed like a risky image.
+func moderateHeldMedia(moderatorID primitive.ObjectID, id primitive.ObjectID, approve bool) error {
+	filter := bson.M{"_id": id, "status": models.ModerationPending}
+	var check models.MediaCheck
+	if err := FindOne(mediaCheckCollection, filter, &check); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return fmt.Errorf("no held content found with ID: %s", id.Hex())
+		}
+		return fmt.Errorf("failed to find held content: %v", err)
+	}
+
+	status := models.ModerationRejected
+	action := models.ModerationActionReject
+	if approve {
+		status = models.ModerationApproved
+		action = models.ModerationActionApprove
+	}
+
+	update := bson.M{"$set": bson.M{"status": status, "updatedAt": time.Now()}}
+	if err := UpdateOne(mediaCheckCollection, filter, update); err != nil {
+		return fmt.Errorf("failed to moderate content: %v", err)
+	}
+
+	if approve {
+		go releaseMedia(check)
+	} else {
+		go removeRejectedMedia(check)
+	}
+
+	recordModerationAction(&moderatorID, action, models.ContentTypeMedia, id, "", 0)
+	go notifyModerationResult(check.UserID, models.ContentTypeMedia, id, action)
+
+	return nil
+}
+
+// removeRejectedMedia deletes a rejected image and detaches it from reviews, pets and users
+func removeRejectedMedia(check models.MediaCheck) {
+	log.Printf("Removing rejected image %s uploaded by user %s", check.Key, check.UserID.Hex())
+
+	if err := DeleteObjectFromCOS(check.Key); err != nil {
+		log.Printf("Failed to delete rejected image %s: %v", check.Key, err)
+	}
+	if err := deleteUploadRecords(check.Key); err != nil {
+		log.Printf("Failed to delete upload record of rejected image %s: %v", check.Key, err)
+	}
+
+	if _, err := UpdateMany(reviewCollection, bson.M{"photos.url": check.URL}, bson.M{"$pull": bson.M{"photos": bson.M{"url": check.URL}}}); err != nil {
+		log.Printf("Failed to remove rejected image from reviews: %v", err)
+	}
+	if _, err := UpdateMany(petCollection, bson.M{"avatar": check.URL}, bson.M{"$set": bson.M{"avatar": ""}}); err != nil {
+		log.Printf("Failed to remove rejected image from pets: %v", err)
+	}
+	if _, err := UpdateMany(petCollection, bson.M{"coverPhoto": check.URL}, bson.M{"$unset": bson.M{"coverPhoto": ""}}); err != nil {
+		log.Printf("Failed to remove rejected cover photo from pets: %v", err)
+	}
+	if _, err := DeleteMany(photoCollection, bson.M{"url": check.URL}); err != nil {
+		log.Printf("Failed to remove rejected image from pet galleries: %v", err)
+	}
+	if _, err := UpdateMany(userCollection, bson.M{"avatarUrl": check.URL}, bson.M{"$set": bson.M{"avatarUrl": ""}}); err != nil {
+		log.Printf("Failed to remove rejected image from users: %v", err)
+	}
+}
+
+// VerifyWechatPushSignature checks the signature WeChat sends with message push requests
+func VerifyWechatPushSignature(signature string, timestamp string, nonce string) bool {
+	token := config.GetConfig().WechatPushToken
+	if token == "" {
+		return false
+	}
+	return wechatPushSignature(token, timestamp, nonce) == signature
+}
+
+// wechatPushSignature is the SHA1 of the token, timestamp and nonce sorted and joined
+func wechatPushSignature(token string, timestamp string, nonce string) string {
+	parts := []string{token, timestamp, nonce}
+	sort.Strings(parts)
+	sum := sha1.Sum([]byte(strings.Join(parts, "")))
+	return hex.EncodeToString(sum[:])
+}
+
+// EnsureMediaCheckIndexes creates the index used to look up media checks by trace ID
+func EnsureMediaCheckIndexes() error {
+	collection := db.GetCollection(mediaCheckCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "traceId", Value: 1}},
+		Options: options.Index().SetName("traceId_unique").SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create media check indexes: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"playtime-go/config"
+	"playtime-go/models"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// pushEventBuffer is the number of undelivered events kept per subscriber, a slow subscriber misses newer ones
+const pushEventBuffer = 32
+
+// EventBroker carries push events between the services that publish them and the connections that
+// deliver them. Publish hands a payload to the subscribers of a topic on every server instance and
+// Subscribe calls deliver for each payload until the returned function cancels the subscription.
+// deliver must not block. Deployments with several instances register a shared broker (Redis, NATS...)
+// with RegisterEventBroker and select it with EVENT_BROKER.
+type EventBroker interface {
+	Publish(topic string, payload []byte) error
+	Subscribe(topic string, deliver func(payload []byte)) (func(), error)
+}
+
+var (
+	eventBrokersMu sync.Mutex
+	eventBrokers   = map[string]EventBroker{"memory": newMemoryBroker()}
+	activeBroker   EventBroker
+)
+
+// RegisterEventBroker makes a broker available under a name for the EVENT_BROKER setting.
+// It must be called before InitEventBroker.
+func RegisterEventBroker(name string, broker EventBroker) {
+	eventBrokersMu.Lock()
+	defer eventBrokersMu.Unlock()
+	eventBrokers[name] = broker
+}
+
+// InitEventBroker selects the broker named by EVENT_BROKER, it fails when no broker has that name
+func InitEventBroker() error {
+	eventBrokersMu.Lock()
+	defer eventBrokersMu.Unlock()
+
+	name := config.GetConfig().EventBroker
+	broker, ok := eventBrokers[name]
+	if !ok {
+		return fmt.Errorf("unknown event broker %q", name)
+	}
+	activeBroker = broker
+	return nil
+}
+
+// eventBroker returns the broker selected by InitEventBroker, the in-process one before it ran
+func eventBroker() EventBroker {
+	eventBrokersMu.Lock()
+	defer eventBrokersMu.Unlock()
+
+	if activeBroker == nil {
+		return eventBrokers["memory"]
+	}
+	return activeBroker
+}
+
+// PublishUserEvent pushes an event to all of the user's open connections. Delivery is best effort.
+func PublishUserEvent(userID primitive.ObjectID, eventType string, data interface{}) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", eventType, err)
+		return
+	}
+	payload, err := json.Marshal(models.PushEvent{Type: eventType, Data: encoded, CreatedAt: time.Now()})
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", eventType, err)
+		return
+	}
+
+	if err := eventBroker().Publish(userEventTopic(userID), payload); err != nil {
+		log.Printf("Failed to publish %s event for user %s: %v", eventType, userID.Hex(), err)
+	}
+}
+
+// SubscribeUserEvents subscribes to the user's push events, only the given types when any are given.
+// The returned function must be called to unsubscribe once the subscriber is done.
+func SubscribeUserEvents(userID primitive.ObjectID, types ...string) (<-chan models.PushEvent, func(), error) {
+	events := make(chan models.PushEvent, pushEventBuffer)
+	var (
+		mu     sync.Mutex
+		closed bool
+	)
+
+	deliver := func(payload []byte) {
+		var event models.PushEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			log.Printf("Failed to decode push event for user %s: %v", userID.Hex(), err)
+			return
+		}
+		if len(types) > 0 && !containsString(types, event.Type) {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		select {
+		case events <- event:
+		default:
+			log.Printf("Dropping %s event for user %s, subscriber is not keeping up", event.Type, userID.Hex())
+		}
+	}
+
+	cancel, err := eventBroker().Subscribe(userEventTopic(userID), deliver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return events, func() {
+		cancel()
+		mu.Lock()
+		defer mu.Unlock()
+		if !closed {
+			closed = true
+			close(events)
+		}
+	}, nil
+}
+
+// userEventTopic is the broker topic carrying a user's events
+func userEventTopic(userID primitive.ObjectID) string {
+	return "user." + userID.Hex()
+}
+
+// containsString reports whether the list contains the value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// memoryBroker delivers events to subscribers on this instance only
+type memoryBroker struct {
+	mu          sync.Mutex
+	nextID      int
+	subscribers map[string]map[int]func(payload []byte)
+}
+
+func newMemoryBroker() *memoryBroker {
+	return &memoryBroker{subscribers: map[string]map[int]func(payload []byte){}}
+}
+
+func (b *memoryBroker) Publish(topic string, payload []byte) error {
+	b.mu.Lock()
+	delivers := make([]func(payload []byte), 0, len(b.subscribers[topic]))
+	for _, deliver := range b.subscribers[topic] {
+		delivers = append(delivers, deliver)
+	}
+	b.mu.Unlock()
+
+	for _, deliver := range delivers {
+		deliver(payload)
+	}
+	return nil
+}
+
+func (b *memoryBroker) Subscribe(topic string, deliver func(payload []byte)) (func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = map[int]func(payload []byte){}
+	}
+	b.subscribers[topic][id] = deliver
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[topic], id)
+		if len(b.subscribers[topic]) == 0 {
+			delete(b.subscribers, topic)
+		}
+	}, nil
+}
@@ -0,0 +1,513 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"playtime-go/db"
+	"playtime-go/models"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	eventCollection   = "events"
+	rsvpCollection    = "event_rsvps"
+	eventSearchRadius = 5000 // meters
+)
+
+// eventWithDistance decodes a $geoNear result over events
+type eventWithDistance struct {
+	models.Event `bson:",inline"`
+	Distance     float64 `bson:"distance"`
+}
+
+// CreateEvent creates an event hosted by the user at an existing place
+func CreateEvent(hostID primitive.ObjectID, request models.EventRequest) (*models.Event, error) {
+	if err := ensureUserNotBanned(hostID); err != nil {
+		return nil, err
+	}
+
+	place, err := GetLocationByID(request.LocationID)
+	if err != nil {
+		return nil, err
+	}
+
+	petSizes := request.PetSizes
+	if petSizes == nil {
+		petSizes = []string{}
+	}
+	petTypes := request.PetTypes
+	if petTypes == nil {
+		petTypes = []string{}
+	}
+
+	now := time.Now()
+	event := models.Event{
+		LocationID: place.ID,
+		PlaceName:  place.Name,
+		Location: models.GeoLocation{
+			Type:        "Point",
+			Coordinates: []float64{place.Longitude, place.Latitude},
+		},
+		HostID:      hostID,
+		Title:       request.Title,
+		Description: request.Description,
+		StartTime:   request.StartTime,
+		EndTime:     request.EndTime,
+		Capacity:    request.Capacity,
+		PetSizes:    petSizes,
+		PetTypes:    petTypes,
+		Status:      models.EventScheduled,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	id, err := InsertOne(eventCollection, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event: %v", err)
+	}
+
+	event.ID = id
+	return &event, nil
+}
+
+// GetEventByID retrieves an event by its ID
+func GetEventByID(id primitive.ObjectID) (*models.Event, error) {
+	filter := bson.M{"_id": id}
+	var event models.Event
+
+	if err := FindOne(eventCollection, filter, &event); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no event found with ID: %s", id.Hex())
+		}
+		return nil, fmt.Errorf("failed to get event by ID: %v", err)
+	}
+
+	return &event, nil
+}
+
+// ListEventsByLocation lists the upcoming events at a place, soonest first
+func ListEventsByLocation(locationID primitive.ObjectID) ([]models.Event, error) {
+	filter := bson.M{
+		"locationId": locationID,
+		"status":     models.EventScheduled,
+		"endTime":    bson.M{"$gt": time.Now()},
+	}
+	var events []models.Event
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "startTime", Value: 1}})
+	findOptions.SetLimit(100)
+
+	if err := FindMany(eventCollection, filter, &events, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list events: %v", err)
+	}
+
+	return events, nil
+}
+
+// SearchNearbyEvents searches for upcoming events near the specified coordinates
+func SearchNearbyEvents(search models.EventSearchRequest) ([]models.EventSearchResult, error) {
+	collection := db.GetCollection(eventCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	radius := search.Radius
+	if radius <= 0 {
+		radius = eventSearchRadius
+	}
+
+	limit := search.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	// An empty list of allowed sizes or types means every pet is welcome
+	query := bson.M{
+		"status":  models.EventScheduled,
+		"endTime": bson.M{"$gt": time.Now()},
+	}
+	var and []bson.M
+	if search.PetSize != "" {
+		and = append(and, bson.M{"$or": []bson.M{{"petSizes": search.PetSize}, {"petSizes": bson.M{"$size": 0}}}})
+	}
+	if search.PetType != "" {
+		and = append(and, bson.M{"$or": []bson.M{{"petTypes": search.PetType}, {"petTypes": bson.M{"$size": 0}}}})
+	}
+	if len(and) > 0 {
+		query["$and"] = and
+	}
+
+	pipeline := []bson.D{
+		{{Key: "$geoNear", Value: bson.D{
+			{Key: "near", Value: bson.D{
+				{Key: "type", Value: "Point"},
+				{Key: "coordinates", Value: []float64{search.Longitude, search.Latitude}},
+			}},
+			{Key: "distanceField", Value: "distance"},
+			{Key: "maxDistance", Value: radius},
+			{Key: "query", Value: query},
+			{Key: "spherical", Value: true},
+		}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute nearby event search: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var events []eventWithDistance
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, fmt.Errorf("failed to decode events: %v", err)
+	}
+
+	results := make([]models.EventSearchResult, 0, len(events))
+	for _, event := range events {
+		results = append(results, models.EventSearchResult{Event: event.Event, Distance: event.Distance})
+	}
+
+	return results, nil
+}
+
+// CancelEvent cancels an event on behalf of its host and notifies everyone who RSVPed
+func CancelEvent(id primitive.ObjectID, userID primitive.ObjectID, request models.EventCancelRequest) (*models.Event, error) {
+	event, err := GetEventByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if event.HostID != userID {
+		return nil, fmt.Errorf("not authorized to cancel event: %s", id.Hex())
+	}
+	if event.Status == models.EventCancelled {
+		return nil, fmt.Errorf("event is already cancelled: %s", id.Hex())
+	}
+
+	now := time.Now()
+	update := bson.M{"$set": bson.M{
+		"status":       models.EventCancelled,
+		"cancelReason": request.Reason,
+		"updatedAt":    now,
+	}}
+	if err := UpdateOne(eventCollection, bson.M{"_id": id}, update); err != nil {
+		return nil, fmt.Errorf("failed to cancel event: %v", err)
+	}
+
+	event.Status = models.EventCancelled
+	event.CancelReason = request.Reason
+	event.UpdatedAt = now
+
+	// Notify attendees after the response is sent
+	go func() {
+		var rsvps []models.EventRSVP
+		filter := bson.M{"eventId": id, "status": bson.M{"$in": []string{models.RSVPGoing, models.RSVPWaitlisted}}}
+		if err := FindMany(rsvpCollection, filter, &rsvps); err != nil {
+			log.Printf("Failed to list RSVPs of cancelled event %s: %v", id.Hex(), err)
+			return
+		}
+		for _, rsvp := range rsvps {
+			if rsvp.UserID == event.HostID {
+				continue
+			}
+			if _, err := sendEventMessage(rsvp.UserID, *event, "Event cancelled"); err != nil {
+				log.Printf("Failed to notify user %s of cancelled event %s: %v", rsvp.UserID.Hex(), id.Hex(), err)
+			}
+		}
+	}()
+
+	return event, nil
+}
+
+// RSVPToEvent registers the user's pets for an event. When there is not enough room left
+// the RSVP joins the waitlist. Replying again replaces the previous RSVP.
+func RSVPToEvent(eventID primitive.ObjectID, userID primitive.ObjectID, request models.EventRSVPRequest) (*models.EventRSVP, error) {
+	event, err := GetEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != models.EventScheduled || !event.EndTime.After(time.Now()) {
+		return nil, fmt.Errorf("event is not open for RSVP: %s", eventID.Hex())
+	}
+	if err := ensureNotBlockedBy(event.HostID, userID); err != nil {
+		return nil, err
+	}
+
+	for _, petID := range request.PetIDs {
+		pet, err := AuthorizePet(petID, userID, PetAccessCare)
+		if err != nil {
+			return nil, err
+		}
+		if !eventAllowsPet(event, pet) {
+			return nil, fmt.Errorf("invalid RSVP: pet %s does not meet the event's size or type restrictions", pet.Name)
+		}
+	}
+
+	// Release the spots of any previous RSVP before taking new ones
+	if _, err := withdrawEventRSVP(eventID, userID); err != nil && !isNoRSVPError(err) {
+		return nil, err
+	}
+
+	now := time.Now()
+	rsvp := models.EventRSVP{
+		EventID:   eventID,
+		UserID:    userID,
+		PetIDs:    request.PetIDs,
+		Status:    models.RSVPWaitlisted,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// Only join directly when nobody is already waiting, so the waitlist stays first come first served
+	if event.WaitlistCount == 0 {
+		reserved, err := reserveEventSpots(eventID, len(request.PetIDs))
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			rsvp.Status = models.RSVPGoing
+		}
+	}
+
+	if rsvp.Status == models.RSVPWaitlisted {
+		if err := UpdateOne(eventCollection, bson.M{"_id": eventID}, bson.M{"$inc": bson.M{"waitlistCount": 1}}); err != nil {
+			return nil, fmt.Errorf("failed to join waitlist: %v", err)
+		}
+	}
+
+	id, err := InsertOne(rsvpCollection, rsvp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create RSVP: %v", err)
+	}
+
+	rsvp.ID = id
+	if event.HostID != userID {
+		notifyRSVPChange(*event, rsvp, event.HostID)
+	}
+	return &rsvp, nil
+}
+
+// CancelEventRSVP withdraws the user's RSVP and offers any freed spots to the waitlist
+func CancelEventRSVP(eventID primitive.ObjectID, userID primitive.ObjectID) (*models.EventRSVP, error) {
+	rsvp, err := withdrawEventRSVP(eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if event, err := GetEventByID(eventID); err == nil && event.HostID != userID {
+		notifyRSVPChange(*event, *rsvp, event.HostID)
+	}
+	return rsvp, nil
+}
+
+// withdrawEventRSVP cancels the user's active RSVP without telling the host
+func withdrawEventRSVP(eventID primitive.ObjectID, userID primitive.ObjectID) (*models.EventRSVP, error) {
+	filter := bson.M{
+		"eventId": eventID,
+		"userId":  userID,
+		"status":  bson.M{"$in": []string{models.RSVPGoing, models.RSVPWaitlisted}},
+	}
+	var rsvp models.EventRSVP
+
+	if err := FindOne(rsvpCollection, filter, &rsvp); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no RSVP found for event: %s", eventID.Hex())
+		}
+		return nil, fmt.Errorf("failed to get RSVP: %v", err)
+	}
+
+	now := time.Now()
+	update := bson.M{"$set": bson.M{"status": models.RSVPCancelled, "updatedAt": now}}
+	if err := UpdateOne(rsvpCollection, bson.M{"_id": rsvp.ID}, update); err != nil {
+		return nil, fmt.Errorf("failed to cancel RSVP: %v", err)
+	}
+
+	counter := bson.M{"waitlistCount": -1}
+	if rsvp.Status == models.RSVPGoing {
+		counter = bson.M{"petCount": -len(rsvp.PetIDs)}
+	}
+	if err := UpdateOne(eventCollection, bson.M{"_id": eventID}, bson.M{"$inc": counter}); err != nil {
+		return nil, fmt.Errorf("failed to update event attendance: %v", err)
+	}
+
+	if rsvp.Status == models.RSVPGoing {
+		if err := promoteEventWaitlist(eventID); err != nil {
+			log.Printf("Failed to promote waitlist of event %s: %v", eventID.Hex(), err)
+		}
+	}
+
+	rsvp.Status = models.RSVPCancelled
+	rsvp.UpdatedAt = now
+	return &rsvp, nil
+}
+
+// ListEventRSVPs lists the active RSVPs of an event, attendees first and the waitlist in order
+func ListEventRSVPs(eventID primitive.ObjectID) ([]models.EventRSVP, error) {
+	filter := bson.M{
+		"eventId": eventID,
+		"status":  bson.M{"$in": []string{models.RSVPGoing, models.RSVPWaitlisted}},
+	}
+	var rsvps []models.EventRSVP
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: 1}})
+
+	if err := FindMany(rsvpCollection, filter, &rsvps, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list RSVPs: %v", err)
+	}
+
+	return rsvps, nil
+}
+
+// promoteEventWaitlist moves waitlisted RSVPs in arrival order into the event while their pets
+// fit. The first RSVP that does not fit keeps its place at the head of the waitlist.
+func promoteEventWaitlist(eventID primitive.ObjectID) error {
+	event, err := GetEventByID(eventID)
+	if err != nil {
+		return err
+	}
+	if event.Status != models.EventScheduled {
+		return nil
+	}
+
+	var waitlist []models.EventRSVP
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	if err := FindMany(rsvpCollection, bson.M{"eventId": eventID, "status": models.RSVPWaitlisted}, &waitlist, findOptions); err != nil {
+		return fmt.Errorf("failed to list waitlist: %v", err)
+	}
+
+	for _, rsvp := range waitlist {
+		reserved, err := reserveEventSpots(eventID, len(rsvp.PetIDs))
+		if err != nil {
+			return err
+		}
+		if !reserved {
+			return nil
+		}
+
+		update := bson.M{"$set": bson.M{"status": models.RSVPGoing, "updatedAt": time.Now()}}
+		if err := UpdateOne(rsvpCollection, bson.M{"_id": rsvp.ID}, update); err != nil {
+			return fmt.Errorf("failed to promote RSVP: %v", err)
+		}
+		if err := UpdateOne(eventCollection, bson.M{"_id": eventID}, bson.M{"$inc": bson.M{"waitlistCount": -1}}); err != nil {
+			return fmt.Errorf("failed to update waitlist count: %v", err)
+		}
+
+		if _, err := sendEventMessage(rsvp.UserID, *event, "A spot opened up, you're going"); err != nil {
+			log.Printf("Failed to notify user %s of waitlist promotion: %v", rsvp.UserID.Hex(), err)
+		}
+		rsvp.Status = models.RSVPGoing
+		notifyRSVPChange(*event, rsvp, rsvp.UserID)
+		if event.HostID != rsvp.UserID {
+			notifyRSVPChange(*event, rsvp, event.HostID)
+		}
+	}
+
+	return nil
+}
+
+// notifyRSVPChange pushes an RSVP's new status to the given users
+func notifyRSVPChange(event models.Event, rsvp models.EventRSVP, recipients ...primitive.ObjectID) {
+	change := models.RSVPEvent{
+		EventID:    event.ID,
+		EventTitle: event.Title,
+		UserID:     rsvp.UserID,
+		Status:     rsvp.Status,
+		PetCount:   len(rsvp.PetIDs),
+	}
+	for _, userID := range recipients {
+		PublishUserEvent(userID, models.PushEventRSVP, change)
+	}
+}
+
+// reserveEventSpots atomically takes spots for pets if the event still has room
+func reserveEventSpots(eventID primitive.ObjectID, pets int) (bool, error) {
+	collection := db.GetCollection(eventCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	filter := bson.M{
+		"_id":    eventID,
+		"status": models.EventScheduled,
+		"$expr":  bson.M{"$lte": []interface{}{bson.M{"$add": []interface{}{"$petCount", pets}}, "$capacity"}},
+	}
+	result, err := collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"petCount": pets}})
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve event spots: %v", err)
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// eventAllowsPet checks a pet against the sizes and types an event accepts
+func eventAllowsPet(event *models.Event, pet *models.Pet) bool {
+	return allowsValue(event.PetSizes, pet.Size) && allowsValue(event.PetTypes, pet.Species)
+}
+
+// allowsValue reports whether a value is in an allow list, an empty list allows everything
+func allowsValue(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isNoRSVPError reports whether the error is the missing RSVP error of withdrawEventRSVP
+func isNoRSVPError(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "no RSVP found")
+}
+
+// sendEventMessage puts an event update in an attendee's inbox and sends them an event subscribe message.
+// It reports whether a message was sent.
+func sendEventMessage(userID primitive.ObjectID, event models.Event, note string) (bool, error) {
+	notifyUser(models.Notification{
+		UserID:     userID,
+		Type:       models.NotificationEventReminder,
+		SourceType: "event",
+		SourceID:   event.ID,
+		Title:      note,
+		Body:       event.Title,
+	})
+
+	page := fmt.Sprintf("pages/event/detail?id=%s", event.ID.Hex())
+	return sendUserSubscribeMessage(userID, models.ReminderKindEvent, page,
+		event.Title, event.PlaceName, event.StartTime.Format("2006-01-02 15:04"), note)
+}
+
+// EnsureEventIndexes creates the geospatial index for events and the index for RSVP lookups
+func EnsureEventIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := db.GetCollection(eventCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "location", Value: "2dsphere"}},
+		Options: options.Index().
+			SetName("location_2dsphere").
+			SetBackground(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create event index: %v", err)
+	}
+
+	_, err = db.GetCollection(rsvpCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "eventId", Value: 1}, {Key: "status", Value: 1}, {Key: "createdAt", Value: 1}},
+		Options: options.Index().SetName("eventId_status_createdAt"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create RSVP index: %v", err)
+	}
+
+	return nil
+}
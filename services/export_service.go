@@ -0,0 +1,285 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"playtime-go/models"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	exportCollection = "exports"
+	exportLinkTTL    = 24 * time.Hour
+
+	// exportPageSize is the number of records read per query while collecting an export
+	exportPageSize = 500
+)
+
+// CreateExportJob creates a new export job for a user and starts building it in the background
+func CreateExportJob(userID primitive.ObjectID) (*models.ExportJob, error) {
+	// Make sure the user exists before queueing any work
+	if _, err := GetUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := models.ExportJob{
+		UserID:    userID,
+		Status:    models.ExportStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	id, err := InsertOne(exportCollection, job)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export job: %v", err)
+	}
+	job.ID = id
+
+	go runExportJob(job)
+
+	return &job, nil
+}
+
+// GetExportJob retrieves an export job for a user, attaching a fresh download link when it is ready
+func GetExportJob(userID primitive.ObjectID, id primitive.ObjectID) (*models.ExportJob, error) {
+	filter := bson.M{"_id": id, "userId": userID}
+	var job models.ExportJob
+
+	err := FindOne(exportCollection, filter, &job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no export found with ID: %s", id.Hex())
+		}
+		return nil, fmt.Errorf("failed to get export by ID: %v", err)
+	}
+
+	if job.Status == models.ExportStatusCompleted && job.Filename != "" {
+		downloadURL, err := GetPresignedCOSURL(job.Filename, exportLinkTTL)
+		if err != nil {
+			return nil, err
+		}
+		expiresAt := time.Now().Add(exportLinkTTL)
+		job.DownloadURL = downloadURL
+		job.ExpiresAt = &expiresAt
+	}
+
+	return &job, nil
+}
+
+// runExportJob collects the user's data, bundles it and stores it in COS
+func runExportJob(job models.ExportJob) {
+	updateExportStatus(job.ID, bson.M{"status": models.ExportStatusRunning})
+
+	data, err := collectUserData(job.UserID)
+	if err != nil {
+		log.Printf("Export %s failed: %v", job.ID.Hex(), err)
+		updateExportStatus(job.ID, bson.M{"status": models.ExportStatusFailed, "error": err.Error()})
+		return
+	}
+
+	bundle, err := buildExportBundle(data)
+	if err != nil {
+		log.Printf("Export %s failed: %v", job.ID.Hex(), err)
+		updateExportStatus(job.ID, bson.M{"status": models.ExportStatusFailed, "error": err.Error()})
+		return
+	}
+
+	key := fmt.Sprintf("export/%s/%s.zip", job.UserID.Hex(), job.ID.Hex())
+	if err := PutPrivateObjectToCOS(key, bytes.NewReader(bundle), "application/zip"); err != nil {
+		log.Printf("Export %s failed: %v", job.ID.Hex(), err)
+		updateExportStatus(job.ID, bson.M{"status": models.ExportStatusFailed, "error": err.Error()})
+		return
+	}
+
+	updateExportStatus(job.ID, bson.M{"status": models.ExportStatusCompleted, "filename": key})
+	log.Printf("Export %s completed for user %s", job.ID.Hex(), job.UserID.Hex())
+}
+
+// updateExportStatus sets fields on an export job and bumps its update time
+func updateExportStatus(id primitive.ObjectID, fields bson.M) {
+	fields["updatedAt"] = time.Now()
+	if err := UpdateOne(exportCollection, bson.M{"_id": id}, bson.M{"$set": fields}); err != nil {
+		log.Printf("Failed to update export %s: %v", id.Hex(), err)
+	}
+}
+
+// listMemberPets returns every pet the user is an active member of, reading them page by page
+func listMemberPets(userID primitive.ObjectID, now time.Time) ([]models.Pet, error) {
+	filter := activePetMemberFilter(userID, now)
+	var pets []models.Pet
+	for {
+		findOptions := options.Find()
+		findOptions.SetSort(bson.D{{Key: "_id", Value: 1}})
+		findOptions.SetLimit(exportPageSize)
+
+		var page []models.Pet
+		if err := FindMany(petCollection, filter, &page, findOptions); err != nil {
+			return nil, fmt.Errorf("failed to list pets: %v", err)
+		}
+		pets = append(pets, page...)
+		if len(page) < exportPageSize {
+			return pets, nil
+		}
+		filter["_id"] = bson.M{"$gt": page[len(page)-1].ID}
+	}
+}
+
+// collectUserData gathers everything we hold about a user
+func collectUserData(userID primitive.ObjectID) (*models.UserDataExport, error) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	memberPets, err := listMemberPets(userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pets the user only sits for belong to someone else
+	pets := make([]models.Pet, 0, len(memberPets))
+	for _, pet := range memberPets {
+		if member := findActivePetMember(&pet, userID, now); member != nil && member.Role != models.PetRoleSitter {
+			pets = append(pets, pet)
+		}
+	}
+
+	var healthRecords []models.HealthRecord
+	for _, pet := range pets {
+		records, err := ListHealthRecords(pet.ID, userID, "")
+		if err != nil {
+			return nil, err
+		}
+		healthRecords = append(healthRecords, records...)
+	}
+
+	reviews, err := GetReviewsByUserID(context.Background(), userID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	places, err := ListLocationsByCreator(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	uploads, err := ListUploadsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	visits, err := ListUserVisits(userID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &models.UserDataExport{
+		User:          *user,
+		Pets:          pets,
+		HealthRecords: healthRecords,
+		Reviews:       reviews,
+		Places:        places,
+		Uploads:       uploads,
+		Visits:        visits,
+		ExportedAt:    time.Now(),
+	}
+	if user.HomeArea != nil {
+		export.HomeArea = homeAreaResponse(user.HomeArea)
+	}
+
+	return export, nil
+}
+
+// buildExportBundle writes the export as a zip with one JSON document and a CSV per collection
+func buildExportBundle(data *models.UserDataExport) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal export: %v", err)
+	}
+	if err := writeZipFile(zw, "data.json", jsonData); err != nil {
+		return nil, err
+	}
+
+	user := data.User
+	files := map[string][][]string{
+		"profile.csv": {
+			{"id", "nickName", "phoneNumber", "avatarUrl", "openId", "unionId", "createdAt"},
+			{user.ID.Hex(), user.NickName, user.PhoneNumber, user.AvatarURL, user.OpenID, user.UnionID, user.CreatedAt.Format(time.RFC3339)},
+		},
+		"pets.csv":    {{"id", "name", "gender", "size", "breed", "avatar", "character", "birthDate", "birthDateApproximate", "createdAt"}},
+		"reviews.csv": {{"id", "placeId", "content", "rating", "date"}},
+		"places.csv":  {{"id", "name", "address", "category", "latitude", "longitude"}},
+		"uploads.csv": {{"id", "url", "contentType", "size", "createdAt"}},
+	}
+	for _, pet := range data.Pets {
+		birthDate := ""
+		if pet.BirthDate != nil {
+			birthDate = pet.BirthDate.Format("2006-01-02")
+		}
+		files["pets.csv"] = append(files["pets.csv"], []string{
+			pet.ID.Hex(), pet.Name, pet.Gender, pet.Size, pet.Breed, pet.Avatar, pet.Character,
+			birthDate, strconv.FormatBool(pet.BirthDateApproximate), pet.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	for _, review := range data.Reviews {
+		files["reviews.csv"] = append(files["reviews.csv"], []string{
+			review.ID.Hex(), review.PlaceID, review.Content, strconv.Itoa(review.Rating), review.Date.Format(time.RFC3339),
+		})
+	}
+	for _, place := range data.Places {
+		files["places.csv"] = append(files["places.csv"], []string{
+			place.ID.Hex(), place.Name, place.Address, place.Category,
+			strconv.FormatFloat(place.Latitude, 'f', -1, 64), strconv.FormatFloat(place.Longitude, 'f', -1, 64),
+		})
+	}
+	for _, upload := range data.Uploads {
+		files["uploads.csv"] = append(files["uploads.csv"], []string{
+			upload.ID.Hex(), upload.URL, upload.ContentType, strconv.FormatInt(upload.Size, 10), upload.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	for name, rows := range files {
+		var csvBuf bytes.Buffer
+		cw := csv.NewWriter(&csvBuf)
+		if err := cw.WriteAll(rows); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %v", name, err)
+		}
+		if err := writeZipFile(zw, name, csvBuf.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize export bundle: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeZipFile adds a single file to a zip archive
+func writeZipFile(zw *zip.Writer, name string, content []byte) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to export bundle: %v", name, err)
+	}
+	if _, err := f.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s to export bundle: %v", name, err)
+	}
+	return nil
+}
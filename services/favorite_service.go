@@ -0,0 +1,410 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"playtime-go/db"
+	"playtime-go/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	placeListCollection = "place_lists"
+	maxPlaceLists       = 50
+	maxPlaceListItems   = 200
+)
+
+// CreatePlaceList creates a new empty list for the user
+func CreatePlaceList(userID primitive.ObjectID, request models.PlaceListRequest) (*models.PlaceListResponse, error) {
+	count, err := Count(placeListCollection, bson.M{"userId": userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count lists: %v", err)
+	}
+	if count >= maxPlaceLists {
+		return nil, fmt.Errorf("invalid list: a user can have at most %d lists", maxPlaceLists)
+	}
+
+	now := time.Now()
+	list := models.PlaceList{
+		UserID:      userID,
+		Name:        request.Name,
+		Description: request.Description,
+		IsPublic:    request.IsPublic,
+		Items:       []models.PlaceListItem{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	id, err := InsertOne(placeListCollection, list)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create list: %v", err)
+	}
+
+	list.ID = id
+	return buildPlaceListResponse(&list, true)
+}
+
+// ListPlaceLists returns the lists of a user. Other users only see the public ones.
+func ListPlaceLists(ownerID primitive.ObjectID, viewerID primitive.ObjectID) ([]models.PlaceListResponse, error) {
+	filter := bson.M{"userId": ownerID}
+	if ownerID != viewerID {
+		filter["isPublic"] = true
+	}
+	var lists []models.PlaceList
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "updatedAt", Value: -1}})
+
+	if err := FindMany(placeListCollection, filter, &lists, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list place lists: %v", err)
+	}
+
+	responses := make([]models.PlaceListResponse, 0, len(lists))
+	for i := range lists {
+		response, err := buildPlaceListResponse(&lists[i], ownerID == viewerID)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+
+	return responses, nil
+}
+
+// GetPlaceList retrieves a list that is public or belongs to the viewer
+func GetPlaceList(id primitive.ObjectID, viewerID primitive.ObjectID) (*models.PlaceListResponse, error) {
+	list, err := getPlaceList(id)
+	if err != nil {
+		return nil, err
+	}
+	if list.UserID != viewerID && !list.IsPublic {
+		return nil, fmt.Errorf("no place list found with ID: %s", id.Hex())
+	}
+
+	return buildPlaceListResponse(list, list.UserID == viewerID)
+}
+
+// GetSharedPlaceList retrieves a list through its share link, even if it is private
+func GetSharedPlaceList(code string) (*models.PlaceListResponse, error) {
+	var list models.PlaceList
+	if err := FindOne(placeListCollection, bson.M{"shareCode": code}, &list); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no place list found with share code: %s", code)
+		}
+		return nil, fmt.Errorf("failed to get shared list: %v", err)
+	}
+
+	return buildPlaceListResponse(&list, false)
+}
+
+// UpdatePlaceList updates the name, description and visibility of the user's list
+func UpdatePlaceList(id primitive.ObjectID, userID primitive.ObjectID, request models.PlaceListRequest) (*models.PlaceListResponse, error) {
+	if _, err := getOwnPlaceList(id, userID); err != nil {
+		return nil, err
+	}
+
+	update := bson.M{"$set": bson.M{
+		"name":        request.Name,
+		"description": request.Description,
+		"isPublic":    request.IsPublic,
+		"updatedAt":   time.Now(),
+	}}
+	if err := UpdateOne(placeListCollection, bson.M{"_id": id}, update); err != nil {
+		return nil, fmt.Errorf("failed to update list: %v", err)
+	}
+
+	return GetPlaceList(id, userID)
+}
+
+// DeletePlaceList deletes the user's list
+func DeletePlaceList(id primitive.ObjectID, userID primitive.ObjectID) error {
+	if _, err := getOwnPlaceList(id, userID); err != nil {
+		return err
+	}
+
+	if err := DeleteOne(placeListCollection, bson.M{"_id": id}); err != nil {
+		return fmt.Errorf("failed to delete list: %v", err)
+	}
+
+	return nil
+}
+
+// AddPlaceToList appends a place to the end of the user's list
+func AddPlaceToList(id primitive.ObjectID, userID primitive.ObjectID, request models.PlaceListItemRequest) (*models.PlaceListResponse, error) {
+	list, err := getOwnPlaceList(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	place, err := GetLocationByID(request.PlaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range list.Items {
+		if item.PlaceID == place.ID {
+			return nil, fmt.Errorf("place is already in list: %s", place.ID.Hex())
+		}
+	}
+	if len(list.Items) >= maxPlaceListItems {
+		return nil, fmt.Errorf("invalid list: a list can hold at most %d places", maxPlaceListItems)
+	}
+
+	now := time.Now()
+	item := models.PlaceListItem{
+		PlaceID: place.ID,
+		Name:    place.Name,
+		Address: place.Address,
+		Note:    request.Note,
+		AddedAt: now,
+	}
+
+	update := bson.M{
+		"$push": bson.M{"items": item},
+		"$set":  bson.M{"updatedAt": now},
+	}
+	if err := UpdateOne(placeListCollection, bson.M{"_id": id}, update); err != nil {
+		return nil, fmt.Errorf("failed to add place to list: %v", err)
+	}
+
+	return GetPlaceList(id, userID)
+}
+
+// RemovePlaceFromList removes a place, or its tombstone, from the user's list
+func RemovePlaceFromList(id primitive.ObjectID, userID primitive.ObjectID, placeID primitive.ObjectID) (*models.PlaceListResponse, error) {
+	if _, err := getOwnPlaceList(id, userID); err != nil {
+		return nil, err
+	}
+
+	update := bson.M{
+		"$pull": bson.M{"items": bson.M{"placeId": placeID}},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
+	if err := UpdateOne(placeListCollection, bson.M{"_id": id}, update); err != nil {
+		return nil, fmt.Errorf("failed to remove place from list: %v", err)
+	}
+
+	return GetPlaceList(id, userID)
+}
+
+// ReorderPlaceList sets the order of the places in the user's list. Places not listed
+// keep their relative order after the listed ones.
+func ReorderPlaceList(id primitive.ObjectID, userID primitive.ObjectID, request models.PlaceListOrderRequest) (*models.PlaceListResponse, error) {
+	list, err := getOwnPlaceList(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byPlace := make(map[primitive.ObjectID]models.PlaceListItem, len(list.Items))
+	for _, item := range list.Items {
+		byPlace[item.PlaceID] = item
+	}
+
+	items := make([]models.PlaceListItem, 0, len(list.Items))
+	placed := make(map[primitive.ObjectID]bool, len(list.Items))
+	for _, placeID := range request.PlaceIDs {
+		if item, ok := byPlace[placeID]; ok && !placed[placeID] {
+			items = append(items, item)
+			placed[placeID] = true
+		}
+	}
+	for _, item := range list.Items {
+		if !placed[item.PlaceID] {
+			items = append(items, item)
+		}
+	}
+
+	update := bson.M{"$set": bson.M{"items": items, "updatedAt": time.Now()}}
+	if err := UpdateOne(placeListCollection, bson.M{"_id": id}, update); err != nil {
+		return nil, fmt.Errorf("failed to reorder list: %v", err)
+	}
+
+	return GetPlaceList(id, userID)
+}
+
+// SharePlaceList creates a new share link code for the user's list, replacing any previous one
+func SharePlaceList(id primitive.ObjectID, userID primitive.ObjectID) (*models.PlaceListResponse, error) {
+	if _, err := getOwnPlaceList(id, userID); err != nil {
+		return nil, err
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{"$set": bson.M{"shareCode": code, "updatedAt": time.Now()}}
+	if err := UpdateOne(placeListCollection, bson.M{"_id": id}, update); err != nil {
+		return nil, fmt.Errorf("failed to share list: %v", err)
+	}
+
+	return GetPlaceList(id, userID)
+}
+
+// UnsharePlaceList revokes the share link of the user's list
+func UnsharePlaceList(id primitive.ObjectID, userID primitive.ObjectID) (*models.PlaceListResponse, error) {
+	if _, err := getOwnPlaceList(id, userID); err != nil {
+		return nil, err
+	}
+
+	update := bson.M{"$unset": bson.M{"shareCode": ""}, "$set": bson.M{"updatedAt": time.Now()}}
+	if err := UpdateOne(placeListCollection, bson.M{"_id": id}, update); err != nil {
+		return nil, fmt.Errorf("failed to unshare list: %v", err)
+	}
+
+	return GetPlaceList(id, userID)
+}
+
+// MarkFavoritedResults sets IsFavorited on search results for places the user saved in any list
+func MarkFavoritedResults(userID primitive.ObjectID, results []models.SearchResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	collection := db.GetCollection(placeListCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	placeIDs, err := collection.Distinct(ctx, "items.placeId", bson.M{"userId": userID})
+	if err != nil {
+		return fmt.Errorf("failed to get favorited places: %v", err)
+	}
+
+	favorited := make(map[primitive.ObjectID]bool, len(placeIDs))
+	for _, placeID := range placeIDs {
+		if id, ok := placeID.(primitive.ObjectID); ok {
+			favorited[id] = true
+		}
+	}
+
+	for i := range results {
+		results[i].IsFavorited = favorited[results[i].Location.ID]
+	}
+
+	return nil
+}
+
+// tombstonePlaceInLists marks a deleted place in every list that saved it
+func tombstonePlaceInLists(placeID primitive.ObjectID) error {
+	collection := db.GetCollection(placeListCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	now := time.Now()
+	update := bson.M{"$set": bson.M{"items.$[item].deletedAt": now}}
+	updateOptions := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"item.placeId": placeID, "item.deletedAt": nil}},
+	})
+
+	_, err := collection.UpdateMany(ctx, bson.M{"items.placeId": placeID}, update, updateOptions)
+	if err != nil {
+		return fmt.Errorf("failed to tombstone place in lists: %v", err)
+	}
+
+	return nil
+}
+
+// getPlaceList retrieves a list by its ID
+func getPlaceList(id primitive.ObjectID) (*models.PlaceList, error) {
+	var list models.PlaceList
+	if err := FindOne(placeListCollection, bson.M{"_id": id}, &list); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no place list found with ID: %s", id.Hex())
+		}
+		return nil, fmt.Errorf("failed to get place list by ID: %v", err)
+	}
+
+	return &list, nil
+}
+
+// getOwnPlaceList retrieves a list and verifies that it belongs to the user
+func getOwnPlaceList(id primitive.ObjectID, userID primitive.ObjectID) (*models.PlaceList, error) {
+	list, err := getPlaceList(id)
+	if err != nil {
+		return nil, err
+	}
+	if list.UserID != userID {
+		return nil, fmt.Errorf("not authorized to modify place list: %s", id.Hex())
+	}
+
+	return list, nil
+}
+
+// buildPlaceListResponse loads the current details of a list's places. The share code is only
+// included for the list's owner.
+func buildPlaceListResponse(list *models.PlaceList, owner bool) (*models.PlaceListResponse, error) {
+	placeIDs := make([]primitive.ObjectID, 0, len(list.Items))
+	for _, item := range list.Items {
+		if item.DeletedAt == nil {
+			placeIDs = append(placeIDs, item.PlaceID)
+		}
+	}
+
+	places := make(map[primitive.ObjectID]*models.LocationResponse, len(placeIDs))
+	if len(placeIDs) > 0 {
+		var locations []models.Location
+		if err := FindMany(locationCollection, bson.M{"_id": bson.M{"$in": placeIDs}}, &locations); err != nil {
+			return nil, fmt.Errorf("failed to load list places: %v", err)
+		}
+		for _, location := range locations {
+			if response, err := ConvertLocationToResponse(location); err == nil {
+				places[location.ID] = response
+			}
+		}
+	}
+
+	entries := make([]models.PlaceListEntry, 0, len(list.Items))
+	for _, item := range list.Items {
+		entry := models.PlaceListEntry{PlaceListItem: item, Place: places[item.PlaceID]}
+		entry.Tombstone = entry.Place == nil
+		entries = append(entries, entry)
+	}
+
+	response := &models.PlaceListResponse{
+		ID:          list.ID,
+		UserID:      list.UserID,
+		Name:        list.Name,
+		Description: list.Description,
+		IsPublic:    list.IsPublic,
+		Entries:     entries,
+		CreatedAt:   list.CreatedAt,
+		UpdatedAt:   list.UpdatedAt,
+	}
+	if owner {
+		response.ShareCode = list.ShareCode
+	}
+
+	return response, nil
+}
+
+// EnsurePlaceListIndexes creates the indexes for a user's lists, share codes and place lookups
+func EnsurePlaceListIndexes() error {
+	collection := db.GetCollection(placeListCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "updatedAt", Value: -1}},
+			Options: options.Index().SetName("userId_updatedAt"),
+		},
+		{
+			Keys:    bson.D{{Key: "shareCode", Value: 1}},
+			Options: options.Index().SetName("shareCode_unique").SetUnique(true).SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "items.placeId", Value: 1}},
+			Options: options.Index().SetName("items_placeId"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create place list indexes: %v", err)
+	}
+
+	return nil
+}
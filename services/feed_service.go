@@ -0,0 +1,419 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"playtime-go/db"
+	"playtime-go/models"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	feedActivityCollection = "feed_activities"
+	feedItemCollection     = "feed_items"
+
+	// maxFeedFanOut is the follower count above which a user or place is not fanned out.
+	// Their followers pull the activities when reading the feed instead.
+	maxFeedFanOut = 5000
+
+	// feedInsertBatch is the number of feed items written per insert during fan-out
+	feedInsertBatch = 1000
+
+	// feedRetention is how long activities stay in feeds
+	feedRetention = 90 * 24 * time.Hour
+
+	// maxFeedSummary is the number of characters of text shown in a feed entry
+	maxFeedSummary = 80
+)
+
+// publishFeedActivity records an activity and fans it out into the feeds of the actor's followers,
+// and for activities at a place, of the place's followers. Popular sources are skipped, see maxFeedFanOut.
+func publishFeedActivity(activity models.FeedActivity) {
+	activity.CreatedAt = time.Now()
+	id, err := InsertOne(feedActivityCollection, activity)
+	if err != nil {
+		log.Printf("Failed to record %s feed activity of user %s: %v", activity.Kind, activity.ActorID.Hex(), err)
+		return
+	}
+	activity.ID = id
+
+	recipients := map[primitive.ObjectID]bool{}
+	if err := addFanOutRecipients(recipients, models.FollowTargetUser, activity.ActorID); err != nil {
+		log.Printf("Failed to find followers of user %s: %v", activity.ActorID.Hex(), err)
+	}
+	if activity.Kind == models.FeedKindReview && activity.PlaceID != nil {
+		if err := addFanOutRecipients(recipients, models.FollowTargetPlace, *activity.PlaceID); err != nil {
+			log.Printf("Failed to find followers of place %s: %v", activity.PlaceID.Hex(), err)
+		}
+	}
+	delete(recipients, activity.ActorID)
+
+	items := make([]interface{}, 0, feedInsertBatch)
+	for userID := range recipients {
+		items = append(items, models.FeedItem{UserID: userID, Activity: activity})
+		if len(items) == feedInsertBatch {
+			insertFeedItems(items)
+			items = items[:0]
+		}
+	}
+	if len(items) > 0 {
+		insertFeedItems(items)
+	}
+}
+
+// publishReviewActivity shares a new review with the reviewer's followers and the place's followers
+func publishReviewActivity(review models.Review) {
+	actorID, err := primitive.ObjectIDFromHex(review.UserID)
+	if err != nil {
+		return
+	}
+
+	activity := models.FeedActivity{
+		Kind:      models.FeedKindReview,
+		ActorID:   actorID,
+		ActorName: review.UserName,
+		SourceID:  review.ID,
+		Summary:   feedSummary(review.Content),
+	}
+	if placeID, err := primitive.ObjectIDFromHex(review.PlaceID); err == nil {
+		activity.PlaceID = &placeID
+		if place, err := GetLocationByID(placeID); err == nil {
+			activity.PlaceName = place.Name
+		}
+	}
+
+	publishFeedActivity(activity)
+}
+
+// publishPetActivity shares a newly added pet with the owner's followers
+func publishPetActivity(pet models.Pet) {
+	activity := models.FeedActivity{
+		Kind:     models.FeedKindPet,
+		ActorID:  pet.OwnerID,
+		SourceID: pet.ID,
+		Summary:  feedSummary(pet.Name),
+	}
+	if user, err := GetUserByID(pet.OwnerID); err == nil {
+		activity.ActorName = user.NickName
+	}
+
+	publishFeedActivity(activity)
+}
+
+// publishCheckInActivity shares a check-in with the user's followers
+func publishCheckInActivity(checkIn models.CheckIn) {
+	activity := models.FeedActivity{
+		Kind:      models.FeedKindCheckIn,
+		ActorID:   checkIn.UserID,
+		SourceID:  checkIn.ID,
+		PlaceID:   &checkIn.PlaceID,
+		PlaceName: checkIn.PlaceName,
+		Summary:   feedSummary(checkIn.PlaceName),
+	}
+	if user, err := GetUserByID(checkIn.UserID); err == nil {
+		activity.ActorName = user.NickName
+	}
+
+	publishFeedActivity(activity)
+}
+
+// feedSummary shortens text to the length shown in a feed entry
+func feedSummary(text string) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= maxFeedSummary {
+		return string(runes)
+	}
+	return string(runes[:maxFeedSummary-1]) + "…"
+}
+
+// addFanOutRecipients adds the followers of a user or place to recipients unless it has too many to fan out to
+func addFanOutRecipients(recipients map[primitive.ObjectID]bool, targetType string, targetID primitive.ObjectID) error {
+	targetCollection, err := followTargetCollection(targetType)
+	if err != nil {
+		return err
+	}
+
+	popular, err := Count(targetCollection, bson.M{"_id": targetID, "followerCount": bson.M{"$gt": maxFeedFanOut}})
+	if err != nil {
+		return fmt.Errorf("failed to get follower count: %v", err)
+	}
+	if popular > 0 {
+		return nil
+	}
+
+	var follows []models.Follow
+	findOptions := options.Find().SetProjection(bson.M{"followerId": 1}).SetLimit(maxFeedFanOut)
+	if err := FindMany(followCollection, bson.M{"targetType": targetType, "targetId": targetID}, &follows, findOptions); err != nil {
+		return fmt.Errorf("failed to list followers: %v", err)
+	}
+	for _, follow := range follows {
+		recipients[follow.FollowerID] = true
+	}
+
+	return nil
+}
+
+// insertFeedItems writes a batch of fanned out feed items
+func insertFeedItems(items []interface{}) {
+	collection := db.GetCollection(feedItemCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if _, err := collection.InsertMany(ctx, items, options.InsertMany().SetOrdered(false)); err != nil {
+		log.Printf("Failed to fan out %d feed items: %v", len(items), err)
+	}
+}
+
+// removeFeedActivity takes the activities about a deleted or hidden review, pet or check-in out of all feeds
+func removeFeedActivity(sourceID primitive.ObjectID) {
+	if _, err := DeleteMany(feedActivityCollection, bson.M{"sourceId": sourceID}); err != nil {
+		log.Printf("Failed to delete feed activities of %s: %v", sourceID.Hex(), err)
+	}
+	if _, err := DeleteMany(feedItemCollection, bson.M{"activity.sourceId": sourceID}); err != nil {
+		log.Printf("Failed to delete feed items of %s: %v", sourceID.Hex(), err)
+	}
+}
+
+// removeFeedItemsFrom takes the activities of an actor out of a user's feed
+func removeFeedItemsFrom(userID primitive.ObjectID, actorID primitive.ObjectID) {
+	if _, err := DeleteMany(feedItemCollection, bson.M{"userId": userID, "activity.actorId": actorID}); err != nil {
+		log.Printf("Failed to delete feed items of user %s from %s: %v", userID.Hex(), actorID.Hex(), err)
+	}
+}
+
+// GetFeed returns a page of the user's feed, newest first. The cursor is the NextCursor of the previous page.
+// Fanned out items are merged with the recent activities of followed popular users and places.
+func GetFeed(userID primitive.ObjectID, cursor string, limit int64) (*models.FeedPage, error) {
+	if limit <= 0 {
+		limit = 20 // Default limit
+	}
+
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	activityFilter := bson.M{}
+	if cursor != "" {
+		before, err := primitive.ObjectIDFromHex(cursor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %s", cursor)
+		}
+		activityFilter["_id"] = bson.M{"$lt": before}
+	}
+	// Leave out users the viewer blocked or muted and users who blocked the viewer
+	blockers, err := usersBlocking(userID)
+	if err != nil {
+		return nil, err
+	}
+	hidden := make([]primitive.ObjectID, 0, len(user.BlockedIDs)+len(user.MutedIDs)+len(blockers))
+	hidden = append(append(append(hidden, user.BlockedIDs...), user.MutedIDs...), blockers...)
+	if len(hidden) > 0 {
+		activityFilter["actorId"] = bson.M{"$nin": hidden}
+	}
+
+	// Fetch one extra activity to know whether another page follows
+	activities, err := fannedOutActivities(userID, activityFilter, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	pulled, err := popularSourceActivities(userID, activityFilter, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Merge newest first, a popular source's activity may also have been fanned out before it grew popular
+	seen := make(map[primitive.ObjectID]bool, len(activities))
+	merged := make([]models.FeedActivity, 0, len(activities)+len(pulled))
+	for _, activity := range append(activities, pulled...) {
+		if !seen[activity.ID] {
+			seen[activity.ID] = true
+			merged = append(merged, activity)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].ID.Hex() > merged[j].ID.Hex()
+	})
+
+	page := &models.FeedPage{Items: merged}
+	if int64(len(merged)) > limit {
+		page.Items = merged[:limit]
+		page.NextCursor = page.Items[limit-1].ID.Hex()
+	}
+
+	return page, nil
+}
+
+// fannedOutActivities reads activities from the user's own feed items
+func fannedOutActivities(userID primitive.ObjectID, activityFilter bson.M, limit int64) ([]models.FeedActivity, error) {
+	filter := bson.M{"userId": userID}
+	for key, value := range activityFilter {
+		filter["activity."+key] = value
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "activity._id", Value: -1}})
+	findOptions.SetLimit(limit)
+
+	var items []models.FeedItem
+	if err := FindMany(feedItemCollection, filter, &items, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to get feed: %v", err)
+	}
+
+	activities := make([]models.FeedActivity, 0, len(items))
+	for _, item := range items {
+		activities = append(activities, item.Activity)
+	}
+	return activities, nil
+}
+
+// popularSourceActivities reads the activities of followed users and places too popular to be fanned out
+func popularSourceActivities(userID primitive.ObjectID, activityFilter bson.M, limit int64) ([]models.FeedActivity, error) {
+	var follows []models.Follow
+	if err := FindMany(followCollection, bson.M{"followerId": userID}, &follows); err != nil {
+		return nil, fmt.Errorf("failed to list follows: %v", err)
+	}
+
+	var userIDs, placeIDs []primitive.ObjectID
+	for _, follow := range follows {
+		if follow.TargetType == models.FollowTargetPlace {
+			placeIDs = append(placeIDs, follow.TargetID)
+		} else {
+			userIDs = append(userIDs, follow.TargetID)
+		}
+	}
+
+	popularUsers, err := popularFollowTargets(userCollection, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	popularPlaces, err := popularFollowTargets(locationCollection, placeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []bson.M
+	if len(popularUsers) > 0 {
+		sources = append(sources, bson.M{"actorId": bson.M{"$in": popularUsers}})
+	}
+	if len(popularPlaces) > 0 {
+		sources = append(sources, bson.M{"kind": models.FeedKindReview, "placeId": bson.M{"$in": popularPlaces}})
+	}
+	if len(sources) == 0 {
+		return nil, nil
+	}
+
+	filter := bson.M{"$or": sources}
+	for key, value := range activityFilter {
+		filter[key] = value
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "_id", Value: -1}})
+	findOptions.SetLimit(limit)
+
+	var activities []models.FeedActivity
+	if err := FindMany(feedActivityCollection, filter, &activities, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to get feed activities: %v", err)
+	}
+
+	// The user's own activities are not part of their feed
+	others := activities[:0]
+	for _, activity := range activities {
+		if activity.ActorID != userID {
+			others = append(others, activity)
+		}
+	}
+	return others, nil
+}
+
+// popularFollowTargets returns the IDs among ids whose follower count is above the fan-out cap
+func popularFollowTargets(collection string, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var targets []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	filter := bson.M{"_id": bson.M{"$in": ids}, "followerCount": bson.M{"$gt": maxFeedFanOut}}
+	if err := FindMany(collection, filter, &targets, options.Find().SetProjection(bson.M{"_id": 1})); err != nil {
+		return nil, fmt.Errorf("failed to find popular follows: %v", err)
+	}
+
+	popular := make([]primitive.ObjectID, 0, len(targets))
+	for _, target := range targets {
+		popular = append(popular, target.ID)
+	}
+	return popular, nil
+}
+
+// EnsureFeedIndexes creates the follow, feed item and feed activity indexes
+func EnsureFeedIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := db.GetCollection(followCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "followerId", Value: 1}, {Key: "targetType", Value: 1}, {Key: "targetId", Value: 1}},
+			Options: options.Index().SetName("follower_target_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "targetType", Value: 1}, {Key: "targetId", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("target_createdAt"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create follow indexes: %v", err)
+	}
+
+	_, err = db.GetCollection(feedItemCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "activity._id", Value: -1}},
+			Options: options.Index().SetName("userId_activityId"),
+		},
+		{
+			Keys:    bson.D{{Key: "activity.sourceId", Value: 1}},
+			Options: options.Index().SetName("activity_sourceId"),
+		},
+		{
+			Keys:    bson.D{{Key: "activity.createdAt", Value: 1}},
+			Options: options.Index().SetName("activity_createdAt_ttl").SetExpireAfterSeconds(int32(feedRetention.Seconds())),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create feed item indexes: %v", err)
+	}
+
+	_, err = db.GetCollection(feedActivityCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "actorId", Value: 1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("actorId_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "placeId", Value: 1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("placeId_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "sourceId", Value: 1}},
+			Options: options.Index().SetName("sourceId"),
+		},
+		{
+			Keys:    bson.D{{Key: "createdAt", Value: 1}},
+			Options: options.Index().SetName("createdAt_ttl").SetExpireAfterSeconds(int32(feedRetention.Seconds())),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create feed activity indexes: %v", err)
+	}
+
+	return nil
+}
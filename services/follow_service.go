@@ -0,0 +1,231 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"playtime-go/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const followCollection = "follows"
+
+// FollowTarget makes the user follow another user or a place. Following something twice is a no-op.
+func FollowTarget(followerID primitive.ObjectID, request models.FollowRequest) (*models.Follow, error) {
+	targetCollection, err := followTargetCollection(request.TargetType)
+	if err != nil {
+		return nil, err
+	}
+
+	switch request.TargetType {
+	case models.FollowTargetUser:
+		if request.TargetID == followerID {
+			return nil, fmt.Errorf("invalid follow: cannot follow yourself")
+		}
+		if _, err := GetUserByID(request.TargetID); err != nil {
+			return nil, err
+		}
+		if err := ensureNotBlockedBy(request.TargetID, followerID); err != nil {
+			return nil, err
+		}
+	case models.FollowTargetPlace:
+		if _, err := GetLocationByID(request.TargetID); err != nil {
+			return nil, err
+		}
+	}
+
+	follow := models.Follow{
+		FollowerID: followerID,
+		TargetType: request.TargetType,
+		TargetID:   request.TargetID,
+		CreatedAt:  time.Now(),
+	}
+
+	id, err := InsertOne(followCollection, follow)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return findFollow(followerID, request.TargetType, request.TargetID)
+		}
+		return nil, fmt.Errorf("failed to follow: %v", err)
+	}
+	follow.ID = id
+
+	// A follow whose counts could not be updated is taken back so counts and follows stay in step
+	if err := updateFollowCounts(followerID, targetCollection, request.TargetID, 1); err != nil {
+		if deleteErr := DeleteOne(followCollection, bson.M{"_id": id}); deleteErr != nil {
+			log.Printf("Failed to roll back follow %s: %v", id.Hex(), deleteErr)
+		}
+		return nil, err
+	}
+
+	if request.TargetType == models.FollowTargetUser {
+		go notifyNewFollower(followerID, request.TargetID)
+	}
+
+	return &follow, nil
+}
+
+// notifyNewFollower tells a user someone started following them
+func notifyNewFollower(followerID primitive.ObjectID, userID primitive.ObjectID) {
+	notification := models.Notification{
+		UserID:     userID,
+		Type:       models.NotificationFollower,
+		ActorID:    &followerID,
+		SourceType: models.FollowTargetUser,
+		SourceID:   followerID,
+		Title:      "You have a new follower",
+	}
+	if follower, err := GetUserByID(followerID); err == nil {
+		notification.ActorName = follower.NickName
+		notification.Title = follower.NickName + " started following you"
+	}
+
+	notifyUser(notification)
+}
+
+// UnfollowTarget stops the user following another user or a place
+func UnfollowTarget(followerID primitive.ObjectID, targetType string, targetID primitive.ObjectID) error {
+	targetCollection, err := followTargetCollection(targetType)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := DeleteMany(followCollection, bson.M{"followerId": followerID, "targetType": targetType, "targetId": targetID})
+	if err != nil {
+		return fmt.Errorf("failed to unfollow: %v", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("no follow found for %s: %s", targetType, targetID.Hex())
+	}
+
+	return updateFollowCounts(followerID, targetCollection, targetID, -1)
+}
+
+// ListFollowing lists what the user follows, optionally only users or places, newest first
+func ListFollowing(userID primitive.ObjectID, targetType string, limit int64) ([]models.Follow, error) {
+	filter := bson.M{"followerId": userID}
+	if targetType != "" {
+		if _, err := followTargetCollection(targetType); err != nil {
+			return nil, err
+		}
+		filter["targetType"] = targetType
+	}
+
+	return findFollows(filter, limit)
+}
+
+// ListFollowers lists the followers of a user or place, newest first
+func ListFollowers(targetType string, targetID primitive.ObjectID, limit int64) ([]models.Follow, error) {
+	if _, err := followTargetCollection(targetType); err != nil {
+		return nil, err
+	}
+
+	return findFollows(bson.M{"targetType": targetType, "targetId": targetID}, limit)
+}
+
+// findFollows runs a follow query newest first with the default limit
+func findFollows(filter bson.M, limit int64) ([]models.Follow, error) {
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	if limit > 0 {
+		findOptions.SetLimit(limit)
+	} else {
+		findOptions.SetLimit(100) // Default limit
+	}
+
+	follows := []models.Follow{}
+	if err := FindMany(followCollection, filter, &follows, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list follows: %v", err)
+	}
+
+	return follows, nil
+}
+
+// findFollow returns the user's follow of a target
+func findFollow(followerID primitive.ObjectID, targetType string, targetID primitive.ObjectID) (*models.Follow, error) {
+	var follow models.Follow
+	if err := FindOne(followCollection, bson.M{"followerId": followerID, "targetType": targetType, "targetId": targetID}, &follow); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no follow found for %s: %s", targetType, targetID.Hex())
+		}
+		return nil, fmt.Errorf("failed to get follow: %v", err)
+	}
+	return &follow, nil
+}
+
+// followTargetCollection returns the collection holding a followable target type
+func followTargetCollection(targetType string) (string, error) {
+	switch targetType {
+	case models.FollowTargetUser:
+		return userCollection, nil
+	case models.FollowTargetPlace:
+		return locationCollection, nil
+	}
+	return "", fmt.Errorf("invalid follow: unknown target type %q", targetType)
+}
+
+// updateFollowCounts moves the follower's following count and the target's follower count by delta
+func updateFollowCounts(followerID primitive.ObjectID, targetCollection string, targetID primitive.ObjectID, delta int) error {
+	if err := UpdateOne(userCollection, bson.M{"_id": followerID}, bson.M{"$inc": bson.M{"followingCount": delta}}); err != nil {
+		return fmt.Errorf("failed to update following count: %v", err)
+	}
+	if err := UpdateOne(targetCollection, bson.M{"_id": targetID}, bson.M{"$inc": bson.M{"followerCount": delta}}); err != nil {
+		// Undo the following count so both sides fail together
+		if undoErr := UpdateOne(userCollection, bson.M{"_id": followerID}, bson.M{"$inc": bson.M{"followingCount": -delta}}); undoErr != nil {
+			log.Printf("Failed to roll back following count of user %s: %v", followerID.Hex(), undoErr)
+		}
+		return fmt.Errorf("failed to update follower count: %v", err)
+	}
+	return nil
+}
+
+// removeFollowsBetween removes the follows between two users in both directions and the feed items each got from the other
+func removeFollowsBetween(userID primitive.ObjectID, otherID primitive.ObjectID) error {
+	for _, pair := range [][2]primitive.ObjectID{{userID, otherID}, {otherID, userID}} {
+		followerID, targetID := pair[0], pair[1]
+
+		filter := bson.M{"followerId": followerID, "targetType": models.FollowTargetUser, "targetId": targetID}
+		deleted, err := DeleteMany(followCollection, filter)
+		if err != nil {
+			return fmt.Errorf("failed to unfollow: %v", err)
+		}
+		if deleted > 0 {
+			if err := updateFollowCounts(followerID, userCollection, targetID, -1); err != nil {
+				return err
+			}
+		}
+
+		removeFeedItemsFrom(followerID, targetID)
+	}
+
+	return nil
+}
+
+// deleteFollowsOfPlace removes the follows of a deleted place and gives its followers their count back
+func deleteFollowsOfPlace(placeID primitive.ObjectID) error {
+	filter := bson.M{"targetType": models.FollowTargetPlace, "targetId": placeID}
+	var follows []models.Follow
+	if err := FindMany(followCollection, filter, &follows); err != nil {
+		return fmt.Errorf("failed to list follows: %v", err)
+	}
+
+	followerIDs := make([]primitive.ObjectID, 0, len(follows))
+	for _, follow := range follows {
+		followerIDs = append(followerIDs, follow.FollowerID)
+	}
+
+	if _, err := DeleteMany(followCollection, filter); err != nil {
+		return fmt.Errorf("failed to delete follows: %v", err)
+	}
+	if len(followerIDs) > 0 {
+		if _, err := UpdateMany(userCollection, bson.M{"_id": bson.M{"$in": followerIDs}}, bson.M{"$inc": bson.M{"followingCount": -1}}); err != nil {
+			return fmt.Errorf("failed to update following counts: %v", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"playtime-go/db"
+	"playtime-go/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const healthCollection = "health_records"
+
+// CreateHealthRecord adds a health record to a pet the user can care for
+func CreateHealthRecord(petID primitive.ObjectID, userID primitive.ObjectID, request models.HealthRecordRequest) (*models.HealthRecord, error) {
+	if _, err := AuthorizePet(petID, userID, PetAccessCare); err != nil {
+		return nil, err
+	}
+
+	attachments, err := healthAttachments(userID, request.Attachments, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record := models.HealthRecord{
+		PetID:       petID,
+		Type:        request.Type,
+		Date:        request.Date,
+		VaccineName: request.VaccineName,
+		Product:     request.Product,
+		Clinic:      request.Clinic,
+		Medication:  request.Medication,
+		Dosage:      request.Dosage,
+		EndDate:     request.EndDate,
+		NextDueDate: request.NextDueDate,
+		Notes:       request.Notes,
+		Attachments: attachments,
+		CreatedBy:   userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	id, err := InsertOne(healthCollection, record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create health record: %v", err)
+	}
+
+	record.ID = id
+	return &record, nil
+}
+
+// GetHealthRecord retrieves a single health record of a pet the user belongs to
+func GetHealthRecord(petID primitive.ObjectID, id primitive.ObjectID, userID primitive.ObjectID) (*models.HealthRecord, error) {
+	if _, err := AuthorizePet(petID, userID, PetAccessView); err != nil {
+		return nil, err
+	}
+
+	return getHealthRecord(petID, id)
+}
+
+// getHealthRecord retrieves a health record without checking ownership
+func getHealthRecord(petID primitive.ObjectID, id primitive.ObjectID) (*models.HealthRecord, error) {
+	filter := bson.M{"_id": id, "petId": petID}
+	var record models.HealthRecord
+
+	err := FindOne(healthCollection, filter, &record)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no health record found with ID: %s", id.Hex())
+		}
+		return nil, fmt.Errorf("failed to get health record by ID: %v", err)
+	}
+
+	return &record, nil
+}
+
+// ListHealthRecords retrieves a pet's health records, optionally filtered by type
+func ListHealthRecords(petID primitive.ObjectID, userID primitive.ObjectID, recordType string) ([]models.HealthRecord, error) {
+	if _, err := AuthorizePet(petID, userID, PetAccessView); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"petId": petID}
+	if recordType != "" {
+		filter["type"] = recordType
+	}
+
+	var records []models.HealthRecord
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "date", Value: -1}})
+
+	err := FindMany(healthCollection, filter, &records, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list health records: %v", err)
+	}
+
+	return records, nil
+}
+
+// UpdateHealthRecord updates a health record of a pet the user can care for
+func UpdateHealthRecord(petID primitive.ObjectID, id primitive.ObjectID, userID primitive.ObjectID, request models.HealthRecordRequest) (*models.HealthRecord, error) {
+	if _, err := AuthorizePet(petID, userID, PetAccessCare); err != nil {
+		return nil, err
+	}
+	existing, err := getHealthRecord(petID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := bson.M{
+		"type":        request.Type,
+		"date":        request.Date,
+		"vaccineName": request.VaccineName,
+		"product":     request.Product,
+		"clinic":      request.Clinic,
+		"medication":  request.Medication,
+		"dosage":      request.Dosage,
+		"endDate":     request.EndDate,
+		"nextDueDate": request.NextDueDate,
+		"notes":       request.Notes,
+		"updatedAt":   time.Now(),
+	}
+
+	// Attachments are only replaced when the request lists them
+	if request.Attachments != nil {
+		attachments, err := healthAttachments(userID, request.Attachments, existing.Attachments)
+		if err != nil {
+			return nil, err
+		}
+		fields["attachments"] = attachments
+	}
+
+	filter := bson.M{"_id": id, "petId": petID}
+	if err := UpdateOne(healthCollection, filter, bson.M{"$set": fields}); err != nil {
+		return nil, fmt.Errorf("failed to update health record: %v", err)
+	}
+
+	return getHealthRecord(petID, id)
+}
+
+// DeleteHealthRecord deletes a health record of a pet the user can care for
+func DeleteHealthRecord(petID primitive.ObjectID, id primitive.ObjectID, userID primitive.ObjectID) error {
+	if _, err := AuthorizePet(petID, userID, PetAccessCare); err != nil {
+		return err
+	}
+	if _, err := getHealthRecord(petID, id); err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": id, "petId": petID}
+	if err := DeleteOne(healthCollection, filter); err != nil {
+		return fmt.Errorf("failed to delete health record: %v", err)
+	}
+
+	return nil
+}
+
+// AddHealthRecordAttachment uploads a file to COS and attaches it to a health record
+func AddHealthRecordAttachment(petID primitive.ObjectID, id primitive.ObjectID, userID primitive.ObjectID, fileReader io.Reader, filename string, contentType string, size int64) (*models.HealthRecord, error) {
+	if _, err := AuthorizePet(petID, userID, PetAccessCare); err != nil {
+		return nil, err
+	}
+	if _, err := getHealthRecord(petID, id); err != nil {
+		return nil, err
+	}
+
+	upload, err := UploadFileToCOS(fileReader, filename, contentType, PetObjectPrefix(petID, "health"))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := RecordUpload(userID, upload, contentType, size); err != nil {
+		return nil, err
+	}
+
+	updateData := bson.M{
+		"$push": bson.M{"attachments": upload.URL},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
+
+	filter := bson.M{"_id": id, "petId": petID}
+	if err := UpdateOne(healthCollection, filter, updateData); err != nil {
+		return nil, fmt.Errorf("failed to add attachment: %v", err)
+	}
+
+	return getHealthRecord(petID, id)
+}
+
+// healthAttachments validates the attachment URLs sent with a health record.
+// Attachments the record already has are kept, new ones must be files the user uploaded.
+func healthAttachments(userID primitive.ObjectID, urls []string, existing []string) ([]string, error) {
+	known := make(map[string]bool, len(existing))
+	for _, url := range existing {
+		known[url] = true
+	}
+
+	attachments := make([]string, 0, len(urls))
+	keys := make(map[string]string, len(urls))
+	var newKeys []string
+	for _, url := range urls {
+		if known[url] {
+			attachments = append(attachments, url)
+			continue
+		}
+
+		key, err := cosKeyFromURL(url)
+		if err != nil {
+			return nil, fmt.Errorf("invalid attachment: %s is not an uploaded file", url)
+		}
+		keys[url] = key
+		newKeys = append(newKeys, key)
+		attachments = append(attachments, url)
+	}
+
+	owned, err := ownedUploadKeys(userID, newKeys)
+	if err != nil {
+		return nil, err
+	}
+	for url, key := range keys {
+		if !owned[key] {
+			return nil, fmt.Errorf("invalid attachment: %s is not an uploaded file", url)
+		}
+	}
+
+	return attachments, nil
+}
+
+// EnsureHealthRecordIndexes creates the indexes used to list and schedule health records
+func EnsureHealthRecordIndexes() error {
+	collection := db.GetCollection(healthCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "petId", Value: 1}, {Key: "date", Value: -1}},
+			Options: options.Index().SetName("petId_date"),
+		},
+		{
+			Keys:    bson.D{{Key: "nextDueDate", Value: 1}},
+			Options: options.Index().SetName("nextDueDate").SetSparse(true),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexModels)
+	if err != nil {
+		return fmt.Errorf("failed to create health record indexes: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"playtime-go/db"
+	"playtime-go/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultHomeAreaRadius = 3000  // meters
+	maxHomeAreaRadius     = 20000 // meters
+	homeAreaPrecision     = 1000  // coordinates are rounded to 3 decimals, roughly 100 meters
+	earthRadiusMeters     = 6378100
+)
+
+// SetUserHomeArea stores a user's approximate home area. Coordinates are rounded before
+// they are stored so the exact address is never kept.
+func SetUserHomeArea(userID primitive.ObjectID, request models.UserHomeAreaRequest) (*models.UserHomeAreaResponse, error) {
+	if _, err := GetUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	radius := request.RadiusMeters
+	if radius <= 0 {
+		radius = defaultHomeAreaRadius
+	}
+	radius = math.Min(radius, maxHomeAreaRadius)
+
+	now := time.Now()
+	homeArea := models.UserHomeArea{
+		Location: models.GeoLocation{
+			Type:        "Point",
+			Coordinates: []float64{roundCoordinate(request.Longitude), roundCoordinate(request.Latitude)},
+		},
+		RadiusMeters:     radius,
+		LostPetAlerts:    request.LostPetAlerts,
+		PlaymateMatching: request.PlaymateMatching,
+		UpdatedAt:        now,
+	}
+
+	update := bson.M{"$set": bson.M{"homeArea": homeArea, "updatedAt": now}}
+	if err := UpdateOne(userCollection, bson.M{"_id": userID}, update); err != nil {
+		return nil, fmt.Errorf("failed to set home area: %v", err)
+	}
+
+	return homeAreaResponse(&homeArea), nil
+}
+
+// GetUserHomeArea retrieves a user's home area
+func GetUserHomeArea(userID primitive.ObjectID) (*models.UserHomeAreaResponse, error) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.HomeArea == nil {
+		return nil, fmt.Errorf("no home area found for user: %s", userID.Hex())
+	}
+
+	return homeAreaResponse(user.HomeArea), nil
+}
+
+// ClearUserHomeArea removes a user's home area and opts them out of nearby features
+func ClearUserHomeArea(userID primitive.ObjectID) error {
+	if _, err := GetUserByID(userID); err != nil {
+		return err
+	}
+
+	update := bson.M{"$unset": bson.M{"homeArea": ""}, "$set": bson.M{"updatedAt": time.Now()}}
+	if err := UpdateOne(userCollection, bson.M{"_id": userID}, update); err != nil {
+		return fmt.Errorf("failed to clear home area: %v", err)
+	}
+
+	return nil
+}
+
+// findUsersNearPoint returns users whose home area is within radius meters of the given point
+// and who match the extra filter
+func findUsersNearPoint(longitude float64, latitude float64, radius float64, extra bson.M) ([]models.User, error) {
+	filter := bson.M{
+		"homeArea.location": bson.M{"$geoWithin": bson.M{
+			"$centerSphere": []interface{}{[]float64{longitude, latitude}, radius / earthRadiusMeters},
+		}},
+	}
+	for key, value := range extra {
+		filter[key] = value
+	}
+
+	var users []models.User
+	if err := FindMany(userCollection, filter, &users); err != nil {
+		return nil, fmt.Errorf("failed to find nearby users: %v", err)
+	}
+
+	return users, nil
+}
+
+// homeAreaResponse converts a stored home area into its API response
+func homeAreaResponse(homeArea *models.UserHomeArea) *models.UserHomeAreaResponse {
+	response := &models.UserHomeAreaResponse{
+		RadiusMeters:     homeArea.RadiusMeters,
+		LostPetAlerts:    homeArea.LostPetAlerts,
+		PlaymateMatching: homeArea.PlaymateMatching,
+		UpdatedAt:        homeArea.UpdatedAt,
+	}
+	if len(homeArea.Location.Coordinates) == 2 {
+		response.Longitude = homeArea.Location.Coordinates[0]
+		response.Latitude = homeArea.Location.Coordinates[1]
+	}
+	return response
+}
+
+// roundCoordinate rounds a coordinate to the precision home areas are stored with
+func roundCoordinate(value float64) float64 {
+	return math.Round(value*homeAreaPrecision) / homeAreaPrecision
+}
+
+// distanceMeters returns the great-circle distance between two points in meters
+func distanceMeters(lng1 float64, lat1 float64, lng2 float64, lat2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
+
+// EnsureUserIndexes creates the geospatial index on user home areas and the index finding who blocked a user
+func EnsureUserIndexes() error {
+	collection := db.GetCollection(userCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	indexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "homeArea.location", Value: "2dsphere"}},
+		Options: options.Index().
+			SetName("homeArea_location_2dsphere").
+			SetSparse(true),
+	}
+
+	_, err := collection.Indexes().CreateOne(ctx, indexModel)
+	if err != nil {
+		return fmt.Errorf("failed to create user home area index: %v", err)
+	}
+
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "blockedUserIds", Value: 1}},
+		Options: options.Index().SetName("blockedUserIds"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create user block list index: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,443 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"playtime-go/db"
+	"playtime-go/models"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	lostReportCollection = "lost_reports"
+	sightingCollection   = "found_sightings"
+	lostSearchRadius     = 5000  // meters
+	maxLostSearchRadius  = 50000 // meters
+	sightingMatchRadius  = 5000  // meters
+	sightingMatchLimit   = 10
+)
+
+// lostReportWithDistance decodes a $geoNear result over lost pet reports
+type lostReportWithDistance struct {
+	models.LostPetReport `bson:",inline"`
+	Distance             float64 `bson:"distance"`
+}
+
+// CreateLostPetReport opens a lost pet alert for a pet the user cares for and notifies nearby users
+func CreateLostPetReport(userID primitive.ObjectID, request models.LostPetReportRequest) (*models.LostPetReport, error) {
+	pet, err := AuthorizePet(request.PetID, userID, PetAccessCare)
+	if err != nil {
+		return nil, err
+	}
+
+	// A pet can only have one open alert at a time
+	open, err := Count(lostReportCollection, bson.M{"petId": pet.ID, "status": models.LostReportOpen})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check open reports: %v", err)
+	}
+	if open > 0 {
+		return nil, fmt.Errorf("pet already has an open lost report: %s", pet.ID.Hex())
+	}
+
+	photos := request.Photos
+	if len(photos) == 0 {
+		photos = []string{}
+		if pet.CoverPhoto != "" {
+			photos = append(photos, pet.CoverPhoto)
+		} else if pet.Avatar != "" {
+			photos = append(photos, pet.Avatar)
+		}
+	}
+
+	now := time.Now()
+	lastSeenAt := request.LastSeenAt
+	if lastSeenAt.IsZero() {
+		lastSeenAt = now
+	}
+
+	report := models.LostPetReport{
+		PetID:      pet.ID,
+		ReporterID: userID,
+		PetName:    pet.Name,
+		Species:    pet.Species,
+		Breed:      pet.Breed,
+		BreedID:    pet.BreedID,
+		Location: models.GeoLocation{
+			Type:        "Point",
+			Coordinates: []float64{request.Longitude, request.Latitude},
+		},
+		LastSeenAt:        lastSeenAt,
+		Description:       request.Description,
+		Photos:            photos,
+		ContactPreference: request.ContactPreference,
+		Status:            models.LostReportOpen,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	// The phone number is shown to anyone who finds the report, so it is only kept when it is the way to get in touch
+	if request.ContactPreference == models.ContactPreferencePhone {
+		report.ContactPhone = request.ContactPhone
+	}
+
+	id, err := InsertOne(lostReportCollection, report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lost report: %v", err)
+	}
+	report.ID = id
+
+	// Broadcasting can take a while, so it runs after the response is sent
+	go func() {
+		notified, err := broadcastLostPetReport(report, pet)
+		if err != nil {
+			log.Printf("Failed to broadcast lost report %s: %v", report.ID.Hex(), err)
+		}
+		if notified > 0 {
+			update := bson.M{"$inc": bson.M{"notifiedCount": notified}}
+			if err := UpdateOne(lostReportCollection, bson.M{"_id": report.ID}, update); err != nil {
+				log.Printf("Failed to update notified count of lost report %s: %v", report.ID.Hex(), err)
+			}
+		}
+	}()
+
+	return &report, nil
+}
+
+// GetLostPetReport retrieves a lost pet report by its ID
+func GetLostPetReport(id primitive.ObjectID) (*models.LostPetReport, error) {
+	filter := bson.M{"_id": id}
+	var report models.LostPetReport
+
+	if err := FindOne(lostReportCollection, filter, &report); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no lost report found with ID: %s", id.Hex())
+		}
+		return nil, fmt.Errorf("failed to get lost report by ID: %v", err)
+	}
+
+	hideLostReportContact(&report)
+	return &report, nil
+}
+
+// hideLostReportContact clears the phone number of reports whose owner prefers another way of contact
+func hideLostReportContact(report *models.LostPetReport) {
+	if report.ContactPreference != models.ContactPreferencePhone {
+		report.ContactPhone = ""
+	}
+}
+
+// SearchLostPetReports lists open lost pet reports near a point, closest first
+func SearchLostPetReports(search models.LostPetSearchRequest) ([]models.LostPetSearchResult, error) {
+	radius := search.Radius
+	if radius <= 0 {
+		radius = lostSearchRadius
+	}
+	radius = math.Min(radius, maxLostSearchRadius)
+
+	limit := search.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	query := bson.M{"status": models.LostReportOpen}
+	if search.Species != "" {
+		query["species"] = search.Species
+	}
+
+	reports, err := findLostReportsNear(search.Longitude, search.Latitude, radius, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.LostPetSearchResult, 0, len(reports))
+	for _, report := range reports {
+		results = append(results, models.LostPetSearchResult{Report: report.LostPetReport, Distance: report.Distance})
+	}
+
+	return results, nil
+}
+
+// ResolveLostPetReport closes an open lost pet report
+func ResolveLostPetReport(id primitive.ObjectID, userID primitive.ObjectID, request models.LostPetResolveRequest) (*models.LostPetReport, error) {
+	report, err := GetLostPetReport(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The reporter may close their own report even if they have since lost access to the pet
+	if report.ReporterID != userID {
+		if _, err := AuthorizePet(report.PetID, userID, PetAccessCare); err != nil {
+			return nil, err
+		}
+	}
+
+	if report.Status != models.LostReportOpen {
+		return nil, fmt.Errorf("lost report is already resolved: %s", id.Hex())
+	}
+
+	now := time.Now()
+	update := bson.M{"$set": bson.M{
+		"status":     models.LostReportResolved,
+		"resolution": request.Resolution,
+		"resolvedAt": now,
+		"updatedAt":  now,
+	}}
+	if err := UpdateOne(lostReportCollection, bson.M{"_id": id, "status": models.LostReportOpen}, update); err != nil {
+		return nil, fmt.Errorf("failed to resolve lost report: %v", err)
+	}
+
+	return GetLostPetReport(id)
+}
+
+// CreateFoundPetSighting records a found pet and matches it against open lost reports
+// by distance, species and breed. Caretakers of matching pets are notified.
+func CreateFoundPetSighting(userID primitive.ObjectID, request models.FoundPetSightingRequest) (*models.FoundPetSightingResponse, error) {
+	now := time.Now()
+	seenAt := request.SeenAt
+	if seenAt.IsZero() {
+		seenAt = now
+	}
+
+	photos := request.Photos
+	if photos == nil {
+		photos = []string{}
+	}
+
+	sighting := models.FoundPetSighting{
+		ReporterID: userID,
+		Species:    request.Species,
+		Breed:      strings.TrimSpace(request.Breed),
+		Location: models.GeoLocation{
+			Type:        "Point",
+			Coordinates: []float64{request.Longitude, request.Latitude},
+		},
+		SeenAt:      seenAt,
+		Description: request.Description,
+		Photos:      photos,
+		CreatedAt:   now,
+	}
+
+	breed, err := FindBreedByName(sighting.Breed)
+	if err != nil {
+		return nil, err
+	}
+	if breed != nil {
+		sighting.Breed = breed.NameZh
+		sighting.BreedID = breed.ID
+	}
+
+	id, err := InsertOne(sightingCollection, sighting)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sighting: %v", err)
+	}
+	sighting.ID = id
+
+	matches, err := matchSightingToReports(sighting)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, match := range matches {
+		notifyLostPetMatch(match.Report, sighting)
+	}
+
+	return &models.FoundPetSightingResponse{Sighting: sighting, Matches: matches}, nil
+}
+
+// matchSightingToReports finds open reports of the same species near a sighting. Reports of the
+// same breed rank higher, as do closer ones. Pets last seen after the sighting are skipped.
+func matchSightingToReports(sighting models.FoundPetSighting) ([]models.LostPetMatch, error) {
+	query := bson.M{
+		"status":     models.LostReportOpen,
+		"lastSeenAt": bson.M{"$lte": sighting.SeenAt},
+	}
+	if sighting.Species != "" {
+		query["species"] = bson.M{"$in": []string{sighting.Species, ""}}
+	}
+
+	coordinates := sighting.Location.Coordinates
+	reports, err := findLostReportsNear(coordinates[0], coordinates[1], sightingMatchRadius, query, 50)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]models.LostPetMatch, 0, len(reports))
+	for _, report := range reports {
+		breedMatch := false
+		switch {
+		case !report.BreedID.IsZero() && !sighting.BreedID.IsZero():
+			breedMatch = report.BreedID == sighting.BreedID
+		case report.Breed != "" && sighting.Breed != "":
+			breedMatch = strings.EqualFold(report.Breed, sighting.Breed)
+		}
+
+		// A known different breed is a strong sign it is another animal
+		if !breedMatch && report.Breed != "" && sighting.Breed != "" {
+			continue
+		}
+
+		score := 0.6 * (1 - report.Distance/sightingMatchRadius)
+		if breedMatch {
+			score += 0.4
+		}
+
+		matches = append(matches, models.LostPetMatch{
+			Report:     report.LostPetReport,
+			Distance:   report.Distance,
+			BreedMatch: breedMatch,
+			Score:      math.Round(score*100) / 100,
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if len(matches) > sightingMatchLimit {
+		matches = matches[:sightingMatchLimit]
+	}
+
+	return matches, nil
+}
+
+// findLostReportsNear runs a $geoNear query over lost pet reports
+func findLostReportsNear(longitude float64, latitude float64, radius float64, query bson.M, limit int64) ([]lostReportWithDistance, error) {
+	collection := db.GetCollection(lostReportCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	pipeline := []bson.D{
+		{{Key: "$geoNear", Value: bson.D{
+			{Key: "near", Value: bson.D{
+				{Key: "type", Value: "Point"},
+				{Key: "coordinates", Value: []float64{longitude, latitude}},
+			}},
+			{Key: "distanceField", Value: "distance"},
+			{Key: "maxDistance", Value: radius},
+			{Key: "query", Value: query},
+			{Key: "spherical", Value: true},
+		}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search lost reports: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var reports []lostReportWithDistance
+	if err := cursor.All(ctx, &reports); err != nil {
+		return nil, fmt.Errorf("failed to decode lost reports: %v", err)
+	}
+
+	for i := range reports {
+		hideLostReportContact(&reports[i].LostPetReport)
+	}
+	return reports, nil
+}
+
+// broadcastLostPetReport notifies users who opted into lost pet alerts and whose home area
+// covers the last-seen location. Members of the pet are skipped.
+func broadcastLostPetReport(report models.LostPetReport, pet *models.Pet) (int, error) {
+	longitude, latitude := report.Location.Coordinates[0], report.Location.Coordinates[1]
+	users, err := findUsersNearPoint(longitude, latitude, maxHomeAreaRadius, bson.M{"homeArea.lostPetAlerts": true})
+	if err != nil {
+		return 0, err
+	}
+
+	notified := 0
+	for _, user := range users {
+		if findActivePetMember(pet, user.ID, time.Now()) != nil {
+			continue
+		}
+
+		home := user.HomeArea.Location.Coordinates
+		if len(home) != 2 {
+			continue
+		}
+		distance := distanceMeters(longitude, latitude, home[0], home[1])
+		if distance > user.HomeArea.RadiusMeters {
+			continue
+		}
+
+		PublishUserEvent(user.ID, models.PushEventLostPet, models.LostPetEvent{
+			ReportID:       report.ID,
+			PetName:        report.PetName,
+			Species:        report.Species,
+			Breed:          report.Breed,
+			LastSeenAt:     report.LastSeenAt,
+			DistanceMeters: distance,
+		})
+
+		sent, err := sendLostPetMessage(user.ID, report, "Lost pet near you")
+		if err != nil {
+			log.Printf("Failed to send lost pet alert to user %s: %v", user.ID.Hex(), err)
+			continue
+		}
+		if sent {
+			notified++
+		}
+	}
+
+	return notified, nil
+}
+
+// notifyLostPetMatch tells the caretakers of a lost pet that a matching sighting was reported
+func notifyLostPetMatch(report models.LostPetReport, sighting models.FoundPetSighting) {
+	pet, err := GetPetByID(report.PetID)
+	if err != nil {
+		log.Printf("Failed to get pet %s for sighting %s: %v", report.PetID.Hex(), sighting.ID.Hex(), err)
+		return
+	}
+
+	for _, userID := range petCaretakers(pet, time.Now()) {
+		if _, err := sendLostPetMessage(userID, report, "Possible sighting reported"); err != nil {
+			log.Printf("Failed to send sighting alert to user %s: %v", userID.Hex(), err)
+		}
+	}
+}
+
+// sendLostPetMessage sends a lost pet subscribe message about a report.
+// It reports whether a message was sent.
+func sendLostPetMessage(userID primitive.ObjectID, report models.LostPetReport, note string) (bool, error) {
+	page := fmt.Sprintf("pages/lost/detail?id=%s", report.ID.Hex())
+	return sendUserSubscribeMessage(userID, models.ReminderKindLostPet, page,
+		report.PetName, report.Breed, report.LastSeenAt.Format("2006-01-02 15:04"), note)
+}
+
+// EnsureLostPetIndexes creates the geospatial indexes for lost reports and sightings
+func EnsureLostPetIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := db.GetCollection(lostReportCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "location", Value: "2dsphere"}},
+		Options: options.Index().
+			SetName("location_2dsphere").
+			SetBackground(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create lost report index: %v", err)
+	}
+
+	_, err = db.GetCollection(sightingCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "location", Value: "2dsphere"}},
+		Options: options.Index().
+			SetName("location_2dsphere").
+			SetBackground(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create sighting index: %v", err)
+	}
+
+	return nil
+}
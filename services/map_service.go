@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"net/url"
 	"playtime-go/config"
@@ -27,6 +28,10 @@ func CreateLocation(request models.LocationRequest) (*models.LocationResponse, e
 		return nil, fmt.Errorf("invalid coordinates: latitude and longitude must be provided")
 	}
 
+	if err := ensureUserNotBanned(request.CreatedBy); err != nil {
+		return nil, err
+	}
+
 	// Create new location with GeoJSON point for MongoDB geospatial queries
 	now := time.Now()
 
@@ -51,10 +56,14 @@ func CreateLocation(request models.LocationRequest) (*models.LocationResponse, e
 			Zone:             request.Zone,
 			AddressComponent: request.AddressComponent,
 			AdInfo:           request.AdInfo,
+			CreatedBy:        request.CreatedBy,
 		},
 		Location:  geoLocation,
 		CreatedAt: now,
 		UpdatedAt: now,
+
+		// Hold a flagged name or description for a moderator
+		ModerationStatus: checkUserText(request.CreatedBy, request.Name, request.Description),
 	}
 
 	// Insert location into database
@@ -106,6 +115,20 @@ func GetLocationByID(id primitive.ObjectID) (*models.LocationResponse, error) {
 	return result, nil
 }
 
+// GetVisibleLocationByID retrieves a location for a viewer, a place held for moderation is only found by its creator and moderators
+func GetVisibleLocationByID(id primitive.ObjectID, viewerID primitive.ObjectID) (*models.LocationResponse, error) {
+	location, err := GetLocationByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if isHiddenModerationStatus(location.ModerationStatus) && (viewerID.IsZero() || (location.CreatedBy != viewerID && !IsModerator(viewerID))) {
+		return nil, fmt.Errorf("no location found with ID: %s", id.Hex())
+	}
+
+	return location, nil
+}
+
 func ConvertLocationToResponse(location models.Location) (*models.LocationResponse, error) {
 	if location.Location.Type == "" {
 		return nil, fmt.Errorf("invalid location: missing GeoJSON type")
@@ -125,6 +148,11 @@ func ConvertLocationToResponse(location models.Location) (*models.LocationRespon
 		BaseLocation: location.BaseLocation,
 		Latitude:     latitude,
 		Longitude:    longitude,
+		ManagerID:    location.ManagerID,
+		Verified:     location.ManagerID != nil,
+
+		ModerationStatus: location.ModerationStatus,
+		Followers:        location.Followers,
 	}
 
 	fmt.Printf("Converted location: %+v\n", response)
@@ -156,6 +184,7 @@ func UpdateLocation(id primitive.ObjectID, request models.LocationRequest) (*mod
 			"adInfo":           existing.AdInfo,
 			"category":         request.Zone,
 			"location":         geoLocation,
+			"moderationStatus": moderationStatusAfterEdit(existing.ModerationStatus, checkUserText(existing.CreatedBy, request.Name, request.Description)),
 			"updatedAt":        now,
 		},
 	}
@@ -190,13 +219,73 @@ func DeleteLocation(id primitive.ObjectID) error {
 		return fmt.Errorf("failed to delete location: %v", err)
 	}
 
+	// Keep the place in users' lists as a tombstone rather than dropping it silently
+	if err := tombstonePlaceInLists(id); err != nil {
+		log.Printf("Failed to tombstone location %s in lists: %v", id.Hex(), err)
+	}
+
+	// Nobody can follow a deleted place
+	if err := deleteFollowsOfPlace(id); err != nil {
+		log.Printf("Failed to delete follows of location %s: %v", id.Hex(), err)
+	}
+
+	return nil
+}
+
+// SetPlaceManager makes the user the verified manager of a place that has no manager yet
+func SetPlaceManager(id primitive.ObjectID, managerID primitive.ObjectID) error {
+	// Check if location exists
+	if _, err := GetLocationByID(id); err != nil {
+		return err
+	}
+
+	collection := db.GetCollection(locationCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	now := time.Now()
+	filter := bson.M{"_id": id, "managerId": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"managerId": managerID, "managedSince": now, "updatedAt": now}}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to set place manager: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("place already has a manager: %s", id.Hex())
+	}
+
 	return nil
 }
 
+// IsPlaceManager reports whether the user is the verified manager of a place
+func IsPlaceManager(id primitive.ObjectID, userID primitive.ObjectID) (bool, error) {
+	count, err := Count(locationCollection, bson.M{"_id": id, "managerId": userID})
+	if err != nil {
+		return false, fmt.Errorf("failed to check place manager: %v", err)
+	}
+
+	return count > 0, nil
+}
+
+// AuthorizePlaceEdit checks that the user may edit a place.
+// Unclaimed places stay open to everyone, a claimed place only to its manager and moderators.
+func AuthorizePlaceEdit(id primitive.ObjectID, userID primitive.ObjectID) error {
+	location, err := GetLocationByID(id)
+	if err != nil {
+		return err
+	}
+
+	if location.ManagerID == nil || *location.ManagerID == userID || IsModerator(userID) {
+		return nil
+	}
+
+	return fmt.Errorf("not authorized to edit place: %s", id.Hex())
+}
+
 // ListLocations retrieves all locations with optional filtering
 func ListLocations(category string, limit int64) ([]models.LocationResponse, error) {
-	// Prepare filter
-	filter := bson.M{}
+	// Prepare filter, places held for moderation are not listed
+	filter := bson.M{"moderationStatus": VisibleContentFilter()}
 	if category != "" {
 		filter["category"] = category
 	}
@@ -277,8 +366,8 @@ func SearchNearbyLocations(search models.SearchRequest) ([]models.SearchResult,
 		}},
 	}
 
-	// Initialize pipeline with geoNear stage
-	pipeline := []bson.D{geoNearStage}
+	// Initialize pipeline with geoNear stage, leaving out places held for moderation
+	pipeline := []bson.D{geoNearStage, {{Key: "$match", Value: bson.M{"moderationStatus": VisibleContentFilter()}}}}
 
 	// Add filtering by keyword if provided
 	if search.Keyword != "" {
@@ -440,3 +529,29 @@ func ReverseGeocode(lat string, lng string) (interface{}, error) {
 	// Return the result
 	return geocodeResponse.Result, nil
 }
+
+// ListLocationsByCreator retrieves all locations created by a user
+func ListLocationsByCreator(userID primitive.ObjectID) ([]models.LocationResponse, error) {
+	filter := bson.M{"createdBy": userID}
+	var locations []models.Location
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	err := FindMany(locationCollection, filter, &locations, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list locations by creator: %v", err)
+	}
+
+	responses := make([]models.LocationResponse, 0, len(locations))
+	for _, location := range locations {
+		response, err := ConvertLocationToResponse(location)
+		if err != nil {
+			log.Printf("Failed to convert location %s: %v", location.ID.Hex(), err)
+			continue
+		}
+		responses = append(responses, *response)
+	}
+
+	return responses, nil
+}
@@ -0,0 +1,495 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"playtime-go/db"
+	"playtime-go/models"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	conversationCollection = "conversations"
+	messageCollection      = "messages"
+	messageRateCollection  = "message_rates"
+
+	// maxMessageText is the longest text message in characters
+	maxMessageText = 2000
+
+	// MessageUploadPrefix is the COS key prefix message images are uploaded under
+	MessageUploadPrefix = "message"
+
+	// Rate limit, a sender may send at most messageRateLimit messages per messageRateWindow
+	messageRateLimit  = 20
+	messageRateWindow = time.Minute
+)
+
+// OpenConversation returns the user's conversation with another user, creating it on first contact
+func OpenConversation(userID primitive.ObjectID, otherID primitive.ObjectID) (*models.ConversationResponse, error) {
+	if userID == otherID {
+		return nil, fmt.Errorf("invalid message: cannot message yourself")
+	}
+	if _, err := GetUserByID(otherID); err != nil {
+		return nil, err
+	}
+	if err := ensureCanMessage(userID, otherID); err != nil {
+		return nil, err
+	}
+
+	first, second := userID, otherID
+	if first.Hex() > second.Hex() {
+		first, second = second, first
+	}
+
+	now := time.Now()
+	filter := bson.M{"participantKey": first.Hex() + ":" + second.Hex()}
+	update := bson.M{"$setOnInsert": bson.M{
+		"participantIds": []primitive.ObjectID{first, second},
+		"lastMessageAt":  now,
+		"createdAt":      now,
+	}}
+	if err := UpsertOne(conversationCollection, filter, update); err != nil && !mongo.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf("failed to open conversation: %v", err)
+	}
+
+	var conversation models.Conversation
+	if err := FindOne(conversationCollection, filter, &conversation); err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %v", err)
+	}
+
+	return conversationResponse(conversation, userID), nil
+}
+
+// ListConversations lists the user's conversations, most recently active first
+func ListConversations(userID primitive.ObjectID, limit int64) ([]models.ConversationResponse, error) {
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "lastMessageAt", Value: -1}})
+	if limit > 0 {
+		findOptions.SetLimit(limit)
+	} else {
+		findOptions.SetLimit(50) // Default limit
+	}
+
+	var conversations []models.Conversation
+	if err := FindMany(conversationCollection, bson.M{"participantIds": userID}, &conversations, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %v", err)
+	}
+
+	responses := make([]models.ConversationResponse, 0, len(conversations))
+	for _, conversation := range conversations {
+		responses = append(responses, *conversationResponse(conversation, userID))
+	}
+	return responses, nil
+}
+
+// GetUnreadMessageCount returns the number of unread messages across all of the user's conversations
+func GetUnreadMessageCount(userID primitive.ObjectID) (int64, error) {
+	var conversations []models.Conversation
+	findOptions := options.Find().SetProjection(bson.M{"unread." + userID.Hex(): 1})
+	filter := bson.M{"participantIds": userID, "unread." + userID.Hex(): bson.M{"$gt": 0}}
+	if err := FindMany(conversationCollection, filter, &conversations, findOptions); err != nil {
+		return 0, fmt.Errorf("failed to count unread messages: %v", err)
+	}
+
+	var unread int64
+	for _, conversation := range conversations {
+		unread += conversation.UnreadCounts[userID.Hex()]
+	}
+	return unread, nil
+}
+
+// ListMessages pages through a conversation newest first, before is the ID of the oldest message already loaded
+func ListMessages(conversationID primitive.ObjectID, userID primitive.ObjectID, before string, limit int64) ([]models.Message, error) {
+	if _, err := getConversationForUser(conversationID, userID); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"conversationId": conversationID}
+	if before != "" {
+		beforeID, err := primitive.ObjectIDFromHex(before)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %s", before)
+		}
+		filter["_id"] = bson.M{"$lt": beforeID}
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "_id", Value: -1}})
+	if limit > 0 {
+		findOptions.SetLimit(limit)
+	} else {
+		findOptions.SetLimit(50) // Default limit
+	}
+
+	messages := []models.Message{}
+	if err := FindMany(messageCollection, filter, &messages, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list messages: %v", err)
+	}
+
+	return messages, nil
+}
+
+// SendMessage sends a message in a conversation and delivers it to the recipient's connected clients
+func SendMessage(conversationID primitive.ObjectID, senderID primitive.ObjectID, request models.MessageRequest) (*models.Message, error) {
+	conversation, err := getConversationForUser(conversationID, senderID)
+	if err != nil {
+		return nil, err
+	}
+	recipientID := otherParticipant(*conversation, senderID)
+
+	if err := ensureUserNotBanned(senderID); err != nil {
+		return nil, err
+	}
+	if err := ensureCanMessage(senderID, recipientID); err != nil {
+		return nil, err
+	}
+	if err := checkMessageRate(senderID); err != nil {
+		return nil, err
+	}
+
+	message, err := buildMessage(senderID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Text goes through the same content check as reviews, a direct message is refused rather than held
+	if message.Text != "" && checkUserText(senderID, message.Text) != models.ModerationApproved {
+		return nil, fmt.Errorf("invalid message: text did not pass the content check")
+	}
+	message.ConversationID = conversationID
+	message.SenderID = senderID
+	message.RecipientID = recipientID
+	message.CreatedAt = time.Now()
+
+	id, err := InsertOne(messageCollection, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send message: %v", err)
+	}
+	message.ID = id
+
+	// The sender has read everything up to their own message
+	update := bson.M{
+		"$set": bson.M{
+			"lastMessage":                message,
+			"lastMessageAt":              message.CreatedAt,
+			"unread." + senderID.Hex():   0,
+			"readUpTo." + senderID.Hex(): id,
+		},
+		"$inc": bson.M{"unread." + recipientID.Hex(): 1},
+	}
+	if err := UpdateOne(conversationCollection, bson.M{"_id": conversationID}, update); err != nil {
+		return nil, fmt.Errorf("failed to update conversation: %v", err)
+	}
+
+	event := models.MessageEvent{Type: models.MessageEventMessage, Message: message}
+	PublishUserEvent(recipientID, models.PushEventMessage, event)
+	PublishUserEvent(senderID, models.PushEventMessage, event) // The sender's other devices
+
+	return message, nil
+}
+
+// MarkConversationRead marks the conversation read up to its latest message and sends a read receipt
+func MarkConversationRead(conversationID primitive.ObjectID, userID primitive.ObjectID) (*models.ReadReceipt, error) {
+	conversation, err := getConversationForUser(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &models.ReadReceipt{ConversationID: conversationID, UserID: userID, ReadAt: time.Now()}
+	if conversation.LastMessage == nil {
+		return receipt, nil
+	}
+	receipt.ReadUpTo = conversation.LastMessage.ID
+
+	update := bson.M{"$set": bson.M{
+		"unread." + userID.Hex():   0,
+		"readUpTo." + userID.Hex(): receipt.ReadUpTo,
+	}}
+	if err := UpdateOne(conversationCollection, bson.M{"_id": conversationID}, update); err != nil {
+		return nil, fmt.Errorf("failed to mark conversation read: %v", err)
+	}
+
+	event := models.MessageEvent{Type: models.MessageEventRead, Read: receipt}
+	PublishUserEvent(otherParticipant(*conversation, userID), models.PushEventMessageRead, event)
+	PublishUserEvent(userID, models.PushEventMessageRead, event)
+
+	return receipt, nil
+}
+
+// PollMessages waits up to wait for messages to the user newer than after.
+// Messages are read from the database so polls also see messages sent through other instances.
+func PollMessages(ctx context.Context, userID primitive.ObjectID, after string, wait time.Duration) (*models.MessagePollResponse, error) {
+	filter := bson.M{"recipientId": userID}
+	if after != "" {
+		afterID, err := primitive.ObjectIDFromHex(after)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %s", after)
+		}
+		filter["_id"] = bson.M{"$gt": afterID}
+	} else {
+		// A first poll only returns what arrives from now on, the cutoff is the cursor of the next poll
+		cutoff := primitive.NewObjectIDFromTimestamp(time.Now())
+		filter["_id"] = bson.M{"$gt": cutoff}
+		after = cutoff.Hex()
+	}
+
+	// Subscribe before the first query so a message sent in between is not missed
+	events, unsubscribe, err := SubscribeUserEvents(userID, models.PushEventMessage, models.PushEventMessageRead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to message events: %v", err)
+	}
+	defer unsubscribe()
+
+	response := &models.MessagePollResponse{Events: []models.MessageEvent{}, Cursor: after}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		messages, err := findNewMessages(filter)
+		if err != nil {
+			return nil, err
+		}
+		for i := range messages {
+			response.Events = append(response.Events, models.MessageEvent{Type: models.MessageEventMessage, Message: &messages[i]})
+		}
+		if len(messages) > 0 {
+			response.Cursor = messages[len(messages)-1].ID.Hex()
+			return response, nil
+		}
+
+		select {
+		case pushed := <-events:
+			// Read receipts are only delivered live, messages are picked up by the next query
+			if pushed.Type != models.PushEventMessageRead {
+				continue
+			}
+			var event models.MessageEvent
+			if err := json.Unmarshal(pushed.Data, &event); err != nil {
+				return nil, fmt.Errorf("failed to decode read receipt: %v", err)
+			}
+			response.Events = append(response.Events, event)
+			return response, nil
+		case <-timer.C:
+			return response, nil
+		case <-ctx.Done():
+			return response, nil
+		}
+	}
+}
+
+// isMessageUploadKey reports whether a COS key is under the prefix message images are uploaded to
+func isMessageUploadKey(key string) bool {
+	return strings.HasPrefix(key, MessageUploadPrefix+"/") && path.Clean(key) == key
+}
+
+// findNewMessages returns the messages matching a poll filter, oldest first
+func findNewMessages(filter bson.M) ([]models.Message, error) {
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "_id", Value: 1}})
+	findOptions.SetLimit(100)
+
+	var messages []models.Message
+	if err := FindMany(messageCollection, filter, &messages, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to poll messages: %v", err)
+	}
+	return messages, nil
+}
+
+// buildMessage validates a message request and fills in the snapshot of a shared place or pet.
+// An image must be a message upload the sender made themselves.
+func buildMessage(senderID primitive.ObjectID, request models.MessageRequest) (*models.Message, error) {
+	message := &models.Message{Type: request.Type, Text: strings.TrimSpace(request.Text)}
+	if len([]rune(message.Text)) > maxMessageText {
+		return nil, fmt.Errorf("invalid message: text can be at most %d characters", maxMessageText)
+	}
+
+	switch request.Type {
+	case models.MessageTypeText:
+		if message.Text == "" {
+			return nil, fmt.Errorf("invalid message: text is required")
+		}
+	case models.MessageTypeImage:
+		key, err := cosKeyFromURL(request.ImageURL)
+		if err != nil || !isMessageUploadKey(key) {
+			return nil, fmt.Errorf("invalid message: image is not an uploaded file")
+		}
+		owned, err := ownedUploadKeys(senderID, []string{key})
+		if err != nil {
+			return nil, err
+		}
+		if !owned[key] {
+			return nil, fmt.Errorf("invalid message: image is not an uploaded file")
+		}
+		message.ImageURL = request.ImageURL
+	case models.MessageTypePlace:
+		if request.PlaceID == nil {
+			return nil, fmt.Errorf("invalid message: place ID is required")
+		}
+		place, err := GetLocationByID(*request.PlaceID)
+		if err != nil {
+			return nil, err
+		}
+		message.PlaceID = request.PlaceID
+		message.ShareName = place.Name
+		if len(place.Photos) > 0 {
+			message.ShareImageURL = place.Photos[0]
+		}
+	case models.MessageTypePet:
+		if request.PetID == nil {
+			return nil, fmt.Errorf("invalid message: pet ID is required")
+		}
+		pet, err := GetPetByID(*request.PetID)
+		if err != nil {
+			return nil, err
+		}
+		message.PetID = request.PetID
+		message.ShareName = pet.Name
+		message.ShareImageURL = pet.Avatar
+	default:
+		return nil, fmt.Errorf("invalid message: unknown type %q", request.Type)
+	}
+
+	return message, nil
+}
+
+// ensureCanMessage returns an error when either user has blocked the other
+func ensureCanMessage(senderID primitive.ObjectID, recipientID primitive.ObjectID) error {
+	if err := ensureNotBlockedBy(recipientID, senderID); err != nil {
+		return err
+	}
+	if err := ensureNotBlockedBy(senderID, recipientID); err != nil {
+		return fmt.Errorf("invalid message: unblock the user to message them")
+	}
+	return nil
+}
+
+// checkMessageRate takes one of the sender's messages in the rate window, returning an error when the window is used up.
+// The sender's last messageRateLimit send times are kept in one document, and a send is only recorded when the
+// oldest of them has left the window. Checking and recording is a single update, so concurrent sends cannot overshoot.
+func checkMessageRate(senderID primitive.ObjectID) error {
+	collection := db.GetCollection(messageRateCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	now := time.Now()
+	filter := bson.M{"_id": senderID, "$or": []bson.M{
+		{fmt.Sprintf("sentAt.%d", messageRateLimit-1): bson.M{"$exists": false}},
+		{"sentAt.0": bson.M{"$lte": now.Add(-messageRateWindow)}},
+	}}
+	update := bson.M{
+		"$push": bson.M{"sentAt": bson.M{"$each": []time.Time{now}, "$slice": -messageRateLimit}},
+		"$set":  bson.M{"updatedAt": now},
+	}
+
+	// A full window does not match, so the upsert collides with the sender's document.
+	// The first sends of a new sender can also collide with each other, so a collision is retried once.
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		_, err = collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+		if !mongo.IsDuplicateKeyError(err) {
+			break
+		}
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("rate limit exceeded: at most %d messages per minute", messageRateLimit)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check message rate: %v", err)
+	}
+	return nil
+}
+
+// getConversationForUser returns a conversation the user takes part in
+func getConversationForUser(conversationID primitive.ObjectID, userID primitive.ObjectID) (*models.Conversation, error) {
+	var conversation models.Conversation
+	if err := FindOne(conversationCollection, bson.M{"_id": conversationID, "participantIds": userID}, &conversation); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no conversation found with ID: %s", conversationID.Hex())
+		}
+		return nil, fmt.Errorf("failed to get conversation: %v", err)
+	}
+	return &conversation, nil
+}
+
+// otherParticipant returns the participant of a conversation who is not the user
+func otherParticipant(conversation models.Conversation, userID primitive.ObjectID) primitive.ObjectID {
+	for _, id := range conversation.ParticipantIDs {
+		if id != userID {
+			return id
+		}
+	}
+	return userID
+}
+
+// conversationResponse shapes a conversation for one participant
+func conversationResponse(conversation models.Conversation, userID primitive.ObjectID) *models.ConversationResponse {
+	otherID := otherParticipant(conversation, userID)
+	response := &models.ConversationResponse{
+		ID:            conversation.ID,
+		OtherUser:     models.ConversationUser{ID: otherID},
+		LastMessage:   conversation.LastMessage,
+		LastMessageAt: conversation.LastMessageAt,
+		UnreadCount:   conversation.UnreadCounts[userID.Hex()],
+	}
+	if readUpTo, ok := conversation.ReadUpTo[otherID.Hex()]; ok {
+		response.OtherReadUpTo = &readUpTo
+	}
+	if user, err := GetUserByID(otherID); err == nil {
+		response.OtherUser.NickName = user.NickName
+		response.OtherUser.AvatarURL = user.AvatarURL
+	}
+	return response
+}
+
+// EnsureMessageIndexes creates the conversation and message indexes
+func EnsureMessageIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := db.GetCollection(conversationCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "participantKey", Value: 1}},
+			Options: options.Index().SetName("participantKey_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "participantIds", Value: 1}, {Key: "lastMessageAt", Value: -1}},
+			Options: options.Index().SetName("participantIds_lastMessageAt"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create conversation indexes: %v", err)
+	}
+
+	_, err = db.GetCollection(messageCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "conversationId", Value: 1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("conversationId_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "recipientId", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index().SetName("recipientId_id"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create message indexes: %v", err)
+	}
+
+	// Rate documents are only needed while their newest send is inside the rate window
+	_, err = db.GetCollection(messageRateCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "updatedAt", Value: 1}},
+		Options: options.Index().SetName("updatedAt_ttl").SetExpireAfterSeconds(int32(messageRateWindow.Seconds())),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create message rate index: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"playtime-go/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// hiddenModerationStatuses keep content out of public listings
+var hiddenModerationStatuses = []string{models.ModerationPending, models.ModerationRejected, models.ModerationHidden}
+
+// VisibleContentFilter matches the moderationStatus of content that passed moderation or predates it
+func VisibleContentFilter() bson.M {
+	return bson.M{"$nin": hiddenModerationStatuses}
+}
+
+// isHiddenModerationStatus reports whether content with a moderation status is kept out of public view
+func isHiddenModerationStatus(status string) bool {
+	for _, hidden := range hiddenModerationStatuses {
+		if status == hidden {
+			return true
+		}
+	}
+	return false
+}
+
+// moderationStatusAfterEdit returns the moderation status of edited content from its current status and the check of the new text.
+// Content hidden by a moderator or by reports stays hidden, an edit cannot bring it back.
+func moderationStatusAfterEdit(current string, checked string) string {
+	if current == models.ModerationHidden {
+		return current
+	}
+	return checked
+}
+
+// contentCollection returns the collection holding a moderated content type
+func contentCollection(contentType string) (string, error) {
+	switch contentType {
+	case models.ContentTypeReview:
+		return reviewCollection, nil
+	case models.ContentTypePet:
+		return petCollection, nil
+	case models.ContentTypePlace:
+		return locationCollection, nil
+	}
+	return "", fmt.Errorf("invalid content type: %s", contentType)
+}
+
+// ListHeldContent lists content of a type held for moderation, oldest first
+func ListHeldContent(moderatorID primitive.ObjectID, contentType string, limit int64) (interface{}, error) {
+	if !IsModerator(moderatorID) {
+		return nil, fmt.Errorf("not authorized to moderate content")
+	}
+
+	// Images are held in their media checks rather than in the content using them
+	if contentType == models.ContentTypeMedia {
+		return listHeldMedia(limit)
+	}
+
+	collection, err := contentCollection(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "_id", Value: 1}})
+	if limit > 0 {
+		findOptions.SetLimit(limit)
+	} else {
+		findOptions.SetLimit(100) // Default limit
+	}
+
+	filter := bson.M{"moderationStatus": models.ModerationPending}
+	switch contentType {
+	case models.ContentTypeReview:
+		reviews := []models.Review{}
+		err = FindMany(collection, filter, &reviews, findOptions)
+		return reviews, err
+	case models.ContentTypePet:
+		pets := []models.Pet{}
+		err = FindMany(collection, filter, &pets, findOptions)
+		return pets, err
+	default:
+		var locations []models.Location
+		if err := FindMany(collection, filter, &locations, findOptions); err != nil {
+			return nil, err
+		}
+		responses := make([]models.LocationResponse, 0, len(locations))
+		for _, location := range locations {
+			if response, err := ConvertLocationToResponse(location); err == nil {
+				responses = append(responses, *response)
+			}
+		}
+		return responses, nil
+	}
+}
+
+// ModerateHeldContent approves or rejects content held for moderation
+func ModerateHeldContent(moderatorID primitive.ObjectID, contentType string, id primitive.ObjectID, approve bool) error {
+	if !IsModerator(moderatorID) {
+		return fmt.Errorf("not authorized to moderate content")
+	}
+
+	if contentType == models.ContentTypeMedia {
+		return moderateHeldMedia(moderatorID, id, approve)
+	}
+
+	collection, err := contentCollection(contentType)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": id, "moderationStatus": models.ModerationPending}
+	count, err := Count(collection, filter)
+	if err != nil {
+		return fmt.Errorf("failed to find held content: %v", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("no held content found with ID: %s", id.Hex())
+	}
+
+	status := models.ModerationRejected
+	if approve {
+		status = models.ModerationApproved
+	}
+
+	update := bson.M{"$set": bson.M{"moderationStatus": status, "updatedAt": time.Now()}}
+	if contentType == models.ContentTypeReview {
+		update = bson.M{"$set": bson.M{"moderationStatus": status}}
+	}
+	if err := UpdateOne(collection, filter, update); err != nil {
+		return fmt.Errorf("failed to moderate content: %v", err)
+	}
+
+	action := models.ModerationActionReject
+	if approve {
+		action = models.ModerationActionApprove
+	}
+	recordModerationAction(&moderatorID, action, contentType, id, "", 0)
+
+	if ownerID, err := reportTargetOwner(contentType, id); err == nil {
+		go notifyModerationResult(ownerID, contentType, id, action)
+	}
+	if approve && contentType == models.ContentTypeReview {
+		go publishApprovedReview(id)
+	}
+
+	return nil
+}
+
+// publishApprovedReview shares a review held since it was written once a moderator approves it.
+// A review that was already shared before an edit sent it back to the queue is not shared again.
+func publishApprovedReview(id primitive.ObjectID) {
+	review, err := GetReview(id)
+	if err != nil {
+		log.Printf("Failed to get approved review %s: %v", id.Hex(), err)
+		return
+	}
+
+	shared, err := Count(feedActivityCollection, bson.M{"kind": models.FeedKindReview, "sourceId": id})
+	if err != nil {
+		log.Printf("Failed to check feed activity of review %s: %v", id.Hex(), err)
+		return
+	}
+	if shared > 0 {
+		return
+	}
+
+	publishReviewActivity(*review)
+	notifyPlaceReview(*review)
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"playtime-go/models"
+	"testing"
+)
+
+func TestModerationStatusAfterEdit(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		checked string
+		want    string
+	}{
+		{name: "approved stays approved", current: models.ModerationApproved, checked: models.ModerationApproved, want: models.ModerationApproved},
+		{name: "flagged edit is held", current: models.ModerationApproved, checked: models.ModerationPending, want: models.ModerationPending},
+		{name: "clean edit releases held content", current: models.ModerationPending, checked: models.ModerationApproved, want: models.ModerationApproved},
+		{name: "clean edit of rejected content", current: models.ModerationRejected, checked: models.ModerationApproved, want: models.ModerationApproved},
+		{name: "content from before moderation", current: "", checked: models.ModerationPending, want: models.ModerationPending},
+		{name: "hidden stays hidden", current: models.ModerationHidden, checked: models.ModerationApproved, want: models.ModerationHidden},
+		{name: "hidden stays hidden when flagged", current: models.ModerationHidden, checked: models.ModerationPending, want: models.ModerationHidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := moderationStatusAfterEdit(tt.current, tt.checked); got != tt.want {
+				t.Errorf("moderationStatusAfterEdit(%q, %q) = %q, want %q", tt.current, tt.checked, got, tt.want)
+			}
+		})
+	}
+}
@@ -84,6 +84,20 @@ func UpdateOne(collectionName string, filter interface{}, update interface{}) er
 	return nil
 }
 
+// UpdateMany updates all documents matching the filter in the specified collection
+func UpdateMany(collectionName string, filter interface{}, update interface{}) (int64, error) {
+	collection := db.GetCollection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update documents: %v", err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
 // DeleteOne deletes a single document matching the filter in the specified collection
 func DeleteOne(collectionName string, filter interface{}) error {
 	collection := db.GetCollection(collectionName)
@@ -125,3 +139,17 @@ func Count(collectionName string, filter interface{}) (int64, error) {
 
 	return count, nil
 }
+
+// UpsertOne updates a single document matching the filter, inserting it if none exists
+func UpsertOne(collectionName string, filter interface{}, update interface{}) error {
+	collection := db.GetCollection(collectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to upsert document: %v", err)
+	}
+
+	return nil
+}
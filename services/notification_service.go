@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"playtime-go/db"
+	"playtime-go/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	notificationCollection = "notifications"
+
+	// notificationRetention is how long notifications stay in the inbox
+	notificationRetention = 180 * 24 * time.Hour
+)
+
+// notifyUser puts a notification in the user's inbox and pushes it to their open connections.
+// Types the user switched off and notifications about the user's own actions are dropped.
+// Notifications are a side effect of the action that caused them, so failures are only logged.
+func notifyUser(notification models.Notification) {
+	if notification.ActorID != nil && *notification.ActorID == notification.UserID {
+		return
+	}
+
+	user, err := GetUserByID(notification.UserID)
+	if err != nil {
+		log.Printf("Failed to get user %s for %s notification: %v", notification.UserID.Hex(), notification.Type, err)
+		return
+	}
+	if enabled, ok := user.NotifyPrefs[notification.Type]; ok && !enabled {
+		return
+	}
+
+	notification.Read = false
+	notification.CreatedAt = time.Now()
+
+	id, err := InsertOne(notificationCollection, notification)
+	if err != nil {
+		log.Printf("Failed to create %s notification for user %s: %v", notification.Type, notification.UserID.Hex(), err)
+		return
+	}
+	notification.ID = id
+
+	PublishUserEvent(notification.UserID, models.PushEventNotification, notification)
+}
+
+// ListNotifications pages through the user's inbox newest first, optionally only unread notifications
+func ListNotifications(userID primitive.ObjectID, unreadOnly bool, before string, limit int64) (*models.NotificationPage, error) {
+	filter := bson.M{"userId": userID}
+	if unreadOnly {
+		filter["read"] = false
+	}
+	if before != "" {
+		beforeID, err := primitive.ObjectIDFromHex(before)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %s", before)
+		}
+		filter["_id"] = bson.M{"$lt": beforeID}
+	}
+	if limit <= 0 {
+		limit = 20 // Default limit
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "_id", Value: -1}})
+	findOptions.SetLimit(limit)
+
+	page := &models.NotificationPage{Items: []models.Notification{}}
+	if err := FindMany(notificationCollection, filter, &page.Items, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %v", err)
+	}
+	if int64(len(page.Items)) == limit {
+		page.NextCursor = page.Items[len(page.Items)-1].ID.Hex()
+	}
+
+	unread, err := GetUnreadNotificationCount(userID)
+	if err != nil {
+		return nil, err
+	}
+	page.Unread = unread
+
+	return page, nil
+}
+
+// GetUnreadNotificationCount counts the user's unread notifications
+func GetUnreadNotificationCount(userID primitive.ObjectID) (int64, error) {
+	count, err := Count(notificationCollection, bson.M{"userId": userID, "read": false})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %v", err)
+	}
+	return count, nil
+}
+
+// MarkNotificationRead marks one of the user's notifications read
+func MarkNotificationRead(userID primitive.ObjectID, id primitive.ObjectID) (*models.Notification, error) {
+	filter := bson.M{"_id": id, "userId": userID}
+	var notification models.Notification
+	if err := FindOne(notificationCollection, filter, &notification); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no notification found with ID: %s", id.Hex())
+		}
+		return nil, fmt.Errorf("failed to get notification: %v", err)
+	}
+	if notification.Read {
+		return &notification, nil
+	}
+
+	now := time.Now()
+	if err := UpdateOne(notificationCollection, filter, bson.M{"$set": bson.M{"read": true, "readAt": now}}); err != nil {
+		return nil, fmt.Errorf("failed to mark notification read: %v", err)
+	}
+
+	notification.Read = true
+	notification.ReadAt = &now
+	return &notification, nil
+}
+
+// MarkAllNotificationsRead marks the user's unread notifications read, only those of one type when given.
+// It returns how many notifications were marked.
+func MarkAllNotificationsRead(userID primitive.ObjectID, notificationType string) (int64, error) {
+	filter := bson.M{"userId": userID, "read": false}
+	if notificationType != "" {
+		if !isNotificationType(notificationType) {
+			return 0, fmt.Errorf("invalid notification type: %s", notificationType)
+		}
+		filter["type"] = notificationType
+	}
+
+	marked, err := UpdateMany(notificationCollection, filter, bson.M{"$set": bson.M{"read": true, "readAt": time.Now()}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %v", err)
+	}
+	return marked, nil
+}
+
+// GetNotificationPreferences returns whether each notification type is on for the user
+func GetNotificationPreferences(userID primitive.ObjectID) (models.NotificationPreferences, error) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := models.NotificationPreferences{}
+	for _, notificationType := range models.NotificationTypes {
+		enabled, ok := user.NotifyPrefs[notificationType]
+		preferences[notificationType] = !ok || enabled
+	}
+	return preferences, nil
+}
+
+// UpdateNotificationPreferences switches the given notification types on or off, other types keep their setting
+func UpdateNotificationPreferences(userID primitive.ObjectID, request models.NotificationPreferences) (models.NotificationPreferences, error) {
+	if len(request) == 0 {
+		return nil, fmt.Errorf("invalid notification preferences: no types given")
+	}
+
+	set := bson.M{"updatedAt": time.Now()}
+	for notificationType, enabled := range request {
+		if !isNotificationType(notificationType) {
+			return nil, fmt.Errorf("invalid notification type: %s", notificationType)
+		}
+		set["notificationPreferences."+notificationType] = enabled
+	}
+
+	if _, err := GetUserByID(userID); err != nil {
+		return nil, err
+	}
+	if err := UpdateOne(userCollection, bson.M{"_id": userID}, bson.M{"$set": set}); err != nil {
+		return nil, fmt.Errorf("failed to update notification preferences: %v", err)
+	}
+
+	return GetNotificationPreferences(userID)
+}
+
+// isNotificationType reports whether the type is a known notification type
+func isNotificationType(notificationType string) bool {
+	return containsString(models.NotificationTypes, notificationType)
+}
+
+// notifyModerationResult tells the owner of moderated content what a moderator decided
+func notifyModerationResult(ownerID primitive.ObjectID, contentType string, id primitive.ObjectID, action string) {
+	if ownerID.IsZero() {
+		return
+	}
+
+	var title string
+	switch action {
+	case models.ModerationActionApprove:
+		title = fmt.Sprintf("Your %s was approved", contentType)
+	case models.ModerationActionReject:
+		title = fmt.Sprintf("Your %s was rejected", contentType)
+	case models.ModerationActionHide:
+		title = fmt.Sprintf("Your %s was hidden after a report", contentType)
+	case models.ModerationActionDelete:
+		title = fmt.Sprintf("Your %s was removed after a report", contentType)
+	case models.ModerationActionBan:
+		title = "Your account has been banned"
+	default:
+		return
+	}
+
+	notifyUser(models.Notification{
+		UserID:     ownerID,
+		Type:       models.NotificationModerationResult,
+		SourceType: contentType,
+		SourceID:   id,
+		Title:      title,
+	})
+}
+
+// notifyReporters tells the users who reported a target that their reports were resolved
+func notifyReporters(reporterIDs []primitive.ObjectID, targetType string, targetID primitive.ObjectID, status string) {
+	title := "Thanks for your report, we took action"
+	if status == models.ReportDismissed {
+		title = "Thanks for your report, we found no violation"
+	}
+
+	for _, reporterID := range reporterIDs {
+		notifyUser(models.Notification{
+			UserID:     reporterID,
+			Type:       models.NotificationModerationResult,
+			SourceType: targetType,
+			SourceID:   targetID,
+			Title:      title,
+		})
+	}
+}
+
+// EnsureNotificationIndexes creates the inbox indexes and expires old notifications
+func EnsureNotificationIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := db.GetCollection(notificationCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("userId_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "read", Value: 1}, {Key: "type", Value: 1}},
+			Options: options.Index().SetName("userId_read_type"),
+		},
+		{
+			Keys: bson.D{{Key: "createdAt", Value: 1}},
+			Options: options.Index().
+				SetName("createdAt_ttl").
+				SetExpireAfterSeconds(int32(notificationRetention.Seconds())),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create notification indexes: %v", err)
+	}
+
+	return nil
+}
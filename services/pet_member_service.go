@@ -0,0 +1,355 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"playtime-go/db"
+	"playtime-go/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	petInviteCollection = "pet_invites"
+	petInviteTTL        = 7 * 24 * time.Hour
+	inviteCodeAlphabet  = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // no 0/O or 1/I to keep codes easy to type
+	inviteCodeLength    = 8
+)
+
+// PetAccess is the level of access an operation needs on a pet
+type PetAccess int
+
+// Pet access levels, each includes the ones before it
+const (
+	PetAccessView   PetAccess = iota // read the pet and its records
+	PetAccessCare                    // add and edit care records such as health and weight
+	PetAccessEdit                    // edit the pet profile
+	PetAccessManage                  // delete the pet and manage its members
+)
+
+// petRoleAccess is the highest access level granted by each member role
+var petRoleAccess = map[string]PetAccess{
+	models.PetRoleOwner:   PetAccessManage,
+	models.PetRoleCoOwner: PetAccessEdit,
+	models.PetRoleSitter:  PetAccessCare,
+}
+
+// AuthorizePet retrieves a pet and verifies that the user is an active member with the required access
+func AuthorizePet(id primitive.ObjectID, userID primitive.ObjectID, access PetAccess) (*models.Pet, error) {
+	pet, err := GetPetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	member := findActivePetMember(pet, userID, time.Now())
+	if member == nil || petRoleAccess[member.Role] < access {
+		return nil, fmt.Errorf("not authorized to access pet: %s", id.Hex())
+	}
+
+	return pet, nil
+}
+
+// findActivePetMember returns the user's membership of a pet if it has not expired
+func findActivePetMember(pet *models.Pet, userID primitive.ObjectID, now time.Time) *models.PetMember {
+	for i := range pet.Members {
+		if pet.Members[i].UserID == userID && pet.Members[i].IsActive(now) {
+			return &pet.Members[i]
+		}
+	}
+	return nil
+}
+
+// petCaretakers returns the owners and co-owners of a pet
+func petCaretakers(pet *models.Pet, now time.Time) []primitive.ObjectID {
+	var userIDs []primitive.ObjectID
+	for _, member := range pet.Members {
+		if member.IsActive(now) && (member.Role == models.PetRoleOwner || member.Role == models.PetRoleCoOwner) {
+			userIDs = append(userIDs, member.UserID)
+		}
+	}
+	if len(userIDs) == 0 && !pet.OwnerID.IsZero() {
+		userIDs = append(userIDs, pet.OwnerID)
+	}
+	return userIDs
+}
+
+// activePetMemberFilter matches pets the user is an unexpired member of
+func activePetMemberFilter(userID primitive.ObjectID, now time.Time) bson.M {
+	return bson.M{"members": bson.M{"$elemMatch": bson.M{
+		"userId": userID,
+		"$or": []bson.M{
+			{"expiresAt": nil},
+			{"expiresAt": bson.M{"$gt": now}},
+		},
+	}}}
+}
+
+// ListPetMembers returns the active members of a pet the user belongs to
+func ListPetMembers(petID primitive.ObjectID, userID primitive.ObjectID) ([]models.PetMember, error) {
+	pet, err := AuthorizePet(petID, userID, PetAccessView)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	members := make([]models.PetMember, 0, len(pet.Members))
+	for _, member := range pet.Members {
+		if member.IsActive(now) {
+			members = append(members, member)
+		}
+	}
+
+	return members, nil
+}
+
+// RemovePetMember removes a member from a pet. Members may leave on their own,
+// otherwise the pet's owner is required. The owner cannot be removed.
+func RemovePetMember(petID primitive.ObjectID, userID primitive.ObjectID, memberID primitive.ObjectID) error {
+	access := PetAccessManage
+	if userID == memberID {
+		access = PetAccessView
+	}
+
+	pet, err := AuthorizePet(petID, userID, access)
+	if err != nil {
+		return err
+	}
+
+	if memberID == pet.OwnerID {
+		return fmt.Errorf("cannot remove the owner of pet: %s", petID.Hex())
+	}
+
+	update := bson.M{
+		"$pull": bson.M{"members": bson.M{"userId": memberID}},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
+	if err := UpdateOne(petCollection, bson.M{"_id": petID}, update); err != nil {
+		return fmt.Errorf("failed to remove pet member: %v", err)
+	}
+
+	return nil
+}
+
+// CreatePetInvite creates a shareable invite code for a pet. Owners may invite co-owners
+// and sitters, co-owners may only invite sitters. Sitter invites need an access expiry.
+func CreatePetInvite(petID primitive.ObjectID, userID primitive.ObjectID, request models.PetInviteRequest) (*models.PetInvite, error) {
+	access := PetAccessEdit
+	if request.Role == models.PetRoleCoOwner {
+		access = PetAccessManage
+	}
+
+	if _, err := AuthorizePet(petID, userID, access); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if request.Role == models.PetRoleSitter && (request.AccessExpiresAt == nil || !request.AccessExpiresAt.After(now)) {
+		return nil, fmt.Errorf("invalid invite: sitter access must expire in the future")
+	}
+	if request.Role != models.PetRoleSitter {
+		request.AccessExpiresAt = nil
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+
+	invite := models.PetInvite{
+		Code:            code,
+		PetID:           petID,
+		Role:            request.Role,
+		AccessExpiresAt: request.AccessExpiresAt,
+		ExpiresAt:       now.Add(petInviteTTL),
+		CreatedBy:       userID,
+		CreatedAt:       now,
+	}
+
+	id, err := InsertOne(petInviteCollection, invite)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pet invite: %v", err)
+	}
+
+	invite.ID = id
+	return &invite, nil
+}
+
+// AcceptPetInvite redeems an invite code and adds the user as a member of the pet
+func AcceptPetInvite(code string, userID primitive.ObjectID) (*models.Pet, error) {
+	now := time.Now()
+	filter := bson.M{"code": code, "usedBy": nil, "expiresAt": bson.M{"$gt": now}}
+	var invite models.PetInvite
+
+	if err := FindOne(petInviteCollection, filter, &invite); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no invite found with code: %s", code)
+		}
+		return nil, fmt.Errorf("failed to get invite: %v", err)
+	}
+
+	pet, err := GetPetByID(invite.PetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if member := findActivePetMember(pet, userID, now); member != nil {
+		return nil, fmt.Errorf("already a member of pet: %s", pet.ID.Hex())
+	}
+
+	// Mark the invite used first so the same code cannot be redeemed twice
+	if err := UpdateOne(petInviteCollection, bson.M{"_id": invite.ID, "usedBy": nil}, bson.M{
+		"$set": bson.M{"usedBy": userID, "usedAt": now},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to redeem invite: %v", err)
+	}
+	if err := FindOne(petInviteCollection, bson.M{"_id": invite.ID, "usedBy": userID}, &invite); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no invite found with code: %s", code)
+		}
+		return nil, fmt.Errorf("failed to redeem invite: %v", err)
+	}
+
+	// Drop any expired membership before adding the new one
+	petFilter := bson.M{"_id": pet.ID}
+	if err := UpdateOne(petCollection, petFilter, bson.M{"$pull": bson.M{"members": bson.M{"userId": userID}}}); err != nil {
+		return nil, fmt.Errorf("failed to add pet member: %v", err)
+	}
+
+	member := models.PetMember{
+		UserID:    userID,
+		Role:      invite.Role,
+		ExpiresAt: invite.AccessExpiresAt,
+		AddedAt:   now,
+	}
+	if err := UpdateOne(petCollection, petFilter, bson.M{
+		"$push": bson.M{"members": member},
+		"$set":  bson.M{"updatedAt": now},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to add pet member: %v", err)
+	}
+
+	return GetPetByID(pet.ID)
+}
+
+// generateInviteCode creates a random human-friendly invite code
+func generateInviteCode() (string, error) {
+	buf := make([]byte, inviteCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate invite code: %v", err)
+	}
+
+	code := make([]byte, inviteCodeLength)
+	for i, b := range buf {
+		code[i] = inviteCodeAlphabet[int(b)%len(inviteCodeAlphabet)]
+	}
+
+	return string(code), nil
+}
+
+// MigratePetMembers gives pets created before memberships existed their owner as the only member.
+// Pets that never had an owner get an empty member list and are logged so a moderator can assign one.
+func MigratePetMembers() (int, error) {
+	filter := bson.M{"members": bson.M{"$exists": false}}
+	var pets []models.Pet
+
+	if err := FindMany(petCollection, filter, &pets); err != nil {
+		return 0, fmt.Errorf("failed to find pets to migrate: %v", err)
+	}
+
+	for i, pet := range pets {
+		members := []models.PetMember{}
+		if pet.OwnerID.IsZero() {
+			log.Printf("Pet %s has no owner, a moderator can assign one with PUT /pet/%s/owner", pet.ID.Hex(), pet.ID.Hex())
+		} else {
+			members = append(members, models.PetMember{UserID: pet.OwnerID, Role: models.PetRoleOwner, AddedAt: pet.CreatedAt})
+		}
+		if err := UpdateOne(petCollection, bson.M{"_id": pet.ID}, bson.M{"$set": bson.M{"members": members}}); err != nil {
+			return i, fmt.Errorf("failed to migrate pet %s: %v", pet.ID.Hex(), err)
+		}
+	}
+
+	return len(pets), nil
+}
+
+// AssignPetOwner makes a user the owner of a pet that has none, such as pets created before owners
+// were recorded. Only moderators may assign owners.
+func AssignPetOwner(petID primitive.ObjectID, moderatorID primitive.ObjectID, ownerID primitive.ObjectID) (*models.Pet, error) {
+	if !IsModerator(moderatorID) {
+		return nil, fmt.Errorf("not authorized to assign pet owners")
+	}
+	if _, err := GetUserByID(ownerID); err != nil {
+		return nil, err
+	}
+
+	pet, err := GetPetByID(petID)
+	if err != nil {
+		return nil, err
+	}
+	if !pet.OwnerID.IsZero() {
+		return nil, fmt.Errorf("pet already has an owner: %s", petID.Hex())
+	}
+
+	// Claim the pet first so two moderators cannot assign different owners, then replace any
+	// membership the new owner already had
+	now := time.Now()
+	filter := bson.M{"_id": petID, "ownerId": bson.M{"$exists": false}}
+	update := bson.M{
+		"$set":  bson.M{"ownerId": ownerID, "updatedAt": now},
+		"$pull": bson.M{"members": bson.M{"userId": ownerID}},
+	}
+
+	collection := db.GetCollection(petCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign pet owner: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return nil, fmt.Errorf("pet already has an owner: %s", petID.Hex())
+	}
+	member := models.PetMember{UserID: ownerID, Role: models.PetRoleOwner, AddedAt: now}
+	if err := UpdateOne(petCollection, bson.M{"_id": petID}, bson.M{"$push": bson.M{"members": member}}); err != nil {
+		return nil, fmt.Errorf("failed to assign pet owner: %v", err)
+	}
+
+	return GetPetByID(petID)
+}
+
+// HidePetMembers drops the member list from a pet unless the viewer is an active member of it
+func HidePetMembers(pet *models.Pet, viewerID primitive.ObjectID) {
+	if viewerID.IsZero() || findActivePetMember(pet, viewerID, time.Now()) == nil {
+		pet.Members = nil
+	}
+}
+
+// EnsurePetIndexes creates the indexes used for pet membership lookups and invite codes
+func EnsurePetIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := db.GetCollection(petCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "members.userId", Value: 1}},
+		Options: options.Index().SetName("members_userId"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create pet member index: %v", err)
+	}
+
+	_, err = db.GetCollection(petInviteCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetName("code_unique").SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create pet invite index: %v", err)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"log"
 	"playtime-go/models"
 	"time"
 
@@ -11,23 +12,52 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-const petCollection = "pets"
+const (
+	petCollection  = "pets"
+	seniorAgeYears = 7
+)
 
 // CreatePet creates a new pet in the database
 func CreatePet(request models.PetRequest) (*models.Pet, error) {
+	if err := ensureUserNotBanned(request.OwnerID); err != nil {
+		return nil, err
+	}
+
+	// Normalize breed and size against the breed catalog
+	breed, err := normalizePetBreed(&request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Derive structured temperament from the character text if needed
+	applyPetTemperament(&request)
+
 	// Create new pet
 	now := time.Now()
+	birthDate, approximate := resolveBirthDate(request, now)
 	pet := models.Pet{
-		Name:      request.Name,
-		Gender:    request.Gender,
-		Size:      request.Size,
-		Breed:     request.Breed,
-		Avatar:    request.Avatar,
-		Character: request.Character,
-		Age:       request.Age,
-		OwnerID:   request.OwnerID,
-		CreatedAt: now,
-		UpdatedAt: now,
+		Name:                 request.Name,
+		Gender:               request.Gender,
+		Species:              request.Species,
+		Size:                 request.Size,
+		Breed:                request.Breed,
+		Avatar:               request.Avatar,
+		Character:            request.Character,
+		Energy:               request.Energy,
+		Temperament:          request.Temperament,
+		BirthDate:            birthDate,
+		BirthDateApproximate: approximate,
+		OwnerID:              request.OwnerID,
+		ModerationStatus:     checkUserText(request.OwnerID, request.Name, request.Character),
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	if breed != nil {
+		pet.BreedID = breed.ID
+		pet.Species = breed.Species
+	}
+	if !pet.OwnerID.IsZero() {
+		pet.Members = []models.PetMember{{UserID: pet.OwnerID, Role: models.PetRoleOwner, AddedAt: now}}
 	}
 
 	// Insert pet into database
@@ -37,6 +67,12 @@ func CreatePet(request models.PetRequest) (*models.Pet, error) {
 	}
 
 	pet.ID = id
+
+	// Share the new pet with the owner's followers, held pets are not shared
+	if !pet.OwnerID.IsZero() && pet.ModerationStatus == models.ModerationApproved {
+		go publishPetActivity(pet)
+	}
+
 	return &pet, nil
 }
 
@@ -56,27 +92,63 @@ func GetPetByID(id primitive.ObjectID) (*models.Pet, error) {
 	return &pet, nil
 }
 
-// UpdatePet updates an existing pet
-func UpdatePet(id primitive.ObjectID, request models.PetRequest) (*models.Pet, error) {
-	// Check if pet exists
-	_, err := GetPetByID(id)
+// GetVisiblePet retrieves a pet for a viewer, a pet held for moderation is only found by its own members
+func GetVisiblePet(id primitive.ObjectID, viewerID primitive.ObjectID) (*models.Pet, error) {
+	pet, err := GetPetByID(id)
 	if err != nil {
 		return nil, err
 	}
 
+	if isHiddenModerationStatus(pet.ModerationStatus) && (viewerID.IsZero() || findActivePetMember(pet, viewerID, time.Now()) == nil) {
+		return nil, fmt.Errorf("no pet found with ID: %s", id.Hex())
+	}
+
+	return pet, nil
+}
+
+// UpdatePet updates an existing pet if the user may edit it
+func UpdatePet(id primitive.ObjectID, userID primitive.ObjectID, request models.PetRequest) (*models.Pet, error) {
+	// Check if pet exists and the user may edit it
+	pet, err := AuthorizePet(id, userID, PetAccessEdit)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureUserNotBanned(userID); err != nil {
+		return nil, err
+	}
+
+	// Normalize breed and size against the breed catalog
+	breed, err := normalizePetBreed(&request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Derive structured temperament from the character text if needed
+	applyPetTemperament(&request)
+
 	// Prepare update document
 	now := time.Now()
-	updateData := bson.M{
-		"$set": bson.M{
-			"name":      request.Name,
-			"gender":    request.Gender,
-			"size":      request.Size,
-			"breed":     request.Breed,
-			"avatar":    request.Avatar,
-			"character": request.Character,
-			"age":       request.Age,
-			"updatedAt": now,
-		},
+	birthDate, approximate := resolveBirthDate(request, now)
+	fields := bson.M{
+		"name":                 request.Name,
+		"gender":               request.Gender,
+		"species":              request.Species,
+		"size":                 request.Size,
+		"breed":                request.Breed,
+		"avatar":               request.Avatar,
+		"character":            request.Character,
+		"energy":               request.Energy,
+		"temperament":          request.Temperament,
+		"birthDate":            birthDate,
+		"birthDateApproximate": approximate,
+		"moderationStatus":     moderationStatusAfterEdit(pet.ModerationStatus, checkUserText(userID, request.Name, request.Character)),
+		"updatedAt":            now,
+	}
+	updateData := bson.M{"$set": fields, "$unset": bson.M{"breedId": ""}}
+	if breed != nil {
+		fields["breedId"] = breed.ID
+		fields["species"] = breed.Species
+		updateData = bson.M{"$set": fields}
 	}
 
 	// Update pet in the database
@@ -90,10 +162,10 @@ func UpdatePet(id primitive.ObjectID, request models.PetRequest) (*models.Pet, e
 	return GetPetByID(id)
 }
 
-// DeletePet deletes a pet by ID
-func DeletePet(id primitive.ObjectID) error {
-	// Check if pet exists
-	_, err := GetPetByID(id)
+// DeletePet deletes a pet by ID if the user may manage it
+func DeletePet(id primitive.ObjectID, userID primitive.ObjectID) error {
+	// Check if pet exists and the user may delete it
+	_, err := AuthorizePet(id, userID, PetAccessManage)
 	if err != nil {
 		return err
 	}
@@ -105,15 +177,46 @@ func DeletePet(id primitive.ObjectID) error {
 		return fmt.Errorf("failed to delete pet: %v", err)
 	}
 
+	// Remove the gallery so its stored objects are not left behind
+	if err := deletePetPhotos(id); err != nil {
+		log.Printf("Failed to delete photos of pet %s: %v", id.Hex(), err)
+	}
+
+	// Take the pet out of followers' feeds
+	removeFeedActivity(id)
+
 	return nil
 }
 
-// ListPets retrieves all pets with optional pagination and filtering
-func ListPets(ownerID *primitive.ObjectID, limit int64) ([]models.Pet, error) {
-	// Prepare filter
+// ListPets retrieves all pets with optional pagination and filtering by member and age stage
+func ListPets(memberID *primitive.ObjectID, viewerID primitive.ObjectID, stage string, limit int64) ([]models.Pet, error) {
+	// Prepare filter, a member is anyone the pet is shared with whose access has not expired
+	now := time.Now()
 	filter := bson.M{}
-	if ownerID != nil {
-		filter["ownerId"] = *ownerID
+	if memberID != nil {
+		filter = activePetMemberFilter(*memberID, now)
+	}
+
+	// Pets held for moderation are only listed to their own members
+	if viewerID.IsZero() {
+		filter["moderationStatus"] = VisibleContentFilter()
+	} else {
+		filter["$or"] = []bson.M{
+			{"moderationStatus": VisibleContentFilter()},
+			activePetMemberFilter(viewerID, now),
+		}
+	}
+
+	// Age stages are birthdate ranges relative to today
+	puppyCutoff := now.AddDate(-1, 0, 0)
+	seniorCutoff := now.AddDate(-seniorAgeYears, 0, 0)
+	switch stage {
+	case models.PetStagePuppy:
+		filter["birthDate"] = bson.M{"$gt": puppyCutoff}
+	case models.PetStageAdult:
+		filter["birthDate"] = bson.M{"$lte": puppyCutoff, "$gt": seniorCutoff}
+	case models.PetStageSenior:
+		filter["birthDate"] = bson.M{"$lte": seniorCutoff}
 	}
 
 	var pets []models.Pet
@@ -136,3 +239,56 @@ func ListPets(ownerID *primitive.ObjectID, limit int64) ([]models.Pet, error) {
 
 	return pets, nil
 }
+
+// resolveBirthDate returns the birthdate of a request, estimating it from the legacy age field if needed
+func resolveBirthDate(request models.PetRequest, now time.Time) (*time.Time, bool) {
+	if request.BirthDate != nil {
+		return request.BirthDate, request.BirthDateApproximate
+	}
+	if request.Age > 0 {
+		estimated := now.AddDate(-request.Age, 0, 0)
+		return &estimated, true
+	}
+	return nil, false
+}
+
+// MigratePetAges converts the static age of existing pets into an approximate birthdate.
+// The age is assumed to have been correct when the pet was last updated.
+func MigratePetAges() (int, error) {
+	filter := bson.M{"age": bson.M{"$exists": true}}
+	var legacyPets []struct {
+		ID        primitive.ObjectID `bson:"_id"`
+		Age       int                `bson:"age"`
+		BirthDate *time.Time         `bson:"birthDate"`
+		UpdatedAt time.Time          `bson:"updatedAt"`
+	}
+
+	if err := FindMany(petCollection, filter, &legacyPets); err != nil {
+		return 0, fmt.Errorf("failed to find pets to migrate: %v", err)
+	}
+
+	migrated := 0
+	for _, pet := range legacyPets {
+		set := bson.M{}
+		if pet.BirthDate == nil && pet.Age > 0 {
+			reference := pet.UpdatedAt
+			if reference.IsZero() {
+				reference = time.Now()
+			}
+			set["birthDate"] = reference.AddDate(-pet.Age, 0, 0)
+			set["birthDateApproximate"] = true
+		}
+
+		update := bson.M{"$unset": bson.M{"age": ""}}
+		if len(set) > 0 {
+			update["$set"] = set
+		}
+
+		if err := UpdateOne(petCollection, bson.M{"_id": pet.ID}, update); err != nil {
+			return migrated, fmt.Errorf("failed to migrate pet %s: %v", pet.ID.Hex(), err)
+		}
+		migrated++
+	}
+
+	return migrated, nil
+}
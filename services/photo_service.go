@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"playtime-go/db"
+	"playtime-go/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const photoCollection = "pet_photos"
+
+// AddPetPhoto uploads a photo into a pet's gallery. It is placed after the existing photos,
+// and the first photo of a pet becomes its cover.
+func AddPetPhoto(petID primitive.ObjectID, userID primitive.ObjectID, fileReader io.Reader, filename string, contentType string, size int64, request models.PetPhotoRequest) (*models.PetPhoto, error) {
+	if _, err := AuthorizePet(petID, userID, PetAccessCare); err != nil {
+		return nil, err
+	}
+
+	// New photos go after the last one, counting would reuse an order left free by a deleted photo
+	var last []models.PetPhoto
+	lastOptions := options.Find()
+	lastOptions.SetSort(bson.D{{Key: "order", Value: -1}})
+	lastOptions.SetLimit(1)
+	if err := FindMany(photoCollection, bson.M{"petId": petID}, &last, lastOptions); err != nil {
+		return nil, fmt.Errorf("failed to find last pet photo: %v", err)
+	}
+	order := 0
+	if len(last) > 0 {
+		order = last[0].Order + 1
+	}
+
+	upload, err := UploadFileToCOS(fileReader, filename, contentType, PetObjectPrefix(petID, "photos"))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := RecordUpload(userID, upload, contentType, size); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	photo := models.PetPhoto{
+		PetID:      petID,
+		URL:        upload.URL,
+		Key:        upload.Filename,
+		Album:      request.Album,
+		Caption:    request.Caption,
+		Order:      order,
+		TakenAt:    request.TakenAt,
+		UploadedBy: userID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	id, err := InsertOne(photoCollection, photo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pet photo: %v", err)
+	}
+	photo.ID = id
+
+	// Check the image in the background, a rejected photo is removed again
+	go func() {
+		if err := SubmitUploadCheck(userID, upload); err != nil {
+			log.Printf("Failed to check pet photo %s: %v", upload.Filename, err)
+		}
+	}()
+
+	if len(last) == 0 || request.IsCover {
+		if err := setPetCoverPhoto(&photo); err != nil {
+			return nil, err
+		}
+	}
+
+	return &photo, nil
+}
+
+// ListPetPhotos returns a pet's photos in gallery order, optionally limited to one album
+func ListPetPhotos(petID primitive.ObjectID, userID primitive.ObjectID, album string) ([]models.PetPhoto, error) {
+	if _, err := AuthorizePet(petID, userID, PetAccessView); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"petId": petID}
+	if album != "" {
+		filter["album"] = album
+	}
+
+	var photos []models.PetPhoto
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "order", Value: 1}, {Key: "createdAt", Value: 1}})
+
+	if err := FindMany(photoCollection, filter, &photos, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list pet photos: %v", err)
+	}
+
+	return photos, nil
+}
+
+// UpdatePetPhoto updates a photo's caption, album and taken-at date, and can make it the pet's cover
+func UpdatePetPhoto(petID primitive.ObjectID, id primitive.ObjectID, userID primitive.ObjectID, request models.PetPhotoRequest) (*models.PetPhoto, error) {
+	if _, err := AuthorizePet(petID, userID, PetAccessCare); err != nil {
+		return nil, err
+	}
+	if _, err := getPetPhoto(petID, id); err != nil {
+		return nil, err
+	}
+
+	updateData := bson.M{
+		"$set": bson.M{
+			"album":     request.Album,
+			"caption":   request.Caption,
+			"takenAt":   request.TakenAt,
+			"updatedAt": time.Now(),
+		},
+	}
+
+	filter := bson.M{"_id": id, "petId": petID}
+	if err := UpdateOne(photoCollection, filter, updateData); err != nil {
+		return nil, fmt.Errorf("failed to update pet photo: %v", err)
+	}
+
+	photo, err := getPetPhoto(petID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.IsCover && !photo.IsCover {
+		if err := setPetCoverPhoto(photo); err != nil {
+			return nil, err
+		}
+	}
+
+	return photo, nil
+}
+
+// ReorderPetPhotos sets the gallery order of a pet's photos. Photos not listed keep their
+// relative order after the listed ones.
+func ReorderPetPhotos(petID primitive.ObjectID, userID primitive.ObjectID, request models.PetPhotoOrderRequest) ([]models.PetPhoto, error) {
+	if _, err := AuthorizePet(petID, userID, PetAccessCare); err != nil {
+		return nil, err
+	}
+
+	photos, err := ListPetPhotos(petID, userID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	position := make(map[primitive.ObjectID]int, len(request.PhotoIDs))
+	for i, photoID := range request.PhotoIDs {
+		position[photoID] = i
+	}
+
+	order := len(request.PhotoIDs)
+	now := time.Now()
+	for _, photo := range photos {
+		newOrder, ok := position[photo.ID]
+		if !ok {
+			newOrder = order
+			order++
+		}
+		if newOrder == photo.Order {
+			continue
+		}
+
+		updateData := bson.M{"$set": bson.M{"order": newOrder, "updatedAt": now}}
+		if err := UpdateOne(photoCollection, bson.M{"_id": photo.ID, "petId": petID}, updateData); err != nil {
+			return nil, fmt.Errorf("failed to reorder pet photos: %v", err)
+		}
+	}
+
+	return ListPetPhotos(petID, userID, "")
+}
+
+// DeletePetPhoto removes a photo from a pet's gallery together with its stored object.
+// If it was the cover, the next photo in the gallery becomes the cover.
+func DeletePetPhoto(petID primitive.ObjectID, id primitive.ObjectID, userID primitive.ObjectID) error {
+	if _, err := AuthorizePet(petID, userID, PetAccessCare); err != nil {
+		return err
+	}
+
+	photo, err := getPetPhoto(petID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := DeleteOne(photoCollection, bson.M{"_id": id, "petId": petID}); err != nil {
+		return fmt.Errorf("failed to delete pet photo: %v", err)
+	}
+
+	// The record is already gone, so a failed object delete only leaves an orphaned file
+	if photo.Key != "" {
+		if err := DeleteObjectFromCOS(photo.Key); err != nil {
+			log.Printf("Failed to delete photo object %s: %v", photo.Key, err)
+		}
+		if err := deleteUploadRecords(photo.Key); err != nil {
+			log.Printf("Failed to delete upload record of %s: %v", photo.Key, err)
+		}
+	}
+
+	if photo.IsCover {
+		var remaining []models.PetPhoto
+		findOptions := options.Find()
+		findOptions.SetSort(bson.D{{Key: "order", Value: 1}, {Key: "createdAt", Value: 1}})
+		findOptions.SetLimit(1)
+		if err := FindMany(photoCollection, bson.M{"petId": petID}, &remaining, findOptions); err != nil {
+			return fmt.Errorf("failed to find next cover photo: %v", err)
+		}
+
+		if len(remaining) == 0 {
+			updateData := bson.M{"$unset": bson.M{"coverPhoto": ""}, "$set": bson.M{"updatedAt": time.Now()}}
+			if err := UpdateOne(petCollection, bson.M{"_id": petID}, updateData); err != nil {
+				return fmt.Errorf("failed to clear pet cover photo: %v", err)
+			}
+		} else if err := setPetCoverPhoto(&remaining[0]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deletePetPhotos removes all photos of a pet and their stored objects when the pet is deleted
+func deletePetPhotos(petID primitive.ObjectID) error {
+	var photos []models.PetPhoto
+	if err := FindMany(photoCollection, bson.M{"petId": petID}, &photos); err != nil {
+		return fmt.Errorf("failed to list pet photos: %v", err)
+	}
+
+	keys := make([]string, 0, len(photos))
+	for _, photo := range photos {
+		if photo.Key == "" {
+			continue
+		}
+		if err := DeleteObjectFromCOS(photo.Key); err != nil {
+			log.Printf("Failed to delete photo object %s: %v", photo.Key, err)
+		}
+		keys = append(keys, photo.Key)
+	}
+	if err := deleteUploadRecords(keys...); err != nil {
+		log.Printf("Failed to delete upload records of pet %s: %v", petID.Hex(), err)
+	}
+
+	if _, err := DeleteMany(photoCollection, bson.M{"petId": petID}); err != nil {
+		return fmt.Errorf("failed to delete pet photos: %v", err)
+	}
+
+	return nil
+}
+
+// getPetPhoto retrieves a photo that belongs to the given pet
+func getPetPhoto(petID primitive.ObjectID, id primitive.ObjectID) (*models.PetPhoto, error) {
+	var photo models.PetPhoto
+	filter := bson.M{"_id": id, "petId": petID}
+
+	if err := FindOne(photoCollection, filter, &photo); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no photo found with ID: %s", id.Hex())
+		}
+		return nil, fmt.Errorf("failed to get pet photo by ID: %v", err)
+	}
+
+	return &photo, nil
+}
+
+// setPetCoverPhoto marks a photo as the pet's only cover and copies its URL onto the pet
+func setPetCoverPhoto(photo *models.PetPhoto) error {
+	now := time.Now()
+	coverFilter := bson.M{"petId": photo.PetID, "isCover": true}
+	if _, err := UpdateMany(photoCollection, coverFilter, bson.M{"$set": bson.M{"isCover": false, "updatedAt": now}}); err != nil {
+		return fmt.Errorf("failed to clear cover photo: %v", err)
+	}
+
+	if err := UpdateOne(photoCollection, bson.M{"_id": photo.ID}, bson.M{"$set": bson.M{"isCover": true, "updatedAt": now}}); err != nil {
+		return fmt.Errorf("failed to set cover photo: %v", err)
+	}
+
+	if err := UpdateOne(petCollection, bson.M{"_id": photo.PetID}, bson.M{"$set": bson.M{"coverPhoto": photo.URL, "updatedAt": now}}); err != nil {
+		return fmt.Errorf("failed to set pet cover photo: %v", err)
+	}
+
+	photo.IsCover = true
+	return nil
+}
+
+// EnsurePhotoIndexes creates the index used to read a pet's gallery in order
+func EnsurePhotoIndexes() error {
+	collection := db.GetCollection(photoCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "petId", Value: 1}, {Key: "order", Value: 1}},
+		Options: options.Index().SetName("petId_order"),
+	}
+
+	_, err := collection.Indexes().CreateOne(ctx, indexModel)
+	if err != nil {
+		return fmt.Errorf("failed to create pet photo index: %v", err)
+	}
+
+	return nil
+}
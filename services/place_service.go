@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"log"
 	"playtime-go/models"
 	"time"
 
@@ -20,15 +21,110 @@ func CreateReview(request models.Review) (*models.Review, error) {
 	now := time.Now()
 	request.Date = now
 
+	// Mark the review as a verified visit only if the user checked in at the place
+	request.VerifiedVisit = false
+	placeID, placeErr := primitive.ObjectIDFromHex(request.PlaceID)
+	userID, userErr := primitive.ObjectIDFromHex(request.UserID)
+	if userErr == nil {
+		if err := ensureUserNotBanned(userID); err != nil {
+			return nil, err
+		}
+	}
+	if placeErr == nil && userErr == nil {
+		verified, err := hasRecentCheckIn(placeID, userID)
+		if err != nil {
+			return nil, err
+		}
+		request.VerifiedVisit = verified
+	}
+	if request.RequireCheckIn && !request.VerifiedVisit {
+		return nil, fmt.Errorf("no check-in found at place: %s", request.PlaceID)
+	}
+
+	// Hold flagged text for a moderator
+	request.ModerationStatus = checkUserText(userID, request.Content)
+
+	// Attach the uploaded photos
+	photos, err := reviewPhotosFromURLs(userID, request.PhotoURLs, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Photos = photos
+	request.PhotoURLs = nil
+
 	// Insert review into database
-	_, err := InsertOne(reviewCollection, request)
+	id, err := InsertOne(reviewCollection, request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create review: %v", err)
 	}
 
+	// Generate thumbnails in the background
+	if len(photos) > 0 {
+		go generateReviewThumbnails(id, photos)
+	}
+
+	request.ID = id
+
+	// Share the review with followers, held reviews are not shared
+	if request.ModerationStatus == models.ModerationApproved {
+		go publishReviewActivity(request)
+		go notifyPlaceReview(request)
+	}
+
 	return &request, nil
 }
 
+// notifyPlaceReview pushes a new review to the creator and the manager of the reviewed place
+// and puts it in the inbox of the manager of a claimed place
+func notifyPlaceReview(review models.Review) {
+	placeID, err := primitive.ObjectIDFromHex(review.PlaceID)
+	if err != nil {
+		return
+	}
+	place, err := GetLocationByID(placeID)
+	if err != nil {
+		log.Printf("Failed to get place %s for review %s: %v", review.PlaceID, review.ID.Hex(), err)
+		return
+	}
+
+	recipients := []primitive.ObjectID{place.CreatedBy}
+	if place.ManagerID != nil && *place.ManagerID != place.CreatedBy {
+		recipients = append(recipients, *place.ManagerID)
+	}
+
+	event := models.PlaceReviewEvent{
+		PlaceID:   review.PlaceID,
+		PlaceName: place.Name,
+		ReviewID:  review.ID,
+		UserID:    review.UserID,
+		UserName:  review.UserName,
+		Rating:    review.Rating,
+	}
+	for _, userID := range recipients {
+		if userID.IsZero() || userID.Hex() == review.UserID {
+			continue
+		}
+		PublishUserEvent(userID, models.PushEventPlaceReview, event)
+	}
+
+	// The manager of a claimed place also gets the review in their inbox
+	if place.ManagerID != nil {
+		notification := models.Notification{
+			UserID:     *place.ManagerID,
+			Type:       models.NotificationPlaceReview,
+			ActorName:  review.UserName,
+			SourceType: models.ContentTypeReview,
+			SourceID:   review.ID,
+			Title:      fmt.Sprintf("New %d star review of %s", review.Rating, place.Name),
+			Body:       feedSummary(review.Content),
+		}
+		if reviewerID, err := primitive.ObjectIDFromHex(review.UserID); err == nil {
+			notification.ActorID = &reviewerID
+		}
+		notifyUser(notification)
+	}
+}
+
 // GetReview retrieves a review by ID
 func GetReview(id primitive.ObjectID) (*models.Review, error) {
 	filter := bson.M{"_id": id}
@@ -45,60 +141,150 @@ func GetReview(id primitive.ObjectID) (*models.Review, error) {
 	return &review, nil
 }
 
-// UpdateReview updates an existing review
-func UpdateReview(id primitive.ObjectID, request models.Review) (*models.Review, error) {
+// GetVisibleReview retrieves a review for a viewer, a review held for moderation is only found by its author and moderators
+func GetVisibleReview(id primitive.ObjectID, viewerID primitive.ObjectID) (*models.Review, error) {
+	review, err := GetReview(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if isHiddenModerationStatus(review.ModerationStatus) && (viewerID.IsZero() || (review.UserID != viewerID.Hex() && !IsModerator(viewerID))) {
+		return nil, fmt.Errorf("no review found with ID: %s", id.Hex())
+	}
+
+	return review, nil
+}
+
+// MigrateReviewRatings moves ratings that earlier versions of UpdateReview wrote to "ratingStar"
+// into "rating", which is the field reviews are read and sorted by
+func MigrateReviewRatings() (int64, error) {
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"rating": "$ratingStar"}}},
+		{{Key: "$unset", Value: "ratingStar"}},
+	}
+	migrated, err := UpdateMany(reviewCollection, bson.M{"ratingStar": bson.M{"$exists": true}}, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to migrate review ratings: %v", err)
+	}
+	return migrated, nil
+}
+
+// UpdateReview updates an existing review, only its author and moderators may edit it
+func UpdateReview(id primitive.ObjectID, userID primitive.ObjectID, request models.Review) (*models.Review, error) {
 	// Check if review exists
-	_, err := GetReview(id)
+	existing, err := GetReview(id)
 	if err != nil {
 		return nil, err
 	}
+	if err := authorizeReviewChange(existing, userID); err != nil {
+		return nil, err
+	}
+	reviewerID, _ := primitive.ObjectIDFromHex(existing.UserID)
 
 	// Prepare update document
-	updateData := bson.M{
-		"$set": bson.M{
-			"content":    request.Content,
-			"ratingStar": request.Rating,
-			"date":       time.Now(),
-		},
+	fields := bson.M{
+		"content":          request.Content,
+		"rating":           request.Rating,
+		"date":             time.Now(),
+		"moderationStatus": moderationStatusAfterEdit(existing.ModerationStatus, checkUserText(reviewerID, request.Content)),
+	}
+
+	// Photos are only replaced when the request lists them
+	var photos, removed []models.ReviewPhoto
+	if request.PhotoURLs != nil {
+		photos, err = reviewPhotosFromURLs(reviewerID, request.PhotoURLs, existing.Photos)
+		if err != nil {
+			return nil, err
+		}
+		removed = removedReviewPhotos(existing.Photos, photos)
+		fields["photos"] = photos
 	}
 
 	// Update review in the database
 	filter := bson.M{"_id": id}
-	err = UpdateOne(reviewCollection, filter, updateData)
+	err = UpdateOne(reviewCollection, filter, bson.M{"$set": fields})
 	if err != nil {
 		return nil, fmt.Errorf("failed to update review: %v", err)
 	}
 
+	// Clean up removed photos and generate thumbnails for new ones in the background
+	if len(photos) > 0 || len(removed) > 0 {
+		go func() {
+			deleteReviewPhotoObjects(existing.UserID, removed)
+			generateReviewThumbnails(id, photos)
+		}()
+	}
+
 	// Get the updated review
 	return GetReview(id)
 }
 
-// DeleteReview deletes a review by ID
-func DeleteReview(id primitive.ObjectID) error {
+// DeleteReview deletes a review by ID, only its author and moderators may delete it
+func DeleteReview(id primitive.ObjectID, userID primitive.ObjectID) error {
 	// Check if review exists
-	_, err := GetReview(id)
+	review, err := GetReview(id)
 	if err != nil {
 		return err
 	}
+	if err := authorizeReviewChange(review, userID); err != nil {
+		return err
+	}
+
+	return deleteReview(review)
+}
+
+// authorizeReviewChange checks that the user is the review's author or a moderator and is not banned
+func authorizeReviewChange(review *models.Review, userID primitive.ObjectID) error {
+	if review.UserID != userID.Hex() && !IsModerator(userID) {
+		return fmt.Errorf("not authorized to modify review: %s", review.ID.Hex())
+	}
+
+	// Banned users cannot edit or delete reviews
+	return ensureUserNotBanned(userID)
+}
+
+// deleteReview removes a review with its votes, feed entries and photos
+func deleteReview(review *models.Review) error {
+	id := review.ID
 
 	// Delete review from the database
 	filter := bson.M{"_id": id}
-	err = DeleteOne(reviewCollection, filter)
+	err := DeleteOne(reviewCollection, filter)
 	if err != nil {
 		return fmt.Errorf("failed to delete review: %v", err)
 	}
 
+	// Remove the votes cast on the review
+	if err := deleteReviewVotes(id); err != nil {
+		log.Printf("Failed to delete votes of review %s: %v", id.Hex(), err)
+	}
+
+	// Take the review out of followers' feeds
+	removeFeedActivity(id)
+
+	// Remove the review's photos from storage
+	go deleteReviewPhotoObjects(review.UserID, review.Photos)
+
 	return nil
 }
 
-// GetReviewsByPlace gets all reviews for a specific place
-func GetReviewsByPlace(placeID string, limit int64) ([]models.Review, error) {
-	filter := bson.M{"placeId": placeID}
+// GetReviewsByPlace gets all reviews for a specific place in the given sort mode.
+// Reviews by users the viewer blocked or muted are left out.
+func GetReviewsByPlace(placeID string, sort string, limit int64, viewerID *primitive.ObjectID) ([]models.Review, error) {
+	filter := bson.M{"placeId": placeID, "moderationStatus": VisibleContentFilter()}
 	var reviews []models.Review
 
-	// Set options for sorting by date (descending) and limit
+	authors, err := hiddenAuthorFilter(viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if authors != nil {
+		filter["userId"] = authors
+	}
+
+	// Set options for sorting and limit
 	findOptions := options.Find()
-	findOptions.SetSort(bson.D{{Key: "date", Value: -1}})
+	findOptions.SetSort(ReviewSortOrder(sort))
 
 	// Apply limit if specified
 	if limit > 0 {
@@ -107,7 +293,7 @@ func GetReviewsByPlace(placeID string, limit int64) ([]models.Review, error) {
 		findOptions.SetLimit(100) // Default limit
 	}
 
-	err := FindMany(reviewCollection, filter, &reviews, findOptions)
+	err = FindMany(reviewCollection, filter, &reviews, findOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get reviews by place: %v", err)
 	}
@@ -115,6 +301,43 @@ func GetReviewsByPlace(placeID string, limit int64) ([]models.Review, error) {
 	return reviews, nil
 }
 
+// ListReviews lists visible reviews matching the filter in the given sort mode.
+// Reviews by users the viewer blocked or muted are left out, even when the filter asks for that user.
+func ListReviews(filter bson.M, sort string, limit int64, viewerID *primitive.ObjectID) ([]models.Review, error) {
+	query := bson.M{"moderationStatus": VisibleContentFilter()}
+	for key, value := range filter {
+		query[key] = value
+	}
+
+	authors, err := hiddenAuthorFilter(viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if authors != nil {
+		if userID, ok := query["userId"]; ok {
+			query["$and"] = []bson.M{{"userId": userID}, {"userId": authors}}
+			delete(query, "userId")
+		} else {
+			query["userId"] = authors
+		}
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(ReviewSortOrder(sort))
+	if limit > 0 {
+		findOptions.SetLimit(limit)
+	} else {
+		findOptions.SetLimit(100) // Default limit
+	}
+
+	reviews := []models.Review{}
+	if err := FindMany(reviewCollection, query, &reviews, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list reviews: %v", err)
+	}
+
+	return reviews, nil
+}
+
 // GetReviewsByUserID gets all reviews for a specific user
 func GetReviewsByUserID(ctx context.Context, userID string) ([]models.Review, error) {
 	// Check if context is already cancelled
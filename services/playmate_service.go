@@ -0,0 +1,481 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"playtime-go/models"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultPlaymateLimit = 20
+	maxPlaymateLimit     = 50
+)
+
+// characterKeyword maps a word found in free-text pet characters to an energy level or temperament tag
+type characterKeyword struct {
+	Word  string
+	Value string
+}
+
+// characterEnergyKeywords lists the words that set an energy level, in the order they are checked
+var characterEnergyKeywords = []characterKeyword{
+	{"活泼", models.EnergyHigh},
+	{"精力", models.EnergyHigh},
+	{"好动", models.EnergyHigh},
+	{"调皮", models.EnergyHigh},
+	{"energetic", models.EnergyHigh},
+	{"active", models.EnergyHigh},
+	{"hyper", models.EnergyHigh},
+	{"安静", models.EnergyLow},
+	{"懒", models.EnergyLow},
+	{"慵懒", models.EnergyLow},
+	{"quiet", models.EnergyLow},
+	{"lazy", models.EnergyLow},
+	{"mellow", models.EnergyLow},
+}
+
+// characterTemperamentKeywords lists the words that set a temperament tag, in the order they are checked
+var characterTemperamentKeywords = []characterKeyword{
+	{"友好", models.TemperamentFriendly},
+	{"亲人", models.TemperamentFriendly},
+	{"粘人", models.TemperamentFriendly},
+	{"friendly", models.TemperamentFriendly},
+	{"social", models.TemperamentFriendly},
+	{"贪玩", models.TemperamentPlayful},
+	{"爱玩", models.TemperamentPlayful},
+	{"调皮", models.TemperamentPlayful},
+	{"playful", models.TemperamentPlayful},
+	{"安静", models.TemperamentCalm},
+	{"稳重", models.TemperamentCalm},
+	{"calm", models.TemperamentCalm},
+	{"温顺", models.TemperamentGentle},
+	{"温柔", models.TemperamentGentle},
+	{"乖", models.TemperamentGentle},
+	{"gentle", models.TemperamentGentle},
+	{"胆小", models.TemperamentShy},
+	{"害羞", models.TemperamentShy},
+	{"怕生", models.TemperamentShy},
+	{"shy", models.TemperamentShy},
+	{"timid", models.TemperamentShy},
+	{"霸道", models.TemperamentDominant},
+	{"强势", models.TemperamentDominant},
+	{"dominant", models.TemperamentDominant},
+	{"护食", models.TemperamentReactive},
+	{"凶", models.TemperamentReactive},
+	{"咬人", models.TemperamentReactive},
+	{"reactive", models.TemperamentReactive},
+	{"aggressive", models.TemperamentReactive},
+	{"独立", models.TemperamentIndependent},
+	{"高冷", models.TemperamentIndependent},
+	{"independent", models.TemperamentIndependent},
+}
+
+// characterNegations are words that cancel the keyword right after them, such as "not shy" or "不凶"
+var characterNegations = map[string]bool{"not": true, "no": true, "non": true, "never": true, "不": true, "没": true}
+
+// characterDegreeWords may come before a single-character Chinese keyword, such as "很乖"
+var characterDegreeWords = []string{"非常", "特别", "比较", "有点", "很", "超", "太", "好"}
+
+// temperamentConflicts lists tag pairs that tend not to play well together
+var temperamentConflicts = [][2]string{
+	{models.TemperamentDominant, models.TemperamentDominant},
+	{models.TemperamentDominant, models.TemperamentShy},
+	{models.TemperamentReactive, models.TemperamentReactive},
+	{models.TemperamentReactive, models.TemperamentShy},
+	{models.TemperamentReactive, models.TemperamentPlayful},
+}
+
+// sizeRank orders size classes so adjacent classes can be compared
+var sizeRank = map[string]int{models.SizeSmall: 0, models.SizeMedium: 1, models.SizeLarge: 2}
+
+// energyRank orders energy levels so adjacent levels can be compared
+var energyRank = map[string]int{models.EnergyLow: 0, models.EnergyMedium: 1, models.EnergyHigh: 2}
+
+// IsValidTemperament reports whether a tag is a known temperament tag
+func IsValidTemperament(tag string) bool {
+	for _, keyword := range characterTemperamentKeywords {
+		if keyword.Value == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCharacterTags derives an energy level and temperament tags from a free-text character description.
+// A high energy keyword wins over a low one, and tags are returned sorted.
+func ParseCharacterTags(character string) (string, []string) {
+	words, phrases := splitCharacter(character)
+
+	energy := ""
+	for _, keyword := range characterEnergyKeywords {
+		if matchCharacterKeyword(keyword.Word, words, phrases) && (energy == "" || keyword.Value == models.EnergyHigh) {
+			energy = keyword.Value
+		}
+	}
+
+	found := make(map[string]bool)
+	tags := []string{}
+	for _, keyword := range characterTemperamentKeywords {
+		if !found[keyword.Value] && matchCharacterKeyword(keyword.Word, words, phrases) {
+			found[keyword.Value] = true
+			tags = append(tags, keyword.Value)
+		}
+	}
+	sort.Strings(tags)
+
+	return energy, tags
+}
+
+// splitCharacter splits character text into lowercase words of letters and runs of Chinese characters.
+// Everything else separates them.
+func splitCharacter(character string) ([]string, []string) {
+	var words, phrases []string
+	var current []rune
+	currentHan := false
+
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		if currentHan {
+			phrases = append(phrases, string(current))
+		} else {
+			words = append(words, string(current))
+		}
+		current = current[:0]
+	}
+
+	for _, r := range strings.ToLower(character) {
+		han := unicode.Is(unicode.Han, r)
+		if !han && !unicode.IsLetter(r) {
+			flush()
+			continue
+		}
+		if len(current) > 0 && han != currentHan {
+			flush()
+		}
+		currentHan = han
+		current = append(current, r)
+	}
+	flush()
+
+	return words, phrases
+}
+
+// matchCharacterKeyword reports whether a keyword appears in the split character text without a negation
+// in front of it. English keywords must be whole words. Chinese keywords are found inside runs of Chinese
+// characters, except single characters, which must stand alone or follow a degree word, since they are
+// part of too many other words.
+func matchCharacterKeyword(keyword string, words []string, phrases []string) bool {
+	if !unicode.Is(unicode.Han, []rune(keyword)[0]) {
+		for i, word := range words {
+			if word == keyword && (i == 0 || !characterNegations[words[i-1]]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len([]rune(keyword)) == 1 {
+		for _, phrase := range phrases {
+			if phrase == keyword {
+				return true
+			}
+			for _, degree := range characterDegreeWords {
+				if phrase == degree+keyword {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	for _, phrase := range phrases {
+		for offset := 0; ; {
+			index := strings.Index(phrase[offset:], keyword)
+			if index < 0 {
+				break
+			}
+			index += offset
+			before := []rune(phrase[:index])
+			if len(before) == 0 || !characterNegations[string(before[len(before)-1])] {
+				return true
+			}
+			offset = index + len(keyword)
+		}
+	}
+	return false
+}
+
+// applyPetTemperament fills in energy and temperament from the character text when the client did not set them
+func applyPetTemperament(request *models.PetRequest) {
+	energy, tags := ParseCharacterTags(request.Character)
+	if request.Energy == "" {
+		request.Energy = energy
+	}
+	if len(request.Temperament) == 0 {
+		request.Temperament = tags
+	}
+	if request.Temperament == nil {
+		request.Temperament = []string{}
+	}
+}
+
+// FindPlaymates suggests pets of opted-in owners near the user's home area as playmates for a pet.
+// Only a rounded distance between home areas is returned, never a location.
+func FindPlaymates(petID primitive.ObjectID, userID primitive.ObjectID, limit int) ([]models.PlaymateSuggestion, error) {
+	pet, err := AuthorizePet(petID, userID, PetAccessView)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.HomeArea == nil || !user.HomeArea.PlaymateMatching {
+		return nil, fmt.Errorf("playmate matching requires a home area with matching enabled")
+	}
+
+	if limit <= 0 {
+		limit = defaultPlaymateLimit
+	}
+	limit = int(math.Min(float64(limit), maxPlaymateLimit))
+
+	home := user.HomeArea.Location.Coordinates
+	owners, err := findUsersNearPoint(home[0], home[1], user.HomeArea.RadiusMeters, bson.M{
+		"_id":                       bson.M{"$ne": userID},
+		"homeArea.playmateMatching": true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(owners) == 0 {
+		return []models.PlaymateSuggestion{}, nil
+	}
+
+	distances := make(map[primitive.ObjectID]float64, len(owners))
+	ownerIDs := make([]primitive.ObjectID, 0, len(owners))
+	for _, owner := range owners {
+		coords := owner.HomeArea.Location.Coordinates
+		distances[owner.ID] = distanceMeters(home[0], home[1], coords[0], coords[1])
+		ownerIDs = append(ownerIDs, owner.ID)
+	}
+
+	// Only pets whose owner or co-owner opted in are suggested, not pets they are only sitting
+	filter := bson.M{
+		"_id":              bson.M{"$ne": pet.ID},
+		"moderationStatus": VisibleContentFilter(),
+		"members": bson.M{"$elemMatch": bson.M{
+			"userId": bson.M{"$in": ownerIDs},
+			"role":   bson.M{"$in": []string{models.PetRoleOwner, models.PetRoleCoOwner}},
+		}},
+	}
+	if pet.Species != "" {
+		filter["species"] = pet.Species
+	}
+
+	var candidates []models.Pet
+	findOptions := options.Find()
+	findOptions.SetLimit(500)
+	if err := FindMany(petCollection, filter, &candidates, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to find playmate candidates: %v", err)
+	}
+
+	now := time.Now()
+	suggestions := make([]models.PlaymateSuggestion, 0, len(candidates))
+	for _, candidate := range candidates {
+		// Skip pets the user already belongs to
+		if findActivePetMember(&candidate, userID, now) != nil {
+			continue
+		}
+
+		distance := math.MaxFloat64
+		for _, member := range candidate.Members {
+			if d, ok := distances[member.UserID]; ok && d < distance {
+				distance = d
+			}
+		}
+
+		score, reasons := scorePlaymate(pet, &candidate, now)
+		if score <= 0 {
+			continue
+		}
+
+		suggestions = append(suggestions, models.PlaymateSuggestion{
+			Pet:        playmateProfile(&candidate, now),
+			Score:      score,
+			Reasons:    reasons,
+			DistanceKm: roundedDistanceKm(distance),
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].DistanceKm < suggestions[j].DistanceKm
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	return suggestions, nil
+}
+
+// scorePlaymate scores how well two pets are likely to play together out of 100, with the
+// reasons behind the score. Size is worth 30, energy 25, temperament 25 and age 20.
+func scorePlaymate(pet *models.Pet, other *models.Pet, now time.Time) (int, []string) {
+	score := 0
+	var reasons []string
+
+	score += rankScore(sizeRank, pet.Size, other.Size, 30)
+	if pet.Size != "" && pet.Size == other.Size {
+		reasons = append(reasons, "same size")
+	}
+
+	score += rankScore(energyRank, petEnergy(pet), petEnergy(other), 25)
+	if e := petEnergy(pet); e != "" && e == petEnergy(other) {
+		reasons = append(reasons, e+" energy")
+	}
+
+	temperamentScore, shared, conflicts := temperamentCompatibility(petTemperament(pet), petTemperament(other))
+	score += temperamentScore
+	for _, tag := range shared {
+		reasons = append(reasons, "both "+tag)
+	}
+	if conflicts > 0 {
+		reasons = append(reasons, "temperaments may clash")
+	}
+
+	stage, stageKnown := petStage(pet, now)
+	otherStage, otherKnown := petStage(other, now)
+	switch {
+	case !stageKnown || !otherKnown:
+		score += 10
+	case stage == otherStage:
+		score += 20
+		reasons = append(reasons, "both "+stage+"s")
+	case stage == models.PetStageAdult || otherStage == models.PetStageAdult:
+		score += 10
+	}
+
+	if reasons == nil {
+		reasons = []string{}
+	}
+	return score, reasons
+}
+
+// rankScore gives full points for equal ranks, half for adjacent ones and half when either is unknown
+func rankScore(ranks map[string]int, a string, b string, points int) int {
+	rankA, okA := ranks[a]
+	rankB, okB := ranks[b]
+	if !okA || !okB {
+		return points / 2
+	}
+
+	switch diff := rankA - rankB; {
+	case diff == 0:
+		return points
+	case diff == 1 || diff == -1:
+		return points / 2
+	}
+	return 0
+}
+
+// temperamentCompatibility scores two sets of temperament tags out of 25. Shared friendly
+// traits add points and each conflicting pair removes 10.
+func temperamentCompatibility(tags []string, otherTags []string) (int, []string, int) {
+	has := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		has[tag] = true
+	}
+	otherHas := make(map[string]bool, len(otherTags))
+	for _, tag := range otherTags {
+		otherHas[tag] = true
+	}
+
+	score := 15
+	var shared []string
+	for _, tag := range []string{models.TemperamentFriendly, models.TemperamentPlayful, models.TemperamentCalm, models.TemperamentGentle} {
+		if has[tag] && otherHas[tag] {
+			score += 5
+			shared = append(shared, tag)
+		}
+	}
+
+	conflicts := 0
+	for _, pair := range temperamentConflicts {
+		if (has[pair[0]] && otherHas[pair[1]]) || (has[pair[1]] && otherHas[pair[0]]) {
+			conflicts++
+		}
+	}
+	score -= conflicts * 10
+
+	return int(math.Max(0, math.Min(25, float64(score)))), shared, conflicts
+}
+
+// petEnergy returns a pet's energy level, parsing it from the character text for pets saved before tags existed
+func petEnergy(pet *models.Pet) string {
+	if pet.Energy != "" {
+		return pet.Energy
+	}
+	energy, _ := ParseCharacterTags(pet.Character)
+	return energy
+}
+
+// petTemperament returns a pet's temperament tags, parsing them from the character text for pets saved before tags existed
+func petTemperament(pet *models.Pet) []string {
+	if len(pet.Temperament) > 0 {
+		return pet.Temperament
+	}
+	_, tags := ParseCharacterTags(pet.Character)
+	return tags
+}
+
+// petStage returns the age stage of a pet, or false if its age is unknown
+func petStage(pet *models.Pet, now time.Time) (string, bool) {
+	months, ok := pet.AgeInMonths(now)
+	switch {
+	case !ok:
+		return "", false
+	case months < 12:
+		return models.PetStagePuppy, true
+	case months >= seniorAgeYears*12:
+		return models.PetStageSenior, true
+	}
+	return models.PetStageAdult, true
+}
+
+// playmateProfile returns the public details of a suggested pet, leaving out its members
+func playmateProfile(pet *models.Pet, now time.Time) models.PlaymateProfile {
+	profile := models.PlaymateProfile{
+		ID:          pet.ID,
+		Name:        pet.Name,
+		Species:     pet.Species,
+		Size:        pet.Size,
+		Breed:       pet.Breed,
+		Avatar:      pet.Avatar,
+		CoverPhoto:  pet.CoverPhoto,
+		Energy:      petEnergy(pet),
+		Temperament: petTemperament(pet),
+	}
+	if months, ok := pet.AgeInMonths(now); ok {
+		years := months / 12
+		profile.Age = &years
+	}
+	return profile
+}
+
+// roundedDistanceKm rounds a distance up to the next whole kilometer so it cannot be used to locate a home
+func roundedDistanceKm(meters float64) int {
+	return int(math.Max(1, math.Ceil(meters/1000)))
+}
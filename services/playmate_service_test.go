@@ -0,0 +1,63 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCharacter(t *testing.T) {
+	tests := []struct {
+		name        string
+		character   string
+		wantWords   []string
+		wantPhrases []string
+	}{
+		{name: "empty", character: ""},
+		{name: "english", character: "Very Friendly, not shy!", wantWords: []string{"very", "friendly", "not", "shy"}},
+		{name: "chinese", character: "很乖，有点胆小", wantPhrases: []string{"很乖", "有点胆小"}},
+		{name: "mixed", character: "超级active的dog狗", wantWords: []string{"active", "dog"}, wantPhrases: []string{"超级", "的", "狗"}},
+		{name: "digits separate", character: "3岁lazy2", wantWords: []string{"lazy"}, wantPhrases: []string{"岁"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, phrases := splitCharacter(tt.character)
+			if !reflect.DeepEqual(words, tt.wantWords) {
+				t.Errorf("words = %q, want %q", words, tt.wantWords)
+			}
+			if !reflect.DeepEqual(phrases, tt.wantPhrases) {
+				t.Errorf("phrases = %q, want %q", phrases, tt.wantPhrases)
+			}
+		})
+	}
+}
+
+func TestMatchCharacterKeyword(t *testing.T) {
+	tests := []struct {
+		name      string
+		keyword   string
+		character string
+		want      bool
+	}{
+		{name: "english word", keyword: "shy", character: "A bit shy at first", want: true},
+		{name: "english negated", keyword: "shy", character: "not shy at all", want: false},
+		{name: "english inside a longer word", keyword: "active", character: "hyperactive", want: false},
+		{name: "english case insensitive", keyword: "friendly", character: "FRIENDLY", want: true},
+		{name: "chinese inside a phrase", keyword: "胆小", character: "有点胆小", want: true},
+		{name: "chinese negated", keyword: "活泼", character: "不活泼", want: false},
+		{name: "chinese negated then repeated", keyword: "活泼", character: "不活泼但很活泼", want: true},
+		{name: "single character alone", keyword: "乖", character: "乖，亲人", want: true},
+		{name: "single character after a degree word", keyword: "乖", character: "很乖", want: true},
+		{name: "single character inside another word", keyword: "懒", character: "懒得理人的样子", want: false},
+		{name: "single character negated", keyword: "凶", character: "不凶", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, phrases := splitCharacter(tt.character)
+			if got := matchCharacterKeyword(tt.keyword, words, phrases); got != tt.want {
+				t.Errorf("matchCharacterKeyword(%q, %q) = %v, want %v", tt.keyword, tt.character, got, tt.want)
+			}
+		})
+	}
+}
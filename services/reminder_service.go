@@ -0,0 +1,361 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"playtime-go/config"
+	"playtime-go/db"
+	"playtime-go/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	reminderLogCollection = "reminder_logs"
+	reminderLookahead     = 3 * 24 * time.Hour  // remind this long before an item is due
+	reminderOverdueWindow = 30 * 24 * time.Hour // stop reminding about items overdue longer than this
+)
+
+// Reminder stages, a due item is reminded once before and once after its due date
+const (
+	reminderStageUpcoming = "upcoming"
+	reminderStageOverdue  = "overdue"
+)
+
+// dueItem is a single reminder waiting to be delivered
+type dueItem struct {
+	UserID   primitive.ObjectID
+	PetID    primitive.ObjectID
+	RecordID primitive.ObjectID
+	PetName  string
+	ItemName string
+	Kind     string
+	DueDate  time.Time
+}
+
+// StartReminderScheduler runs a reminder sweep immediately and then on every interval
+func StartReminderScheduler(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if sent, err := RunReminderSweep(time.Now()); err != nil {
+				log.Printf("Reminder sweep failed: %v", err)
+			} else {
+				log.Printf("Reminder sweep finished, %d reminders delivered", sent)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// RunReminderSweep finds due and overdue items and delivers reminders for them
+func RunReminderSweep(now time.Time) (int, error) {
+	items, err := findDueHealthItems(now)
+	if err != nil {
+		return 0, err
+	}
+
+	birthdays, err := findDueBirthdays(now)
+	if err != nil {
+		return 0, err
+	}
+	items = append(items, birthdays...)
+
+	sent := 0
+	for _, item := range items {
+		delivered, err := deliverReminder(item, now)
+		if err != nil {
+			log.Printf("Failed to deliver %s reminder for pet %s: %v", item.Kind, item.PetID.Hex(), err)
+			continue
+		}
+		if delivered {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// findDueHealthItems collects vaccination and deworming records whose next due date is close or passed
+func findDueHealthItems(now time.Time) ([]dueItem, error) {
+	filter := bson.M{
+		"type": bson.M{"$in": []string{models.HealthRecordVaccination, models.HealthRecordDeworming}},
+		"nextDueDate": bson.M{
+			"$gte": now.Add(-reminderOverdueWindow),
+			"$lte": now.Add(reminderLookahead),
+		},
+	}
+	var records []models.HealthRecord
+
+	if err := FindMany(healthCollection, filter, &records); err != nil {
+		return nil, fmt.Errorf("failed to find due health records: %v", err)
+	}
+
+	items := make([]dueItem, 0, len(records))
+	for _, record := range records {
+		// A later record of the same kind means the follow-up already happened
+		laterFilter := bson.M{
+			"petId": record.PetID,
+			"type":  record.Type,
+			"date":  bson.M{"$gt": record.Date},
+		}
+		if record.Type == models.HealthRecordVaccination {
+			laterFilter["vaccineName"] = record.VaccineName
+		}
+		later, err := Count(healthCollection, laterFilter)
+		if err != nil {
+			return nil, err
+		}
+		if later > 0 {
+			continue
+		}
+
+		pet, err := GetPetByID(record.PetID)
+		if err != nil {
+			log.Printf("Skipping health record %s: %v", record.ID.Hex(), err)
+			continue
+		}
+
+		itemName := record.VaccineName
+		if record.Type == models.HealthRecordDeworming {
+			itemName = record.Product
+			if itemName == "" {
+				itemName = "Deworming"
+			}
+		}
+
+		for _, userID := range petCaretakers(pet, now) {
+			items = append(items, dueItem{
+				UserID:   userID,
+				PetID:    pet.ID,
+				RecordID: record.ID,
+				PetName:  pet.Name,
+				ItemName: itemName,
+				Kind:     record.Type,
+				DueDate:  *record.NextDueDate,
+			})
+		}
+	}
+
+	return items, nil
+}
+
+// findDueBirthdays collects pets with an exact birthdate whose birthday falls within the lookahead window
+func findDueBirthdays(now time.Time) ([]dueItem, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	// Match on month and day of the birthdate for every day of the window
+	days := bson.A{}
+	for day := today; !day.After(today.Add(reminderLookahead)); day = day.AddDate(0, 0, 1) {
+		days = append(days, bson.M{"$and": bson.A{
+			bson.M{"$eq": bson.A{bson.M{"$month": "$birthDate"}, int(day.Month())}},
+			bson.M{"$eq": bson.A{bson.M{"$dayOfMonth": "$birthDate"}, day.Day()}},
+		}})
+	}
+
+	filter := bson.M{
+		"birthDate":            bson.M{"$ne": nil},
+		"birthDateApproximate": false,
+		"$expr":                bson.M{"$or": days},
+	}
+	var pets []models.Pet
+
+	if err := FindMany(petCollection, filter, &pets); err != nil {
+		return nil, fmt.Errorf("failed to find pet birthdays: %v", err)
+	}
+
+	items := make([]dueItem, 0, len(pets))
+	for _, pet := range pets {
+		birth := pet.BirthDate.UTC()
+		birthday := time.Date(today.Year(), birth.Month(), birth.Day(), 0, 0, 0, 0, time.UTC)
+		if birthday.Before(today) {
+			birthday = birthday.AddDate(1, 0, 0)
+		}
+
+		for _, userID := range petCaretakers(&pet, now) {
+			items = append(items, dueItem{
+				UserID:   userID,
+				PetID:    pet.ID,
+				PetName:  pet.Name,
+				ItemName: fmt.Sprintf("Birthday, turning %d", birthday.Year()-birth.Year()),
+				Kind:     models.ReminderKindBirthday,
+				DueDate:  birthday,
+			})
+		}
+	}
+
+	return items, nil
+}
+
+// deliverReminder sends a reminder unless it was already delivered or the user has not subscribed
+func deliverReminder(item dueItem, now time.Time) (bool, error) {
+	// Birthdays are only reminded ahead of time
+	stage := reminderStageUpcoming
+	if item.Kind != models.ReminderKindBirthday && item.DueDate.Before(now) {
+		stage = reminderStageOverdue
+	}
+
+	templateID := GetReminderTemplates()[item.Kind]
+	if templateID == "" {
+		return false, nil
+	}
+
+	subscription, err := getActiveSubscription(item.UserID, item.Kind)
+	if err != nil || subscription == nil {
+		return false, err
+	}
+
+	user, err := GetUserByID(item.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	note := "Due soon"
+	if stage == reminderStageOverdue {
+		note = "Overdue"
+	}
+
+	message := models.SubscribeMessageRequest{
+		ToUser:     user.OpenID,
+		TemplateID: templateID,
+		Page:       fmt.Sprintf("pages/pet/detail?id=%s", item.PetID.Hex()),
+		Data:       templateMessageData(item.Kind, item.PetName, item.ItemName, item.DueDate.Format("2006-01-02"), note),
+	}
+
+	reminderLog := models.ReminderLog{
+		UserID:     item.UserID,
+		PetID:      item.PetID,
+		RecordID:   item.RecordID,
+		Kind:       item.Kind,
+		Stage:      stage,
+		TemplateID: templateID,
+		DueDate:    item.DueDate,
+		Status:     models.ReminderStatusSending,
+		SentAt:     now,
+		Claimed:    true,
+	}
+
+	// Each stage of a due item is only reminded once per recipient, the unique index
+	// lets exactly one sweep claim it and only that sweep sends the message
+	logID, claimed, err := claimReminder(reminderLog)
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	response, sendErr := SendSubscribeMessage(message)
+	update := bson.M{}
+	switch {
+	case sendErr != nil:
+		// Releasing the claim lets the next sweep retry
+		update["$set"] = bson.M{"status": models.ReminderStatusFailed, "errCode": response.ErrCode, "errMsg": sendErr.Error()}
+		update["$unset"] = bson.M{"claimed": ""}
+	case config.GetConfig().ReminderDryRun:
+		update["$set"] = bson.M{"status": models.ReminderStatusDryRun}
+	default:
+		update["$set"] = bson.M{"status": models.ReminderStatusSent}
+	}
+
+	if err := UpdateOne(reminderLogCollection, bson.M{"_id": logID}, update); err != nil {
+		return false, fmt.Errorf("failed to write reminder log: %v", err)
+	}
+	if sendErr != nil {
+		return false, sendErr
+	}
+
+	if err := consumeSubscription(subscription.ID); err != nil {
+		log.Printf("Failed to consume subscription %s: %v", subscription.ID.Hex(), err)
+	}
+
+	return true, nil
+}
+
+// claimReminder inserts the log of a reminder that is about to be sent.
+// It reports false when another delivery of the same reminder already holds the claim.
+func claimReminder(reminderLog models.ReminderLog) (primitive.ObjectID, bool, error) {
+	collection := db.GetCollection(reminderLogCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := collection.InsertOne(ctx, reminderLog)
+	if mongo.IsDuplicateKeyError(err) {
+		return primitive.NilObjectID, false, nil
+	}
+	if err != nil {
+		return primitive.NilObjectID, false, fmt.Errorf("failed to write reminder log: %v", err)
+	}
+
+	logID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return primitive.NilObjectID, false, fmt.Errorf("failed to get inserted ID")
+	}
+	return logID, true, nil
+}
+
+// EnsureReminderLogIndexes creates the unique index that allows one claimed log per reminder
+func EnsureReminderLogIndexes() error {
+	// Logs written before claims existed still block their reminder
+	if _, err := UpdateMany(reminderLogCollection, bson.M{
+		"status":  bson.M{"$in": []string{models.ReminderStatusSent, models.ReminderStatusDryRun}},
+		"claimed": bson.M{"$exists": false},
+	}, bson.M{"$set": bson.M{"claimed": true}}); err != nil {
+		return fmt.Errorf("failed to migrate reminder logs: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := db.GetCollection(reminderLogCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "userId", Value: 1},
+				{Key: "petId", Value: 1},
+				{Key: "recordId", Value: 1},
+				{Key: "kind", Value: 1},
+				{Key: "stage", Value: 1},
+				{Key: "dueDate", Value: 1},
+			},
+			Options: options.Index().
+				SetName("reminder_claim_unique").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"claimed": true}),
+		},
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "sentAt", Value: -1}},
+			Options: options.Index().SetName("userId_sentAt"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create reminder log indexes: %v", err)
+	}
+
+	return nil
+}
+
+// ListReminderLogs retrieves the reminder delivery logs for a user
+func ListReminderLogs(userID primitive.ObjectID, limit int64) ([]models.ReminderLog, error) {
+	filter := bson.M{"userId": userID}
+	var logs []models.ReminderLog
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "sentAt", Value: -1}})
+
+	if limit > 0 {
+		findOptions.SetLimit(limit)
+	} else {
+		findOptions.SetLimit(100) // Default limit
+	}
+
+	err := FindMany(reminderLogCollection, filter, &logs, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reminder logs: %v", err)
+	}
+
+	return logs, nil
+}
@@ -0,0 +1,575 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"playtime-go/config"
+	"playtime-go/db"
+	"playtime-go/models"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	reportCollection           = "reports"
+	moderationActionCollection = "moderation_actions"
+
+	// maxReportDetails is the longest free text a reporter can send
+	maxReportDetails = 500
+)
+
+// CreateReport files the user's report against a review, place, pet or user.
+// Reporting the same target again while the first report is open returns the open report.
+func CreateReport(reporterID primitive.ObjectID, request models.ReportRequest) (*models.Report, error) {
+	if !isValidReportReason(request.Reason) {
+		return nil, fmt.Errorf("invalid report: unknown reason %q", request.Reason)
+	}
+	details := strings.TrimSpace(request.Details)
+	if len([]rune(details)) > maxReportDetails {
+		return nil, fmt.Errorf("invalid report: details can be at most %d characters", maxReportDetails)
+	}
+	reporter, err := reportingUser(reporterID)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerID, err := reportTargetOwner(request.TargetType, request.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if ownerID == reporterID {
+		return nil, fmt.Errorf("invalid report: cannot report your own content")
+	}
+
+	if existing, err := findOpenReport(reporterID, request.TargetType, request.TargetID); err == nil {
+		return existing, nil
+	}
+
+	report := models.Report{
+		TargetType: request.TargetType,
+		TargetID:   request.TargetID,
+		ReporterID: reporterID,
+		Reason:     request.Reason,
+		Details:    details,
+		Status:     models.ReportOpen,
+		Trusted:    isEstablishedAccount(reporter, time.Now()),
+		CreatedAt:  time.Now(),
+	}
+
+	id, err := InsertOne(reportCollection, report)
+	if err != nil {
+		// A concurrent duplicate report lost the race against the unique open report index
+		if mongo.IsDuplicateKeyError(err) {
+			return findOpenReport(reporterID, request.TargetType, request.TargetID)
+		}
+		return nil, fmt.Errorf("failed to create report: %v", err)
+	}
+	report.ID = id
+
+	if err := autoHideReportedContent(request.TargetType, request.TargetID); err != nil {
+		log.Printf("Failed to auto-hide reported %s %s: %v", request.TargetType, request.TargetID.Hex(), err)
+	}
+
+	return &report, nil
+}
+
+// reportingUser returns the user filing a report, who must exist and not be banned
+func reportingUser(reporterID primitive.ObjectID) (*models.User, error) {
+	reporter, err := GetUserByID(reporterID)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "no user found") {
+			return nil, fmt.Errorf("not authorized to report: unknown user %s", reporterID.Hex())
+		}
+		return nil, err
+	}
+	if reporter.Banned {
+		return nil, fmt.Errorf("user is banned: %s", reporterID.Hex())
+	}
+	return reporter, nil
+}
+
+// isEstablishedAccount reports whether a user's account is old enough for their reports to count towards an auto-hide.
+// Accounts created before sign-up times were recorded count as established.
+func isEstablishedAccount(user *models.User, now time.Time) bool {
+	minAge := time.Duration(config.GetConfig().ReportMinAccountAgeHours) * time.Hour
+	return user.CreatedAt.IsZero() || !user.CreatedAt.After(now.Add(-minAge))
+}
+
+// isValidReportReason reports whether reason is a supported report reason code
+func isValidReportReason(reason string) bool {
+	switch reason {
+	case models.ReportReasonSpam, models.ReportReasonHarassment, models.ReportReasonInappropriate,
+		models.ReportReasonMisleading, models.ReportReasonAnimalWelfare, models.ReportReasonOther:
+		return true
+	}
+	return false
+}
+
+// reportTargetOwner checks that a reported target exists and returns the user responsible for it
+func reportTargetOwner(targetType string, targetID primitive.ObjectID) (primitive.ObjectID, error) {
+	switch targetType {
+	case models.ContentTypeReview:
+		review, err := GetReview(targetID)
+		if err != nil {
+			return primitive.NilObjectID, err
+		}
+		ownerID, _ := primitive.ObjectIDFromHex(review.UserID)
+		return ownerID, nil
+	case models.ContentTypePlace:
+		location, err := GetLocationByID(targetID)
+		if err != nil {
+			return primitive.NilObjectID, err
+		}
+		return location.CreatedBy, nil
+	case models.ContentTypePet:
+		pet, err := GetPetByID(targetID)
+		if err != nil {
+			return primitive.NilObjectID, err
+		}
+		return pet.OwnerID, nil
+	case models.ContentTypeUser:
+		user, err := GetUserByID(targetID)
+		if err != nil {
+			return primitive.NilObjectID, err
+		}
+		return user.ID, nil
+	}
+	return primitive.NilObjectID, fmt.Errorf("invalid content type: %s", targetType)
+}
+
+// findOpenReport returns the reporter's open report against a target
+func findOpenReport(reporterID primitive.ObjectID, targetType string, targetID primitive.ObjectID) (*models.Report, error) {
+	filter := bson.M{"reporterId": reporterID, "targetType": targetType, "targetId": targetID, "status": models.ReportOpen}
+	var report models.Report
+	if err := FindOne(reportCollection, filter, &report); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no report found for %s: %s", targetType, targetID.Hex())
+		}
+		return nil, fmt.Errorf("failed to get report: %v", err)
+	}
+	return &report, nil
+}
+
+// autoHideReportedContent hides content once its open reports from established accounts reach the configured threshold.
+// Users are never hidden automatically, they stay in the queue until a moderator acts.
+func autoHideReportedContent(targetType string, targetID primitive.ObjectID) error {
+	threshold := config.GetConfig().ReportHideThreshold
+	if threshold <= 0 || targetType == models.ContentTypeUser {
+		return nil
+	}
+
+	// Reports filed before accounts were checked have no trusted flag and still count
+	filter := bson.M{"targetType": targetType, "targetId": targetID, "status": models.ReportOpen, "trusted": bson.M{"$ne": false}}
+	open, err := Count(reportCollection, filter)
+	if err != nil {
+		return fmt.Errorf("failed to count reports: %v", err)
+	}
+	if open < int64(threshold) {
+		return nil
+	}
+
+	hidden, err := hideContent(targetType, targetID, true)
+	if err != nil || !hidden {
+		return err
+	}
+
+	recordModerationAction(nil, models.ModerationActionAutoHide, targetType, targetID, "", open)
+	return nil
+}
+
+// hideContent sets the moderation status of content to hidden and reports whether it changed.
+// Content hidden by reports is marked and keeps its previous status so that dismissing the reports
+// can restore it, a moderator hiding content takes over an earlier automatic hide.
+func hideContent(contentType string, id primitive.ObjectID, byReports bool) (bool, error) {
+	collection, err := contentCollection(contentType)
+	if err != nil {
+		return false, err
+	}
+
+	filter := bson.M{"_id": id, "moderationStatus": bson.M{"$ne": models.ModerationHidden}}
+	if !byReports {
+		filter = bson.M{"_id": id, "$or": []bson.M{
+			{"moderationStatus": bson.M{"$ne": models.ModerationHidden}},
+			{"hiddenByReports": true},
+		}}
+	}
+	var update interface{} = bson.M{
+		"$set":   bson.M{"moderationStatus": models.ModerationHidden, "hiddenByReports": false},
+		"$unset": bson.M{"statusBeforeHide": ""},
+	}
+	if byReports {
+		update = mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{
+				"statusBeforeHide": "$moderationStatus",
+				"moderationStatus": models.ModerationHidden,
+				"hiddenByReports":  true,
+			}}},
+		}
+	}
+	changed, err := UpdateMany(collection, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to hide content: %v", err)
+	}
+
+	// Hidden reviews and pets leave followers' feeds
+	if changed > 0 {
+		removeFeedActivity(id)
+	}
+
+	return changed > 0, nil
+}
+
+// ListUserReports lists the reports the user has filed, newest first
+func ListUserReports(reporterID primitive.ObjectID, limit int64) ([]models.Report, error) {
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	if limit > 0 {
+		findOptions.SetLimit(limit)
+	} else {
+		findOptions.SetLimit(100) // Default limit
+	}
+
+	reports := []models.Report{}
+	if err := FindMany(reportCollection, bson.M{"reporterId": reporterID}, &reports, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list reports: %v", err)
+	}
+
+	return reports, nil
+}
+
+// ListReportQueue lists reported targets with open reports, most reported first
+func ListReportQueue(moderatorID primitive.ObjectID, targetType string, limit int64) ([]models.ReportCase, error) {
+	if !IsModerator(moderatorID) {
+		return nil, fmt.Errorf("not authorized to moderate content")
+	}
+	if limit <= 0 {
+		limit = 100 // Default limit
+	}
+
+	match := bson.M{"status": models.ReportOpen}
+	if targetType != "" {
+		if _, err := reportTargetCollection(targetType); err != nil {
+			return nil, err
+		}
+		match["targetType"] = targetType
+	}
+
+	collection := db.GetCollection(reportCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	pipeline := []bson.D{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":             bson.M{"targetType": "$targetType", "targetId": "$targetId"},
+			"openReports":     bson.M{"$sum": 1},
+			"reasons":         bson.M{"$addToSet": "$reason"},
+			"firstReportedAt": bson.M{"$min": "$createdAt"},
+			"lastReportedAt":  bson.M{"$max": "$createdAt"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "openReports", Value: -1}, {Key: "firstReportedAt", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: bson.M{
+			"_id":             0,
+			"targetType":      "$_id.targetType",
+			"targetId":        "$_id.targetId",
+			"openReports":     1,
+			"reasons":         1,
+			"firstReportedAt": 1,
+			"lastReportedAt":  1,
+		}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list report queue: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	cases := []models.ReportCase{}
+	if err := cursor.All(ctx, &cases); err != nil {
+		return nil, fmt.Errorf("failed to decode report queue: %v", err)
+	}
+
+	return cases, nil
+}
+
+// ListTargetReports lists every report filed against a target, newest first
+func ListTargetReports(moderatorID primitive.ObjectID, targetType string, targetID primitive.ObjectID) ([]models.Report, error) {
+	if !IsModerator(moderatorID) {
+		return nil, fmt.Errorf("not authorized to moderate content")
+	}
+	if _, err := reportTargetCollection(targetType); err != nil {
+		return nil, err
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	reports := []models.Report{}
+	if err := FindMany(reportCollection, bson.M{"targetType": targetType, "targetId": targetID}, &reports, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list reports: %v", err)
+	}
+
+	return reports, nil
+}
+
+// reportTargetCollection returns the collection holding a reportable target type
+func reportTargetCollection(targetType string) (string, error) {
+	if targetType == models.ContentTypeUser {
+		return userCollection, nil
+	}
+	return contentCollection(targetType)
+}
+
+// ApplyModerationAction dismisses the reports against a target, hides or deletes it, or bans the user behind it.
+// The target's open reports are resolved and the action is written to the audit trail.
+func ApplyModerationAction(moderatorID primitive.ObjectID, request models.ModerationActionRequest) (*models.ModerationAction, error) {
+	if !IsModerator(moderatorID) {
+		return nil, fmt.Errorf("not authorized to moderate content")
+	}
+
+	ownerID, err := reportTargetOwner(request.TargetType, request.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := models.ReportActioned
+	switch request.Action {
+	case models.ModerationActionDismiss:
+		status = models.ReportDismissed
+		if request.TargetType != models.ContentTypeUser {
+			if err := restoreHiddenContent(request.TargetType, request.TargetID); err != nil {
+				return nil, err
+			}
+		}
+	case models.ModerationActionHide:
+		if request.TargetType == models.ContentTypeUser {
+			return nil, fmt.Errorf("invalid moderation action: users cannot be hidden, ban them instead")
+		}
+		if _, err := hideContent(request.TargetType, request.TargetID, false); err != nil {
+			return nil, err
+		}
+	case models.ModerationActionDelete:
+		if err := deleteReportedContent(request.TargetType, request.TargetID); err != nil {
+			return nil, err
+		}
+	case models.ModerationActionBan:
+		if ownerID.IsZero() {
+			return nil, fmt.Errorf("invalid moderation action: no user to ban for %s", request.TargetType)
+		}
+		if err := banUser(ownerID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid moderation action: %s", request.Action)
+	}
+
+	reporterIDs, err := openReportReporters(request.TargetType, request.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := resolveReports(moderatorID, request.TargetType, request.TargetID, status, request.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		notifyModerationResult(ownerID, request.TargetType, request.TargetID, request.Action)
+		notifyReporters(reporterIDs, request.TargetType, request.TargetID, status)
+	}()
+
+	return recordModerationAction(&moderatorID, request.Action, request.TargetType, request.TargetID, request.Note, resolved), nil
+}
+
+// restoreHiddenContent gives content hidden by reports its previous status back after its reports are dismissed,
+// content that was still held for moderation goes back to the queue. Content a moderator hid, directly or by
+// banning its author, stays hidden.
+func restoreHiddenContent(contentType string, id primitive.ObjectID) error {
+	collection, err := contentCollection(contentType)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": id, "moderationStatus": models.ModerationHidden, "hiddenByReports": true}
+	update := mongo.Pipeline{
+		// Content that predates moderation has no status to go back to
+		{{Key: "$set", Value: bson.M{"moderationStatus": bson.M{"$ifNull": bson.A{"$statusBeforeHide", models.ModerationApproved}}}}},
+		{{Key: "$unset", Value: bson.A{"hiddenByReports", "statusBeforeHide"}}},
+	}
+	if _, err := UpdateMany(collection, filter, update); err != nil {
+		return fmt.Errorf("failed to restore content: %v", err)
+	}
+
+	return nil
+}
+
+// deleteReportedContent removes reported content the same way its author deleting it would
+func deleteReportedContent(contentType string, id primitive.ObjectID) error {
+	switch contentType {
+	case models.ContentTypeReview:
+		review, err := GetReview(id)
+		if err != nil {
+			return err
+		}
+		return deleteReview(review)
+	case models.ContentTypePlace:
+		return DeleteLocation(id)
+	case models.ContentTypePet:
+		if err := DeleteOne(petCollection, bson.M{"_id": id}); err != nil {
+			return fmt.Errorf("failed to delete pet: %v", err)
+		}
+		if err := deletePetPhotos(id); err != nil {
+			log.Printf("Failed to delete photos of pet %s: %v", id.Hex(), err)
+		}
+		removeFeedActivity(id)
+		return nil
+	}
+	return fmt.Errorf("invalid moderation action: %s cannot be deleted, ban the user instead", contentType)
+}
+
+// banUser stops a user from posting and hides all their reviews
+func banUser(userID primitive.ObjectID) error {
+	now := time.Now()
+	update := bson.M{"$set": bson.M{"banned": true, "bannedAt": now, "updatedAt": now}}
+	if err := UpdateOne(userCollection, bson.M{"_id": userID}, update); err != nil {
+		return fmt.Errorf("failed to ban user: %v", err)
+	}
+
+	filter := bson.M{"userId": userID.Hex()}
+	update = bson.M{
+		"$set":   bson.M{"moderationStatus": models.ModerationHidden, "hiddenByReports": false},
+		"$unset": bson.M{"statusBeforeHide": ""},
+	}
+	if _, err := UpdateMany(reviewCollection, filter, update); err != nil {
+		return fmt.Errorf("failed to hide reviews of banned user: %v", err)
+	}
+
+	return nil
+}
+
+// openReportReporters returns the users with an open report against a target
+func openReportReporters(targetType string, targetID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	var reports []models.Report
+	filter := bson.M{"targetType": targetType, "targetId": targetID, "status": models.ReportOpen}
+	if err := FindMany(reportCollection, filter, &reports); err != nil {
+		return nil, fmt.Errorf("failed to list open reports: %v", err)
+	}
+
+	reporterIDs := make([]primitive.ObjectID, 0, len(reports))
+	for _, report := range reports {
+		reporterIDs = append(reporterIDs, report.ReporterID)
+	}
+	return reporterIDs, nil
+}
+
+// resolveReports closes the open reports against a target and returns how many were closed
+func resolveReports(moderatorID primitive.ObjectID, targetType string, targetID primitive.ObjectID, status string, resolution string) (int64, error) {
+	filter := bson.M{"targetType": targetType, "targetId": targetID, "status": models.ReportOpen}
+	update := bson.M{"$set": bson.M{
+		"status":     status,
+		"resolution": resolution,
+		"resolvedBy": moderatorID,
+		"resolvedAt": time.Now(),
+	}}
+
+	resolved, err := UpdateMany(reportCollection, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve reports: %v", err)
+	}
+
+	return resolved, nil
+}
+
+// recordModerationAction writes an entry to the moderation audit trail, a failed write is only logged
+func recordModerationAction(moderatorID *primitive.ObjectID, action string, targetType string, targetID primitive.ObjectID, note string, reports int64) *models.ModerationAction {
+	entry := models.ModerationAction{
+		ModeratorID: moderatorID,
+		Action:      action,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		Note:        strings.TrimSpace(note),
+		Reports:     reports,
+		CreatedAt:   time.Now(),
+	}
+
+	id, err := InsertOne(moderationActionCollection, entry)
+	if err != nil {
+		log.Printf("Failed to record moderation action %s on %s %s: %v", action, targetType, targetID.Hex(), err)
+	}
+	entry.ID = id
+
+	return &entry
+}
+
+// ListModerationActions lists the audit trail newest first, optionally only for one target
+func ListModerationActions(moderatorID primitive.ObjectID, targetType string, targetID *primitive.ObjectID, limit int64) ([]models.ModerationAction, error) {
+	if !IsModerator(moderatorID) {
+		return nil, fmt.Errorf("not authorized to moderate content")
+	}
+
+	filter := bson.M{}
+	if targetType != "" {
+		filter["targetType"] = targetType
+	}
+	if targetID != nil {
+		filter["targetId"] = *targetID
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	if limit > 0 {
+		findOptions.SetLimit(limit)
+	} else {
+		findOptions.SetLimit(100) // Default limit
+	}
+
+	actions := []models.ModerationAction{}
+	if err := FindMany(moderationActionCollection, filter, &actions, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list moderation actions: %v", err)
+	}
+
+	return actions, nil
+}
+
+// EnsureReportIndexes creates the report deduplication and queue indexes and the audit trail index
+func EnsureReportIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := db.GetCollection(reportCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			// A reporter has at most one open report per target
+			Keys: bson.D{{Key: "reporterId", Value: 1}, {Key: "targetType", Value: 1}, {Key: "targetId", Value: 1}},
+			Options: options.Index().SetName("reporter_target_open_unique").SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": models.ReportOpen}),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "targetType", Value: 1}, {Key: "targetId", Value: 1}},
+			Options: options.Index().SetName("status_target"),
+		},
+		{
+			Keys:    bson.D{{Key: "reporterId", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("reporterId_createdAt"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create report indexes: %v", err)
+	}
+
+	_, err = db.GetCollection(moderationActionCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "targetType", Value: 1}, {Key: "targetId", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index().SetName("target_createdAt"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create moderation action indexes: %v", err)
+	}
+
+	return nil
+}
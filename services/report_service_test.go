@@ -0,0 +1,34 @@
+package services
+
+import (
+	"playtime-go/config"
+	"playtime-go/models"
+	"testing"
+	"time"
+)
+
+func TestIsEstablishedAccount(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	minAge := time.Duration(config.GetConfig().ReportMinAccountAgeHours) * time.Hour
+
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		want      bool
+	}{
+		{name: "sign-up time not recorded", createdAt: time.Time{}, want: true},
+		{name: "old account", createdAt: now.Add(-minAge - 30*24*time.Hour), want: true},
+		{name: "exactly the minimum age", createdAt: now.Add(-minAge), want: true},
+		{name: "just short of the minimum age", createdAt: now.Add(-minAge + time.Second), want: false},
+		{name: "created now", createdAt: now, want: minAge <= 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &models.User{CreatedAt: tt.createdAt}
+			if got := isEstablishedAccount(user, now); got != tt.want {
+				t.Errorf("isEstablishedAccount(createdAt %v) = %v, want %v", tt.createdAt, got, tt.want)
+			}
+		})
+	}
+}
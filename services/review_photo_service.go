@@ -0,0 +1,371 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif" // register GIF decoding for thumbnails
+	"image/jpeg"
+	_ "image/png" // register PNG decoding for thumbnails
+	"io"
+	"log"
+	"net/url"
+	"path"
+	"playtime-go/config"
+	"playtime-go/db"
+	"playtime-go/models"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// thumbnailSize is the longest edge of a generated thumbnail in pixels
+	thumbnailSize = 320
+
+	// maxThumbnailSourceBytes is the largest image downloaded to create a thumbnail
+	maxThumbnailSourceBytes = 20 * 1024 * 1024
+
+	// maxThumbnailSourcePixels is the largest image, in pixels, decoded to create a thumbnail
+	maxThumbnailSourcePixels = 40 * 1000 * 1000
+
+	// ReviewUploadPrefix is the COS key prefix review photos are uploaded under
+	ReviewUploadPrefix = "review"
+)
+
+// reviewPhotosFromURLs validates photo URLs sent with a review and turns them into review photos.
+// Only review uploads the reviewer made themselves are accepted. Photos the review already has keep their thumbnails.
+func reviewPhotosFromURLs(userID primitive.ObjectID, urls []string, existing []models.ReviewPhoto) ([]models.ReviewPhoto, error) {
+	if len(urls) > models.MaxReviewPhotos {
+		return nil, fmt.Errorf("invalid photo: a review can have at most %d photos", models.MaxReviewPhotos)
+	}
+
+	known := make(map[string]models.ReviewPhoto, len(existing))
+	for _, photo := range existing {
+		known[photo.URL] = photo
+	}
+
+	photos := make([]models.ReviewPhoto, 0, len(urls))
+	seen := make(map[string]bool, len(urls))
+	var newKeys []string
+	for _, rawURL := range urls {
+		if seen[rawURL] {
+			continue
+		}
+		seen[rawURL] = true
+
+		if photo, ok := known[rawURL]; ok {
+			photos = append(photos, photo)
+			continue
+		}
+
+		key, err := cosKeyFromURL(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		if !isReviewUploadKey(key) {
+			return nil, fmt.Errorf("invalid photo: %s is not an uploaded file", rawURL)
+		}
+		photos = append(photos, models.ReviewPhoto{URL: rawURL, Key: key})
+		newKeys = append(newKeys, key)
+	}
+
+	// New photos must be the reviewer's own uploads
+	owned, err := ownedUploadKeys(userID, newKeys)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range newKeys {
+		if !owned[key] {
+			return nil, fmt.Errorf("invalid photo: %s is not an uploaded file", key)
+		}
+	}
+
+	return photos, nil
+}
+
+// isReviewUploadKey reports whether a COS key lies under the review upload prefix
+func isReviewUploadKey(key string) bool {
+	return strings.HasPrefix(key, ReviewUploadPrefix+"/") && path.Clean(key) == key
+}
+
+// reviewThumbnailKey returns the COS key of the thumbnail created for a review photo
+func reviewThumbnailKey(key string) string {
+	return strings.TrimSuffix(key, path.Ext(key)) + "_thumb.jpg"
+}
+
+// cosKeyFromURL returns the object key of a public URL in our COS bucket
+func cosKeyFromURL(rawURL string) (string, error) {
+	bucket, err := url.Parse(config.GetConfig().COSBucketURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid COS bucket URL: %v", err)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || !strings.EqualFold(u.Host, bucket.Host) {
+		return "", fmt.Errorf("invalid photo: %s is not an uploaded file", rawURL)
+	}
+
+	key := strings.TrimPrefix(path.Clean(u.Path), "/")
+	if key == "" || key == "." || u.RawQuery != "" {
+		return "", fmt.Errorf("invalid photo: %s is not an uploaded file", rawURL)
+	}
+
+	return key, nil
+}
+
+// cosObjectURL returns the public URL of an object in our COS bucket
+func cosObjectURL(key string) (string, error) {
+	bucket, err := url.Parse(config.GetConfig().COSBucketURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid COS bucket URL: %v", err)
+	}
+	return fmt.Sprintf("https://%s/%s", bucket.Host, key), nil
+}
+
+// generateReviewThumbnails creates the missing thumbnails of a review's photos.
+// It runs after the review is saved, a photo whose thumbnail fails is shown at full size.
+func generateReviewThumbnails(reviewID primitive.ObjectID, photos []models.ReviewPhoto) {
+	for _, photo := range photos {
+		// A held image gets its thumbnail once a moderator approves it
+		if photo.ThumbnailURL != "" || isMediaHeld(photo.URL) {
+			continue
+		}
+
+		thumbnailKey, thumbnailURL, err := createThumbnail(photo.Key)
+		if err != nil {
+			log.Printf("Failed to create thumbnail for %s: %v", photo.Key, err)
+			continue
+		}
+
+		filter := bson.M{"_id": reviewID, "photos.key": photo.Key}
+		update := bson.M{"$set": bson.M{
+			"photos.$.thumbnailUrl": thumbnailURL,
+			"photos.$.thumbnailKey": thumbnailKey,
+		}}
+		if err := UpdateOne(reviewCollection, filter, update); err != nil {
+			log.Printf("Failed to save thumbnail for %s: %v", photo.Key, err)
+		}
+	}
+}
+
+// createThumbnail downloads an image from COS, scales it down and uploads it next to the original as a JPEG
+func createThumbnail(key string) (string, string, error) {
+	cosClient, _, err := newCOSClient()
+	if err != nil {
+		return "", "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := cosClient.Object.Get(ctx, key, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to download image: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxThumbnailSourceBytes+1))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to download image: %v", err)
+	}
+	if len(data) > maxThumbnailSourceBytes {
+		return "", "", fmt.Errorf("image is larger than %d bytes", maxThumbnailSourceBytes)
+	}
+
+	// Check the dimensions before decoding, a small file can declare a huge image
+	imageConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode image: %v", err)
+	}
+	if imageConfig.Width <= 0 || imageConfig.Height <= 0 ||
+		int64(imageConfig.Width)*int64(imageConfig.Height) > maxThumbnailSourcePixels {
+		return "", "", fmt.Errorf("image of %dx%d pixels is too large", imageConfig.Width, imageConfig.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode image: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, scaleImage(src, thumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		return "", "", fmt.Errorf("failed to encode thumbnail: %v", err)
+	}
+
+	thumbnailKey := reviewThumbnailKey(key)
+	if err := PutObjectToCOS(thumbnailKey, &buf, "image/jpeg"); err != nil {
+		return "", "", err
+	}
+
+	thumbnailURL, err := cosObjectURL(thumbnailKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	return thumbnailKey, thumbnailURL, nil
+}
+
+// scaleImage shrinks an image to fit in a size by size box, averaging the source pixels behind each target pixel
+func scaleImage(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return src
+	}
+
+	dstWidth, dstHeight := size, height*size/width
+	if height > width {
+		dstWidth, dstHeight = width*size/height, size
+	}
+	if dstWidth < 1 {
+		dstWidth = 1
+	}
+	if dstHeight < 1 {
+		dstHeight = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0 := bounds.Min.Y + y*height/dstHeight
+		y1 := bounds.Min.Y + (y+1)*height/dstHeight
+		for x := 0; x < dstWidth; x++ {
+			x0 := bounds.Min.X + x*width/dstWidth
+			x1 := bounds.Min.X + (x+1)*width/dstWidth
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(b / n >> 8)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+
+	return dst
+}
+
+// deleteReviewPhotoObjects removes review photos and their thumbnails from COS.
+// Only review uploads the reviewer made are deleted, anything else a review points at is left alone.
+func deleteReviewPhotoObjects(userID string, photos []models.ReviewPhoto) {
+	reviewerID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return
+	}
+
+	keys := make([]string, 0, len(photos))
+	for _, photo := range photos {
+		if isReviewUploadKey(photo.Key) {
+			keys = append(keys, photo.Key)
+		}
+	}
+
+	owned, err := ownedUploadKeys(reviewerID, keys)
+	if err != nil {
+		log.Printf("Failed to look up review photo uploads: %v", err)
+		return
+	}
+
+	var deleted []string
+	for _, photo := range photos {
+		if !owned[photo.Key] {
+			continue
+		}
+		if err := DeleteObjectFromCOS(photo.Key); err != nil {
+			log.Printf("Failed to delete review photo %s: %v", photo.Key, err)
+			continue
+		}
+		deleted = append(deleted, photo.Key)
+
+		if photo.ThumbnailKey == reviewThumbnailKey(photo.Key) {
+			if err := DeleteObjectFromCOS(photo.ThumbnailKey); err != nil {
+				log.Printf("Failed to delete review photo %s: %v", photo.ThumbnailKey, err)
+			}
+		}
+	}
+
+	if err := deleteUploadRecords(deleted...); err != nil {
+		log.Printf("Failed to delete review photo uploads: %v", err)
+	}
+}
+
+// removedReviewPhotos returns the photos in before that are no longer in after
+func removedReviewPhotos(before []models.ReviewPhoto, after []models.ReviewPhoto) []models.ReviewPhoto {
+	kept := make(map[string]bool, len(after))
+	for _, photo := range after {
+		kept[photo.URL] = true
+	}
+
+	var removed []models.ReviewPhoto
+	for _, photo := range before {
+		if !kept[photo.URL] {
+			removed = append(removed, photo)
+		}
+	}
+	return removed
+}
+
+// ListPlaceReviewPhotos pages through the photos of all reviews of a place, newest review first
+func ListPlaceReviewPhotos(placeID string, page int64, limit int64) (*models.PlaceReviewPhotoPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 20 // Default limit
+	}
+
+	collection := db.GetCollection(reviewCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Fetch one extra photo to know whether another page follows
+	pipeline := []bson.D{
+		{{Key: "$match", Value: bson.M{"placeId": placeID, "photos.0": bson.M{"$exists": true}, "moderationStatus": VisibleContentFilter()}}},
+		{{Key: "$sort", Value: bson.D{{Key: "date", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$unwind", Value: "$photos"}},
+		{{Key: "$skip", Value: (page - 1) * limit}},
+		{{Key: "$limit", Value: limit + 1}},
+		{{Key: "$project", Value: bson.M{
+			"_id":          0,
+			"reviewId":     "$_id",
+			"userId":       1,
+			"userName":     1,
+			"date":         1,
+			"url":          "$photos.url",
+			"key":          "$photos.key",
+			"thumbnailUrl": "$photos.thumbnailUrl",
+			"thumbnailKey": "$photos.thumbnailKey",
+		}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list review photos: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	photos := []models.PlaceReviewPhoto{}
+	if err := cursor.All(ctx, &photos); err != nil {
+		return nil, fmt.Errorf("failed to decode review photos: %v", err)
+	}
+
+	result := &models.PlaceReviewPhotoPage{Page: page, Limit: limit}
+	if int64(len(photos)) > limit {
+		photos = photos[:limit]
+		result.HasMore = true
+	}
+	result.Photos = photos
+
+	return result, nil
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"playtime-go/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const maxReviewReplies = 50
+
+// AddReviewReply posts a reply in a review's thread.
+// Only the verified manager of the reviewed place and the original reviewer may reply.
+func AddReviewReply(reviewID primitive.ObjectID, userID primitive.ObjectID, request models.ReviewReplyRequest) (*models.Review, error) {
+	if err := ensureUserNotBanned(userID); err != nil {
+		return nil, err
+	}
+
+	review, err := GetReview(reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := reviewReplyRole(review, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A reviewer who blocked the manager does not get replies from them
+	if reviewerID, err := primitive.ObjectIDFromHex(review.UserID); err == nil && reviewerID != userID {
+		if err := ensureNotBlockedBy(reviewerID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(review.Replies) >= maxReviewReplies {
+		return nil, fmt.Errorf("invalid reply: a review can have at most %d replies", maxReviewReplies)
+	}
+	if request.ParentID != nil && findReviewReply(review, *request.ParentID) == nil {
+		return nil, fmt.Errorf("invalid reply: no reply found with ID: %s", request.ParentID.Hex())
+	}
+
+	// Replies go through the same content check as reviews. They are shown with the review
+	// and cannot be held on their own, so a flagged reply is refused.
+	if checkUserText(userID, request.Content) != models.ModerationApproved {
+		return nil, fmt.Errorf("invalid reply: text did not pass the content check")
+	}
+
+	reply := models.ReviewReply{
+		ID:       primitive.NewObjectID(),
+		ParentID: request.ParentID,
+		UserID:   userID.Hex(),
+		Role:     role,
+		Content:  request.Content,
+		Date:     time.Now(),
+	}
+	if user, err := GetUserByID(userID); err == nil {
+		reply.UserName = user.NickName
+		reply.UserAvatar = user.AvatarURL
+	}
+
+	filter := bson.M{"_id": reviewID}
+	if err := UpdateOne(reviewCollection, filter, bson.M{"$push": bson.M{"replies": reply}}); err != nil {
+		return nil, fmt.Errorf("failed to add reply: %v", err)
+	}
+	review.Replies = append(review.Replies, reply)
+
+	// Let the reviewer know the place answered
+	if role == models.ReplyRoleManager {
+		if reviewerID, err := primitive.ObjectIDFromHex(review.UserID); err == nil {
+			if _, err := sendReviewReplyMessage(reviewerID, *review, reply); err != nil {
+				log.Printf("Failed to notify reviewer %s of reply: %v", review.UserID, err)
+			}
+		}
+	}
+	go notifyReviewReply(*review, reply, userID)
+
+	return review, nil
+}
+
+// notifyReviewReply puts a reply in the inbox of the other side of the thread,
+// the reviewer for a manager's reply and the place manager for a reviewer's reply
+func notifyReviewReply(review models.Review, reply models.ReviewReply, authorID primitive.ObjectID) {
+	notification := models.Notification{
+		Type:       models.NotificationReviewReply,
+		ActorID:    &authorID,
+		ActorName:  reply.UserName,
+		SourceType: models.ContentTypeReview,
+		SourceID:   review.ID,
+		Title:      "The place replied to your review",
+		Body:       feedSummary(reply.Content),
+	}
+
+	if reply.Role == models.ReplyRoleManager {
+		reviewerID, err := primitive.ObjectIDFromHex(review.UserID)
+		if err != nil {
+			return
+		}
+		notification.UserID = reviewerID
+	} else {
+		placeID, err := primitive.ObjectIDFromHex(review.PlaceID)
+		if err != nil {
+			return
+		}
+		place, err := GetLocationByID(placeID)
+		if err != nil || place.ManagerID == nil {
+			return
+		}
+		notification.UserID = *place.ManagerID
+		notification.Title = "A reviewer replied on your place"
+	}
+
+	notifyUser(notification)
+}
+
+// DeleteReviewReply removes a reply, its author and the place manager may delete it.
+// Replies answering it stay in the thread.
+func DeleteReviewReply(reviewID primitive.ObjectID, replyID primitive.ObjectID, userID primitive.ObjectID) (*models.Review, error) {
+	review, err := GetReview(reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := findReviewReply(review, replyID)
+	if reply == nil {
+		return nil, fmt.Errorf("no reply found with ID: %s", replyID.Hex())
+	}
+	if reply.UserID != userID.Hex() {
+		role, err := reviewReplyRole(review, userID)
+		if err != nil || role != models.ReplyRoleManager {
+			return nil, fmt.Errorf("not authorized to delete reply: %s", replyID.Hex())
+		}
+	}
+
+	filter := bson.M{"_id": reviewID}
+	if err := UpdateOne(reviewCollection, filter, bson.M{"$pull": bson.M{"replies": bson.M{"_id": replyID}}}); err != nil {
+		return nil, fmt.Errorf("failed to delete reply: %v", err)
+	}
+
+	return GetReview(reviewID)
+}
+
+// reviewReplyRole returns the role the user replies with, the place manager takes precedence
+func reviewReplyRole(review *models.Review, userID primitive.ObjectID) (string, error) {
+	if placeID, err := primitive.ObjectIDFromHex(review.PlaceID); err == nil {
+		manager, err := IsPlaceManager(placeID, userID)
+		if err != nil {
+			return "", err
+		}
+		if manager {
+			return models.ReplyRoleManager, nil
+		}
+	}
+	if review.UserID == userID.Hex() {
+		return models.ReplyRoleReviewer, nil
+	}
+
+	return "", fmt.Errorf("not authorized to reply to review: %s", review.ID.Hex())
+}
+
+// findReviewReply returns the reply with the given ID in a review's thread
+func findReviewReply(review *models.Review, replyID primitive.ObjectID) *models.ReviewReply {
+	for i := range review.Replies {
+		if review.Replies[i].ID == replyID {
+			return &review.Replies[i]
+		}
+	}
+	return nil
+}
+
+// sendReviewReplyMessage tells a reviewer that the place manager replied to their review
+func sendReviewReplyMessage(userID primitive.ObjectID, review models.Review, reply models.ReviewReply) (bool, error) {
+	placeName := ""
+	if placeID, err := primitive.ObjectIDFromHex(review.PlaceID); err == nil {
+		if place, err := GetLocationByID(placeID); err == nil {
+			placeName = place.Name
+		}
+	}
+
+	page := fmt.Sprintf("pages/place/detail?id=%s&reviewId=%s", review.PlaceID, review.ID.Hex())
+	return sendUserSubscribeMessage(userID, models.ReminderKindReviewReply, page,
+		placeName, reply.Content, reply.Date.Format("2006-01-02 15:04"))
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"log"
 	"playtime-go/models"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -10,25 +11,27 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func DeleteAllUserReview(userID primitive.ObjectID) error {
-	// Create filter to match all reviews by the user
-	filter := bson.M{"user_id": userID}
+// DeleteAllUserReview deletes every review written by a user, only moderators may do this
+func DeleteAllUserReview(userID primitive.ObjectID, moderatorID primitive.ObjectID) error {
+	if !IsModerator(moderatorID) {
+		return fmt.Errorf("not authorized to delete all reviews of user: %s", userID.Hex())
+	}
 
-	// Use the DeleteMany function from mongo_service.go
-	deletedCount, err := DeleteMany(reviewCollection, filter)
+	deletedCount, err := deleteReviewsMatching(bson.M{"userId": userID.Hex()})
 	if err != nil {
 		return fmt.Errorf("failed to delete user reviews: %v", err)
 	}
 
-	fmt.Printf("%v rows of user reviews are delete \n", deletedCount)
-	// Log the number of deleted documents (optional)
-	// log.Printf("Deleted %d reviews for user %s", deletedCount, userID.Hex())
-
+	log.Printf("Moderator %s deleted %d reviews of user %s", moderatorID.Hex(), deletedCount, userID.Hex())
 	return nil
 }
 
-func GetAllUserReview(userID primitive.ObjectID) ([]models.Review, error) {
-	filter := bson.M{"user_id": userID}
+// GetAllUserReview gets the reviews of a user, reviews held for moderation are only listed to the user and moderators
+func GetAllUserReview(userID primitive.ObjectID, viewerID primitive.ObjectID) ([]models.Review, error) {
+	filter := bson.M{"userId": userID.Hex()}
+	if viewerID.IsZero() || (viewerID != userID && !IsModerator(viewerID)) {
+		filter["moderationStatus"] = VisibleContentFilter()
+	}
 	var reviews []models.Review
 
 	findOptions := options.Find()
@@ -45,14 +48,24 @@ func GetAllUserReview(userID primitive.ObjectID) ([]models.Review, error) {
 	return reviews, nil
 }
 
-func GetAllPlaceReview(placeID primitive.ObjectID) ([]models.Review, error) {
-	filter := bson.M{"place_id": placeID}
+// GetAllPlaceReview gets every visible review of a place in the given sort mode.
+// Reviews by users the viewer blocked or muted are left out.
+func GetAllPlaceReview(placeID primitive.ObjectID, sort string, viewerID *primitive.ObjectID) ([]models.Review, error) {
+	filter := bson.M{"placeId": placeID.Hex(), "moderationStatus": VisibleContentFilter()}
 	var reviews []models.Review
 
+	authors, err := hiddenAuthorFilter(viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if authors != nil {
+		filter["userId"] = authors
+	}
+
 	findOptions := options.Find()
-	findOptions.SetSort(bson.D{{Key: "date", Value: -1}})
+	findOptions.SetSort(ReviewSortOrder(sort))
 
-	err := FindMany(reviewCollection, filter, &reviews, findOptions)
+	err = FindMany(reviewCollection, filter, &reviews, findOptions)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, fmt.Errorf("no review found for place ID: %s", placeID.Hex())
@@ -63,17 +76,40 @@ func GetAllPlaceReview(placeID primitive.ObjectID) ([]models.Review, error) {
 	return reviews, nil
 }
 
-func DeleteAllPlaceReview(placeID primitive.ObjectID) error {
-	// Create filter to match all reviews for the place
-	filter := bson.M{"place_id": placeID}
+// DeleteAllPlaceReview deletes every review of a place, only moderators may do this
+func DeleteAllPlaceReview(placeID primitive.ObjectID, moderatorID primitive.ObjectID) error {
+	if !IsModerator(moderatorID) {
+		return fmt.Errorf("not authorized to delete all reviews of place: %s", placeID.Hex())
+	}
 
-	// Use the DeleteMany function from mongo_service.go
-	deletedCount, err := DeleteMany(reviewCollection, filter)
+	deletedCount, err := deleteReviewsMatching(bson.M{"placeId": placeID.Hex()})
 	if err != nil {
 		return fmt.Errorf("failed to delete place reviews: %v", err)
 	}
 
-	fmt.Printf("%v rows of place reviews are deleted\n", deletedCount)
-
+	log.Printf("Moderator %s deleted %d reviews of place %s", moderatorID.Hex(), deletedCount, placeID.Hex())
 	return nil
 }
+
+// deleteReviewsMatching deletes the matching reviews together with their votes, feed items and photos
+func deleteReviewsMatching(filter bson.M) (int64, error) {
+	var reviews []models.Review
+	if err := FindMany(reviewCollection, filter, &reviews); err != nil {
+		return 0, err
+	}
+
+	deletedCount, err := DeleteMany(reviewCollection, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, review := range reviews {
+		if err := deleteReviewVotes(review.ID); err != nil {
+			log.Printf("Failed to delete votes of review %s: %v", review.ID.Hex(), err)
+		}
+		removeFeedActivity(review.ID)
+		go deleteReviewPhotoObjects(review.UserID, review.Photos)
+	}
+
+	return deletedCount, nil
+}
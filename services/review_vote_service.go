@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"playtime-go/db"
+	"playtime-go/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	reviewVoteCollection = "review_votes"
+
+	// wilsonZ is the z-score for a 95% confidence interval
+	wilsonZ = 1.96
+)
+
+// VoteReview records the user's helpful or unhelpful vote on a review.
+// Voting the same way again is a no-op, voting the other way changes the existing vote.
+func VoteReview(reviewID primitive.ObjectID, userID primitive.ObjectID, helpful bool) (*models.Review, error) {
+	review, err := GetReview(reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID == userID.Hex() {
+		return nil, fmt.Errorf("invalid vote: cannot vote on your own review")
+	}
+
+	previous, err := swapReviewVote(reviewID, userID, helpful)
+	if err != nil {
+		return nil, err
+	}
+
+	// Adjust the counters by the difference between the previous and new vote
+	inc := bson.M{}
+	switch {
+	case previous == nil && helpful:
+		inc["helpfulCount"] = 1
+	case previous == nil:
+		inc["unhelpfulCount"] = 1
+	case previous.Helpful == helpful:
+		return review, nil
+	case helpful:
+		inc["helpfulCount"] = 1
+		inc["unhelpfulCount"] = -1
+	default:
+		inc["helpfulCount"] = -1
+		inc["unhelpfulCount"] = 1
+	}
+
+	return applyReviewVoteCounts(reviewID, inc)
+}
+
+// RemoveReviewVote withdraws the user's vote on a review
+func RemoveReviewVote(reviewID primitive.ObjectID, userID primitive.ObjectID) (*models.Review, error) {
+	collection := db.GetCollection(reviewVoteCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var vote models.ReviewVote
+	err := collection.FindOneAndDelete(ctx, bson.M{"reviewId": reviewID, "userId": userID}).Decode(&vote)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no vote found for review: %s", reviewID.Hex())
+		}
+		return nil, fmt.Errorf("failed to remove vote: %v", err)
+	}
+
+	inc := bson.M{"unhelpfulCount": -1}
+	if vote.Helpful {
+		inc = bson.M{"helpfulCount": -1}
+	}
+
+	return applyReviewVoteCounts(reviewID, inc)
+}
+
+// swapReviewVote upserts the user's vote and returns the vote it replaced, or nil for a first vote.
+// The unique index on reviewId and userId makes concurrent first votes collide, the loser retries as an update.
+func swapReviewVote(reviewID primitive.ObjectID, userID primitive.ObjectID, helpful bool) (*models.ReviewVote, error) {
+	collection := db.GetCollection(reviewVoteCollection)
+
+	now := time.Now()
+	filter := bson.M{"reviewId": reviewID, "userId": userID}
+	update := bson.M{
+		"$set":         bson.M{"helpful": helpful, "updatedAt": now},
+		"$setOnInsert": bson.M{"createdAt": now},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		var previous models.ReviewVote
+		err = collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&previous)
+		cancel()
+
+		if err == nil {
+			return &previous, nil
+		}
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("failed to save vote: %v", err)
+}
+
+// applyReviewVoteCounts increments the vote counters of a review and refreshes its helpfulness score.
+// Both happen in one pipeline update, so concurrent votes cannot leave a score computed from stale counts.
+func applyReviewVoteCounts(reviewID primitive.ObjectID, inc bson.M) (*models.Review, error) {
+	counts := bson.M{}
+	for field, delta := range inc {
+		counts[field] = bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$" + field, 0}}, delta}}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: counts}},
+		{{Key: "$set", Value: bson.M{"helpfulScore": wilsonLowerBoundExpr("$helpfulCount", "$unhelpfulCount")}}},
+	}
+
+	collection := db.GetCollection(reviewCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var review models.Review
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	if err := collection.FindOneAndUpdate(ctx, bson.M{"_id": reviewID}, pipeline, opts).Decode(&review); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no review found with ID: %s", reviewID.Hex())
+		}
+		return nil, fmt.Errorf("failed to update vote counts: %v", err)
+	}
+
+	return &review, nil
+}
+
+// wilsonLowerBoundExpr builds an aggregation expression for the lower bound of the Wilson score interval
+// for the share of helpful votes. It ranks a review with few votes below one with many votes at the same ratio.
+func wilsonLowerBoundExpr(helpful string, unhelpful string) bson.M {
+	z2 := wilsonZ * wilsonZ
+	return bson.M{"$let": bson.M{
+		"vars": bson.M{
+			"h": bson.M{"$ifNull": bson.A{helpful, 0}},
+			"n": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{helpful, 0}}, bson.M{"$ifNull": bson.A{unhelpful, 0}}}},
+		},
+		"in": bson.M{"$cond": bson.A{
+			bson.M{"$lte": bson.A{"$$n", 0}},
+			0.0,
+			bson.M{"$let": bson.M{
+				"vars": bson.M{"phat": bson.M{"$divide": bson.A{"$$h", "$$n"}}},
+				"in": bson.M{"$divide": bson.A{
+					bson.M{"$subtract": bson.A{
+						// centre: phat + z²/2n
+						bson.M{"$add": bson.A{"$$phat", bson.M{"$divide": bson.A{z2 / 2, "$$n"}}}},
+						// margin: z·√((phat(1−phat) + z²/4n) / n)
+						bson.M{"$multiply": bson.A{wilsonZ, bson.M{"$sqrt": bson.M{"$divide": bson.A{
+							bson.M{"$add": bson.A{
+								bson.M{"$multiply": bson.A{"$$phat", bson.M{"$subtract": bson.A{1, "$$phat"}}}},
+								bson.M{"$divide": bson.A{z2 / 4, "$$n"}},
+							}},
+							"$$n",
+						}}}}},
+					}},
+					bson.M{"$add": bson.A{1, bson.M{"$divide": bson.A{z2, "$$n"}}}},
+				}},
+			}},
+		}},
+	}}
+}
+
+// ReviewSortOrder returns the sort document for a review sort mode, newest first by default
+func ReviewSortOrder(sort string) bson.D {
+	switch sort {
+	case models.ReviewSortHighestRated:
+		return bson.D{{Key: "rating", Value: -1}, {Key: "date", Value: -1}}
+	case models.ReviewSortLowestRated:
+		return bson.D{{Key: "rating", Value: 1}, {Key: "date", Value: -1}}
+	case models.ReviewSortMostHelpful:
+		return bson.D{{Key: "helpfulScore", Value: -1}, {Key: "helpfulCount", Value: -1}, {Key: "date", Value: -1}}
+	default:
+		return bson.D{{Key: "date", Value: -1}}
+	}
+}
+
+// IsValidReviewSort reports whether sort is a supported review sort mode
+func IsValidReviewSort(sort string) bool {
+	switch sort {
+	case models.ReviewSortNewest, models.ReviewSortHighestRated, models.ReviewSortLowestRated, models.ReviewSortMostHelpful:
+		return true
+	}
+	return false
+}
+
+// deleteReviewVotes removes all votes cast on a review
+func deleteReviewVotes(reviewID primitive.ObjectID) error {
+	if _, err := DeleteMany(reviewVoteCollection, bson.M{"reviewId": reviewID}); err != nil {
+		return fmt.Errorf("failed to delete review votes: %v", err)
+	}
+	return nil
+}
+
+// EnsureReviewVoteIndexes creates the unique vote index and the indexes used to sort place reviews
+func EnsureReviewVoteIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := db.GetCollection(reviewVoteCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "reviewId", Value: 1}, {Key: "userId", Value: 1}},
+		Options: options.Index().SetName("reviewId_userId_unique").SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create review vote indexes: %v", err)
+	}
+
+	_, err = db.GetCollection(reviewCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "placeId", Value: 1}, {Key: "date", Value: -1}},
+			Options: options.Index().SetName("placeId_date"),
+		},
+		{
+			Keys:    bson.D{{Key: "placeId", Value: 1}, {Key: "helpfulScore", Value: -1}},
+			Options: options.Index().SetName("placeId_helpfulScore"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create review indexes: %v", err)
+	}
+
+	return nil
+}
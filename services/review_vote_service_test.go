@@ -0,0 +1,129 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// evalExpr evaluates the aggregation operators used by wilsonLowerBoundExpr against a document
+func evalExpr(t *testing.T, expr interface{}, vars map[string]interface{}, doc map[string]interface{}) interface{} {
+	t.Helper()
+
+	number := func(value interface{}) float64 {
+		switch v := value.(type) {
+		case int:
+			return float64(v)
+		case float64:
+			return v
+		}
+		t.Fatalf("expected a number, got %#v", value)
+		return 0
+	}
+	args := func(value interface{}) []float64 {
+		list, ok := value.(bson.A)
+		if !ok {
+			t.Fatalf("expected an argument list, got %#v", value)
+		}
+		values := make([]float64, 0, len(list))
+		for _, arg := range list {
+			values = append(values, number(evalExpr(t, arg, vars, doc)))
+		}
+		return values
+	}
+
+	switch e := expr.(type) {
+	case int, float64:
+		return e
+	case string:
+		if len(e) > 2 && e[:2] == "$$" {
+			return vars[e[2:]]
+		}
+		if len(e) > 1 && e[0] == '$' {
+			return doc[e[1:]]
+		}
+		return e
+	case bson.M:
+		if len(e) != 1 {
+			t.Fatalf("expected a single operator, got %#v", e)
+		}
+		for op, arg := range e {
+			switch op {
+			case "$let":
+				spec := arg.(bson.M)
+				scope := make(map[string]interface{}, len(vars))
+				for name, value := range vars {
+					scope[name] = value
+				}
+				for name, value := range spec["vars"].(bson.M) {
+					scope[name] = evalExpr(t, value, vars, doc)
+				}
+				return evalExpr(t, spec["in"], scope, doc)
+			case "$ifNull":
+				list := arg.(bson.A)
+				if value := evalExpr(t, list[0], vars, doc); value != nil {
+					return value
+				}
+				return evalExpr(t, list[1], vars, doc)
+			case "$cond":
+				list := arg.(bson.A)
+				if evalExpr(t, list[0], vars, doc).(bool) {
+					return evalExpr(t, list[1], vars, doc)
+				}
+				return evalExpr(t, list[2], vars, doc)
+			case "$lte":
+				values := args(arg)
+				return values[0] <= values[1]
+			case "$add", "$multiply":
+				values := args(arg)
+				result := values[0]
+				for _, value := range values[1:] {
+					if op == "$add" {
+						result += value
+					} else {
+						result *= value
+					}
+				}
+				return result
+			case "$subtract":
+				values := args(arg)
+				return values[0] - values[1]
+			case "$divide":
+				values := args(arg)
+				return values[0] / values[1]
+			case "$sqrt":
+				return math.Sqrt(number(evalExpr(t, arg, vars, doc)))
+			}
+			t.Fatalf("unsupported operator %s", op)
+		}
+	}
+	t.Fatalf("unsupported expression %#v", expr)
+	return nil
+}
+
+func TestWilsonLowerBoundExpr(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  map[string]interface{}
+		want float64
+	}{
+		{name: "no votes", doc: map[string]interface{}{"helpfulCount": 0, "unhelpfulCount": 0}, want: 0},
+		{name: "missing counters", doc: map[string]interface{}{}, want: 0},
+		{name: "only unhelpful", doc: map[string]interface{}{"helpfulCount": 0, "unhelpfulCount": 3}, want: 0},
+		{name: "one helpful vote", doc: map[string]interface{}{"helpfulCount": 1, "unhelpfulCount": 0}, want: 0.2065},
+		{name: "ten helpful votes", doc: map[string]interface{}{"helpfulCount": 10}, want: 0.7225},
+		{name: "split votes", doc: map[string]interface{}{"helpfulCount": 5, "unhelpfulCount": 5}, want: 0.2366},
+		{name: "many split votes", doc: map[string]interface{}{"helpfulCount": 50, "unhelpfulCount": 50}, want: 0.4038},
+	}
+
+	expr := wilsonLowerBoundExpr("$helpfulCount", "$unhelpfulCount")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evalExpr(t, expr, nil, tt.doc).(float64)
+			if math.Abs(got-tt.want) > 0.0005 {
+				t.Errorf("Wilson lower bound = %.4f, want %.4f", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"playtime-go/config"
+	"playtime-go/models"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sessionTokenTTL is how long a session token identifies its user.
+// Clients sign in again with a fresh login code when it runs out, bans take effect immediately either way.
+const sessionTokenTTL = 2 * time.Hour
+
+var (
+	sessionSecret     []byte
+	sessionSecretOnce sync.Once
+)
+
+// IssueSessionToken exchanges a WeChat login code for a session token of the user it belongs to.
+// The code proves who the caller is, the user is looked up by the OpenID WeChat returns for it.
+func IssueSessionToken(code string) (*models.SessionToken, error) {
+	session, err := GetLoginSession(url.QueryEscape(code))
+	if err != nil {
+		if session.ErrCode != 0 {
+			return nil, fmt.Errorf("invalid login code: %v", err)
+		}
+		return nil, err
+	}
+	if session.OpenID == "" {
+		return nil, fmt.Errorf("invalid login code: no OpenID returned")
+	}
+
+	user, err := GetUserByOpenID(session.OpenID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Banned {
+		return nil, fmt.Errorf("user is banned: %s", user.ID.Hex())
+	}
+
+	return signSessionToken(user.ID), nil
+}
+
+// signSessionToken signs a session token for the user, formatted as userID.expiry.signature
+func signSessionToken(userID primitive.ObjectID) *models.SessionToken {
+
+	expiresAt := time.Now().Add(sessionTokenTTL).Truncate(time.Second)
+	payload := userID.Hex() + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+
+	return &models.SessionToken{
+		Token:     payload + "." + signSessionPayload(payload),
+		ExpiresAt: expiresAt,
+	}
+}
+
+// VerifySessionToken checks a session token's signature and expiry and returns its user.
+// A user banned after the token was issued can no longer use it.
+func VerifySessionToken(token string) (primitive.ObjectID, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return primitive.NilObjectID, fmt.Errorf("invalid session token: malformed")
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(signSessionPayload(payload))) {
+		return primitive.NilObjectID, fmt.Errorf("invalid session token: bad signature")
+	}
+
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("invalid session token: malformed expiry")
+	}
+	if time.Now().Unix() > expiry {
+		return primitive.NilObjectID, fmt.Errorf("invalid session token: expired")
+	}
+
+	userID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("invalid session token: malformed user ID")
+	}
+	if err := ensureUserNotBanned(userID); err != nil {
+		return primitive.NilObjectID, err
+	}
+	return userID, nil
+}
+
+// signSessionPayload returns the hex HMAC-SHA256 of a token payload
+func signSessionPayload(payload string) string {
+	mac := hmac.New(sha256.New, sessionTokenSecret())
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sessionTokenSecret returns the configured signing secret, or a random one for this process
+// so tokens still work on a single instance without configuration
+func sessionTokenSecret() []byte {
+	sessionSecretOnce.Do(func() {
+		if secret := config.GetConfig().SessionSecret; secret != "" {
+			sessionSecret = []byte(secret)
+			return
+		}
+
+		log.Printf("Warning: SESSION_SECRET is not set, session tokens only work on this instance until it restarts")
+		sessionSecret = make([]byte, 32)
+		if _, err := rand.Read(sessionSecret); err != nil {
+			log.Fatalf("Failed to generate session secret: %v", err)
+		}
+	})
+	return sessionSecret
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"playtime-go/config"
+	"playtime-go/models"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	subscriptionCollection = "subscriptions"
+
+	// maxMessageThingLength is the longest value WeChat accepts in a template "thing" field
+	maxMessageThingLength = 20
+)
+
+// GetReminderTemplates returns the configured subscribe message template for each reminder kind
+func GetReminderTemplates() map[string]string {
+	cfg := config.GetConfig()
+	return map[string]string{
+		models.ReminderKindVaccination: cfg.VaccineTemplateID,
+		models.ReminderKindDeworming:   cfg.DewormingTemplateID,
+		models.ReminderKindBirthday:    cfg.BirthdayTemplateID,
+		models.ReminderKindLostPet:     cfg.LostPetTemplateID,
+		models.ReminderKindEvent:       cfg.EventTemplateID,
+		models.ReminderKindReviewReply: cfg.ReviewReplyTemplateID,
+	}
+}
+
+// defaultTemplateFields are the data keys of the stock template for each reminder kind,
+// in the order the values are sent. A template with other keys is configured with WECHAT_*_TEMPLATE_FIELDS.
+var defaultTemplateFields = map[string][]string{
+	models.ReminderKindVaccination: {"thing1", "thing2", "time3", "thing4"},
+	models.ReminderKindDeworming:   {"thing1", "thing2", "time3", "thing4"},
+	models.ReminderKindBirthday:    {"thing1", "thing2", "time3", "thing4"},
+	models.ReminderKindLostPet:     {"thing1", "thing2", "time3", "thing4"},
+	models.ReminderKindEvent:       {"thing1", "thing2", "time3", "thing4"},
+	models.ReminderKindReviewReply: {"thing1", "thing2", "time3"},
+}
+
+// templateMessageData maps values onto the data keys of the template for a reminder kind.
+// Values beyond the template's keys are dropped and "thing" values are shortened to fit.
+func templateMessageData(kind string, values ...string) map[string]models.SubscribeMessageValue {
+	fields := config.GetConfig().TemplateFields[kind]
+	if len(fields) == 0 {
+		fields = defaultTemplateFields[kind]
+	}
+
+	data := make(map[string]models.SubscribeMessageValue, len(fields))
+	for i, field := range fields {
+		if i >= len(values) {
+			break
+		}
+		value := values[i]
+		if strings.HasPrefix(field, "thing") {
+			value = truncateMessageValue(value)
+		}
+		data[field] = models.SubscribeMessageValue{Value: value}
+	}
+	return data
+}
+
+// reminderKindForTemplate finds the reminder kind a template ID is configured for
+func reminderKindForTemplate(templateID string) string {
+	for kind, id := range GetReminderTemplates() {
+		if id != "" && id == templateID {
+			return kind
+		}
+	}
+	return ""
+}
+
+// RecordSubscription stores a user's consent result for a subscribe message template
+func RecordSubscription(userID primitive.ObjectID, request models.SubscriptionRequest) (*models.Subscription, error) {
+	kind := reminderKindForTemplate(request.TemplateID)
+	if kind == "" {
+		return nil, fmt.Errorf("unknown template ID: %s", request.TemplateID)
+	}
+
+	// A rejected prompt grants no sends, but we still track that the template was offered
+	increment := 0
+	if request.Accepted {
+		increment = 1
+	}
+
+	filter := bson.M{"userId": userID, "templateId": request.TemplateID}
+	update := bson.M{
+		"$inc": bson.M{"remaining": increment},
+		"$set": bson.M{"kind": kind, "updatedAt": time.Now()},
+	}
+	if err := UpsertOne(subscriptionCollection, filter, update); err != nil {
+		return nil, fmt.Errorf("failed to record subscription: %v", err)
+	}
+
+	var subscription models.Subscription
+	if err := FindOne(subscriptionCollection, filter, &subscription); err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %v", err)
+	}
+
+	return &subscription, nil
+}
+
+// ListSubscriptions retrieves all subscribe message consents for a user
+func ListSubscriptions(userID primitive.ObjectID) ([]models.Subscription, error) {
+	filter := bson.M{"userId": userID}
+	var subscriptions []models.Subscription
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "kind", Value: 1}})
+
+	err := FindMany(subscriptionCollection, filter, &subscriptions, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %v", err)
+	}
+
+	return subscriptions, nil
+}
+
+// getActiveSubscription returns the user's subscription for a kind if it still has sends left
+func getActiveSubscription(userID primitive.ObjectID, kind string) (*models.Subscription, error) {
+	filter := bson.M{"userId": userID, "kind": kind, "remaining": bson.M{"$gt": 0}}
+	var subscription models.Subscription
+
+	err := FindOne(subscriptionCollection, filter, &subscription)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get subscription: %v", err)
+	}
+
+	return &subscription, nil
+}
+
+// consumeSubscription uses up one send of a subscription. Dry runs leave the subscription untouched.
+func consumeSubscription(id primitive.ObjectID) error {
+	if config.GetConfig().ReminderDryRun {
+		return nil
+	}
+
+	filter := bson.M{"_id": id, "remaining": bson.M{"$gt": 0}}
+	update := bson.M{
+		"$inc": bson.M{"remaining": -1},
+		"$set": bson.M{"updatedAt": time.Now()},
+	}
+	return UpdateOne(subscriptionCollection, filter, update)
+}
+
+// sendUserSubscribeMessage sends a subscribe message of the given kind to a user if they have
+// consented to one, using up the consent. Values fill the template's data keys in order.
+// It reports whether a message was sent.
+func sendUserSubscribeMessage(userID primitive.ObjectID, kind string, page string, values ...string) (bool, error) {
+	templateID := GetReminderTemplates()[kind]
+	if templateID == "" {
+		return false, nil
+	}
+
+	subscription, err := getActiveSubscription(userID, kind)
+	if err != nil || subscription == nil {
+		return false, err
+	}
+
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return false, err
+	}
+
+	message := models.SubscribeMessageRequest{
+		ToUser:     user.OpenID,
+		TemplateID: templateID,
+		Page:       page,
+		Data:       templateMessageData(kind, values...),
+	}
+
+	if _, err := SendSubscribeMessage(message); err != nil {
+		return false, err
+	}
+
+	if err := consumeSubscription(subscription.ID); err != nil {
+		log.Printf("Failed to consume subscription %s: %v", subscription.ID.Hex(), err)
+	}
+
+	return true, nil
+}
+
+// truncateMessageValue shortens free text to the 20 characters a template "thing" field allows
+func truncateMessageValue(value string) string {
+	runes := []rune(value)
+	if len(runes) <= maxMessageThingLength {
+		return value
+	}
+	return string(runes[:maxMessageThingLength-1]) + "…"
+}
+
+// SendSubscribeMessage sends a subscribe message through the WeChat API,
+// or through the local fake endpoint when reminders run in dry-run mode
+func SendSubscribeMessage(message models.SubscribeMessageRequest) (models.SubscribeMessageResponse, error) {
+	cfg := config.GetConfig()
+
+	var url string
+	if cfg.ReminderDryRun {
+		url = fmt.Sprintf("%s/cgi-bin/message/subscribe/send?access_token=dry-run", cfg.WechatFakeURL)
+	} else {
+		token, err := GetToken()
+		if err != nil {
+			return models.SubscribeMessageResponse{}, fmt.Errorf("failed to get access token: %v", err)
+		}
+		url = fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/message/subscribe/send?access_token=%s", token.AccessToken)
+	}
+
+	jsonBody, err := json.Marshal(message)
+	if err != nil {
+		return models.SubscribeMessageResponse{}, fmt.Errorf("failed to marshal request body: %v", err)
+	}
+
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return models.SubscribeMessageResponse{}, fmt.Errorf("failed to send subscribe message: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return models.SubscribeMessageResponse{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result models.SubscribeMessageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return models.SubscribeMessageResponse{}, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	if result.ErrCode != 0 {
+		return result, fmt.Errorf("WeChat API error: %d - %s", result.ErrCode, result.ErrMsg)
+	}
+
+	log.Printf("Sent subscribe message %s to %s", message.TemplateID, message.ToUser)
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"fmt"
+	"playtime-go/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const uploadCollection = "uploads"
+
+// RecordUpload stores a record of a file uploaded by a user
+func RecordUpload(userID primitive.ObjectID, upload *UploadResponse, contentType string, size int64) (*models.Upload, error) {
+	record := models.Upload{
+		UserID:      userID,
+		URL:         upload.URL,
+		Filename:    upload.Filename,
+		ContentType: contentType,
+		Size:        size,
+		CreatedAt:   time.Now(),
+	}
+
+	id, err := InsertOne(uploadCollection, record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record upload: %v", err)
+	}
+
+	record.ID = id
+	return &record, nil
+}
+
+// ListUploadsByUser retrieves all uploads recorded for a user
+func ListUploadsByUser(userID primitive.ObjectID) ([]models.Upload, error) {
+	filter := bson.M{"userId": userID}
+	var uploads []models.Upload
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	err := FindMany(uploadCollection, filter, &uploads, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list uploads: %v", err)
+	}
+
+	return uploads, nil
+}
+
+// ownedUploadKeys returns which of the given object keys the user has upload records for
+func ownedUploadKeys(userID primitive.ObjectID, keys []string) (map[string]bool, error) {
+	owned := make(map[string]bool, len(keys))
+	if len(keys) == 0 {
+		return owned, nil
+	}
+
+	var uploads []models.Upload
+	filter := bson.M{"userId": userID, "filename": bson.M{"$in": keys}}
+	if err := FindMany(uploadCollection, filter, &uploads); err != nil {
+		return nil, fmt.Errorf("failed to look up uploads: %v", err)
+	}
+
+	for _, upload := range uploads {
+		owned[upload.Filename] = true
+	}
+	return owned, nil
+}
+
+// deleteUploadRecords removes the upload records of stored objects that have been deleted
+func deleteUploadRecords(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if _, err := DeleteMany(uploadCollection, bson.M{"filename": bson.M{"$in": keys}}); err != nil {
+		return fmt.Errorf("failed to delete upload records: %v", err)
+	}
+	return nil
+}
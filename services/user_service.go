@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"playtime-go/config"
 	"playtime-go/models"
 	"time"
 
@@ -111,3 +112,25 @@ func ListUsers() ([]models.User, error) {
 
 	return users, nil
 }
+
+// ensureUserNotBanned returns an error when a moderator has banned the user from posting
+func ensureUserNotBanned(userID primitive.ObjectID) error {
+	count, err := Count(userCollection, bson.M{"_id": userID, "banned": true})
+	if err != nil {
+		return fmt.Errorf("failed to check user ban: %v", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("user is banned: %s", userID.Hex())
+	}
+	return nil
+}
+
+// IsModerator reports whether the user is one of the configured moderators
+func IsModerator(userID primitive.ObjectID) bool {
+	for _, id := range config.GetConfig().ModeratorIDs {
+		if id == userID.Hex() {
+			return true
+		}
+	}
+	return false
+}
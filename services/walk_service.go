@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"playtime-go/db"
+	"playtime-go/models"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	walkCollection = "walks"
+
+	maxWalkPoints   = 20000
+	maxWalkPets     = 10
+	maxWalkTitle    = 100
+	maxWalkDistance = 100000 // meters, also bounds the number of route samples places are searched around
+
+	// walkPlaceRadius is how close the route must pass to a place for it to count as visited
+	walkPlaceRadius = 50 // meters
+	// walkPlaceSampleSpacing is the largest gap between the route points places are searched around
+	walkPlaceSampleSpacing = walkPlaceRadius // meters
+	// walkPlaceSearchRadius is the radius in radians searched around each sample. Every point of the route is
+	// within the sample spacing of a sample, so these circles cover the whole corridor of the route.
+	walkPlaceSearchRadius = float64(walkPlaceRadius+walkPlaceSampleSpacing) / earthRadiusMeters
+	// walkPlaceQueryBatch is the number of route points searched around per query
+	walkPlaceQueryBatch = 100
+)
+
+// CreateWalk logs a walk from timestamped points for pets the user can care for.
+// Distance, duration, pace and the places passed are computed from the points.
+func CreateWalk(userID primitive.ObjectID, request models.WalkRequest) (*models.Walk, error) {
+	if len(request.PetIDs) == 0 {
+		return nil, fmt.Errorf("invalid walk: at least one pet is required")
+	}
+	if len(request.PetIDs) > maxWalkPets {
+		return nil, fmt.Errorf("invalid walk: at most %d pets can join a walk", maxWalkPets)
+	}
+	title := strings.TrimSpace(request.Title)
+	if len([]rune(title)) > maxWalkTitle {
+		return nil, fmt.Errorf("invalid walk: title can be at most %d characters", maxWalkTitle)
+	}
+
+	petIDs := make([]primitive.ObjectID, 0, len(request.PetIDs))
+	seen := map[primitive.ObjectID]bool{}
+	for _, petID := range request.PetIDs {
+		if seen[petID] {
+			continue
+		}
+		seen[petID] = true
+		if _, err := AuthorizePet(petID, userID, PetAccessCare); err != nil {
+			return nil, err
+		}
+		petIDs = append(petIDs, petID)
+	}
+
+	points, err := cleanWalkPoints(request.Points)
+	if err != nil {
+		return nil, err
+	}
+
+	walk := models.Walk{
+		UserID:     userID,
+		PetIDs:     petIDs,
+		Title:      title,
+		Route:      models.GeoLineString{Type: "LineString", Coordinates: make([][]float64, 0, len(points))},
+		Timestamps: make([]time.Time, 0, len(points)),
+		StartedAt:  points[0].Time,
+		EndedAt:    request.Points[len(request.Points)-1].Time, // Repeated positions at the end still count
+		CreatedAt:  time.Now(),
+	}
+	for i, point := range points {
+		walk.Route.Coordinates = append(walk.Route.Coordinates, []float64{point.Longitude, point.Latitude})
+		walk.Timestamps = append(walk.Timestamps, point.Time)
+		if i > 0 {
+			walk.DistanceMeters += distanceMeters(points[i-1].Longitude, points[i-1].Latitude, point.Longitude, point.Latitude)
+		}
+	}
+	walk.DistanceMeters = math.Round(walk.DistanceMeters*10) / 10
+	if walk.DistanceMeters > maxWalkDistance {
+		return nil, fmt.Errorf("invalid walk: a route can be at most %d km long", maxWalkDistance/1000)
+	}
+	walk.DurationSeconds = int64(walk.EndedAt.Sub(walk.StartedAt).Seconds())
+	if walk.DistanceMeters > 0 {
+		walk.PaceSecondsPerKm = math.Round(float64(walk.DurationSeconds) / (walk.DistanceMeters / 1000))
+	}
+
+	places, err := findPlacesAlongRoute(walk.Route.Coordinates)
+	if err != nil {
+		return nil, err
+	}
+	walk.Places = places
+
+	id, err := InsertOne(walkCollection, walk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create walk: %v", err)
+	}
+
+	walk.ID = id
+	return &walk, nil
+}
+
+// GetWalk retrieves a walk the user logged or can view through one of its pets
+func GetWalk(id primitive.ObjectID, userID primitive.ObjectID) (*models.Walk, error) {
+	var walk models.Walk
+	if err := FindOne(walkCollection, bson.M{"_id": id}, &walk); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("no walk found with ID: %s", id.Hex())
+		}
+		return nil, fmt.Errorf("failed to get walk by ID: %v", err)
+	}
+
+	if walk.UserID == userID {
+		return &walk, nil
+	}
+	for _, petID := range walk.PetIDs {
+		if _, err := AuthorizePet(petID, userID, PetAccessView); err == nil {
+			return &walk, nil
+		}
+	}
+	return nil, fmt.Errorf("not authorized to view walk: %s", id.Hex())
+}
+
+// ListWalks lists walks newest first, the user's own walks or all walks of a pet they can view.
+// Routes are left out, GetWalk returns them.
+func ListWalks(userID primitive.ObjectID, petID *primitive.ObjectID, before time.Time, limit int64) ([]models.Walk, error) {
+	filter := bson.M{"userId": userID}
+	if petID != nil {
+		if _, err := AuthorizePet(*petID, userID, PetAccessView); err != nil {
+			return nil, err
+		}
+		filter = bson.M{"petIds": *petID}
+	}
+	if !before.IsZero() {
+		filter["startedAt"] = bson.M{"$lt": before}
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "startedAt", Value: -1}})
+	findOptions.SetProjection(bson.M{"route": 0, "timestamps": 0})
+	if limit > 0 {
+		findOptions.SetLimit(limit)
+	} else {
+		findOptions.SetLimit(20) // Default limit
+	}
+
+	walks := []models.Walk{}
+	if err := FindMany(walkCollection, filter, &walks, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list walks: %v", err)
+	}
+
+	return walks, nil
+}
+
+// DeleteWalk deletes a walk, only the user who logged it may delete it
+func DeleteWalk(id primitive.ObjectID, userID primitive.ObjectID) error {
+	walk, err := GetWalk(id, userID)
+	if err != nil {
+		return err
+	}
+	if walk.UserID != userID {
+		return fmt.Errorf("not authorized to delete walk: %s", id.Hex())
+	}
+
+	if err := DeleteOne(walkCollection, bson.M{"_id": id}); err != nil {
+		return fmt.Errorf("failed to delete walk: %v", err)
+	}
+	return nil
+}
+
+// WalkPoints returns the recorded points of a walk in order, for export
+func WalkPoints(walk *models.Walk) []models.WalkPoint {
+	points := make([]models.WalkPoint, 0, len(walk.Route.Coordinates))
+	for i, coordinate := range walk.Route.Coordinates {
+		if len(coordinate) != 2 || i >= len(walk.Timestamps) {
+			break
+		}
+		points = append(points, models.WalkPoint{Longitude: coordinate[0], Latitude: coordinate[1], Time: walk.Timestamps[i]})
+	}
+	return points
+}
+
+// cleanWalkPoints validates recorded points and drops repeated positions, which GeoJSON line strings cannot hold
+func cleanWalkPoints(points []models.WalkPoint) ([]models.WalkPoint, error) {
+	if len(points) > maxWalkPoints {
+		return nil, fmt.Errorf("invalid walk: a route can have at most %d points", maxWalkPoints)
+	}
+
+	cleaned := make([]models.WalkPoint, 0, len(points))
+	for i, point := range points {
+		if point.Latitude < -90 || point.Latitude > 90 || point.Longitude < -180 || point.Longitude > 180 {
+			return nil, fmt.Errorf("invalid walk: point %d has invalid coordinates", i+1)
+		}
+		if point.Time.IsZero() {
+			return nil, fmt.Errorf("invalid walk: point %d has no time", i+1)
+		}
+		if len(cleaned) > 0 {
+			last := cleaned[len(cleaned)-1]
+			if point.Time.Before(last.Time) {
+				return nil, fmt.Errorf("invalid walk: points must be in time order")
+			}
+			if point.Latitude == last.Latitude && point.Longitude == last.Longitude {
+				continue
+			}
+		}
+		cleaned = append(cleaned, point)
+	}
+
+	if len(cleaned) < 2 {
+		return nil, fmt.Errorf("invalid walk: a route needs at least two distinct points")
+	}
+	if points[len(points)-1].Time.After(time.Now().Add(time.Hour)) {
+		return nil, fmt.Errorf("invalid walk: points cannot be in the future")
+	}
+
+	return cleaned, nil
+}
+
+// findPlacesAlongRoute returns the visible places within walkPlaceRadius of the route, closest first.
+// Candidates come from circles around points sampled along the route, then each is checked against every segment.
+func findPlacesAlongRoute(route [][]float64) ([]models.WalkPlace, error) {
+	samples := sampleRoute(route, walkPlaceSampleSpacing)
+
+	findOptions := options.Find()
+	findOptions.SetProjection(bson.M{"name": 1, "category": 1, "location": 1})
+
+	places := []models.WalkPlace{}
+	seen := map[primitive.ObjectID]bool{}
+	for start := 0; start < len(samples); start += walkPlaceQueryBatch {
+		end := start + walkPlaceQueryBatch
+		if end > len(samples) {
+			end = len(samples)
+		}
+
+		circles := make([]bson.M, 0, end-start)
+		for _, sample := range samples[start:end] {
+			circles = append(circles, bson.M{"location": bson.M{"$geoWithin": bson.M{"$centerSphere": []interface{}{sample, walkPlaceSearchRadius}}}})
+		}
+		filter := bson.M{"$or": circles, "moderationStatus": VisibleContentFilter()}
+
+		var candidates []models.Location
+		if err := FindMany(locationCollection, filter, &candidates, findOptions); err != nil {
+			return nil, fmt.Errorf("failed to find places along route: %v", err)
+		}
+
+		for _, candidate := range candidates {
+			if seen[candidate.ID] || len(candidate.Location.Coordinates) != 2 {
+				continue
+			}
+			seen[candidate.ID] = true
+			distance := distanceToRoute(candidate.Location.Coordinates[0], candidate.Location.Coordinates[1], route)
+			if distance > walkPlaceRadius {
+				continue
+			}
+			places = append(places, models.WalkPlace{
+				PlaceID:        candidate.ID,
+				Name:           candidate.Name,
+				Category:       candidate.Category,
+				DistanceMeters: math.Round(distance),
+			})
+		}
+	}
+
+	sort.Slice(places, func(i, j int) bool { return places[i].DistanceMeters < places[j].DistanceMeters })
+	return places, nil
+}
+
+// sampleRoute picks route points no more than spacing meters apart. Vertices close to the last sample are
+// skipped, and long segments get points in between, so every point of the route is within spacing of a sample.
+func sampleRoute(route [][]float64, spacing float64) [][]float64 {
+	samples := [][]float64{route[0]}
+	last := route[0]
+	for i := 1; i < len(route); i++ {
+		if distanceMeters(last[0], last[1], route[i][0], route[i][1]) <= spacing {
+			continue
+		}
+
+		// Leave the last sample's disk at the previous vertex, then step along the segment
+		previous := route[i-1]
+		if previous[0] != last[0] || previous[1] != last[1] {
+			samples = append(samples, previous)
+		}
+		steps := int(math.Ceil(distanceMeters(previous[0], previous[1], route[i][0], route[i][1]) / spacing))
+		for step := 1; step <= steps; step++ {
+			fraction := float64(step) / float64(steps)
+			samples = append(samples, []float64{
+				previous[0] + (route[i][0]-previous[0])*fraction,
+				previous[1] + (route[i][1]-previous[1])*fraction,
+			})
+		}
+		last = route[i]
+	}
+	return samples
+}
+
+// distanceToRoute returns the shortest distance in meters from a point to a route. Segments are short,
+// so each is projected onto a local flat plane around the point.
+func distanceToRoute(lng float64, lat float64, route [][]float64) float64 {
+	metersPerLat := earthRadiusMeters * math.Pi / 180
+	metersPerLng := metersPerLat * math.Cos(lat*math.Pi/180)
+	project := func(coordinate []float64) (float64, float64) {
+		return (coordinate[0] - lng) * metersPerLng, (coordinate[1] - lat) * metersPerLat
+	}
+
+	shortest := math.Inf(1)
+	for i := 1; i < len(route); i++ {
+		ax, ay := project(route[i-1])
+		bx, by := project(route[i])
+		dx, dy := bx-ax, by-ay
+
+		// Closest point of the segment to the origin, which is the place
+		t := 0.0
+		if length := dx*dx + dy*dy; length > 0 {
+			t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/length))
+		}
+		shortest = math.Min(shortest, math.Hypot(ax+t*dx, ay+t*dy))
+	}
+	return shortest
+}
+
+// EnsureWalkIndexes creates the indexes for listing a user's and a pet's walks
+func EnsureWalkIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := db.GetCollection(walkCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "startedAt", Value: -1}},
+			Options: options.Index().SetName("userId_startedAt"),
+		},
+		{
+			Keys:    bson.D{{Key: "petIds", Value: 1}, {Key: "startedAt", Value: -1}},
+			Options: options.Index().SetName("petIds_startedAt"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create walk indexes: %v", err)
+	}
+
+	return nil
+}
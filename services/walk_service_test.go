@@ -0,0 +1,131 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+// offsetMeters returns the coordinate east and north meters away from a longitude and latitude
+func offsetMeters(lng float64, lat float64, east float64, north float64) []float64 {
+	metersPerLat := earthRadiusMeters * math.Pi / 180
+	metersPerLng := metersPerLat * math.Cos(lat*math.Pi/180)
+	return []float64{lng + east/metersPerLng, lat + north/metersPerLat}
+}
+
+func TestWalkPlaceSearchRadius(t *testing.T) {
+	if walkPlaceSearchRadius <= 0 {
+		t.Fatalf("walkPlaceSearchRadius = %v, want a positive radius", walkPlaceSearchRadius)
+	}
+	got := walkPlaceSearchRadius * earthRadiusMeters
+	want := float64(walkPlaceRadius + walkPlaceSampleSpacing)
+	if math.Abs(got-want) > 1e-6 {
+		t.Errorf("walkPlaceSearchRadius covers %v meters, want %v", got, want)
+	}
+}
+
+func TestSampleRoute(t *testing.T) {
+	lng, lat := 121.47, 31.23
+	start := []float64{lng, lat}
+
+	tests := []struct {
+		name        string
+		route       [][]float64
+		spacing     float64
+		wantSamples int
+	}{
+		{
+			name:        "segment shorter than the spacing",
+			route:       [][]float64{start, offsetMeters(lng, lat, 0, 30)},
+			spacing:     50,
+			wantSamples: 1,
+		},
+		{
+			name:        "long straight segment",
+			route:       [][]float64{start, offsetMeters(lng, lat, 0, 990)},
+			spacing:     50,
+			wantSamples: 21,
+		},
+		{
+			name: "dense vertices are skipped",
+			route: [][]float64{
+				start,
+				offsetMeters(lng, lat, 0, 10),
+				offsetMeters(lng, lat, 0, 20),
+				offsetMeters(lng, lat, 0, 30),
+				offsetMeters(lng, lat, 0, 40),
+			},
+			spacing:     50,
+			wantSamples: 1,
+		},
+		{
+			name: "corner",
+			route: [][]float64{
+				start,
+				offsetMeters(lng, lat, 0, 190),
+				offsetMeters(lng, lat, 190, 190),
+			},
+			spacing: 50,
+			// 4 steps along each leg after the start
+			wantSamples: 9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := sampleRoute(tt.route, tt.spacing)
+			if len(samples) != tt.wantSamples {
+				t.Errorf("sampleRoute returned %d samples, want %d", len(samples), tt.wantSamples)
+			}
+			if samples[0][0] != tt.route[0][0] || samples[0][1] != tt.route[0][1] {
+				t.Errorf("first sample = %v, want the start of the route %v", samples[0], tt.route[0])
+			}
+
+			// Every point along the route must be within the spacing of a sample
+			for i := 1; i < len(tt.route); i++ {
+				a, b := tt.route[i-1], tt.route[i]
+				for step := 0; step <= 100; step++ {
+					fraction := float64(step) / 100
+					point := []float64{a[0] + (b[0]-a[0])*fraction, a[1] + (b[1]-a[1])*fraction}
+					nearest := math.Inf(1)
+					for _, sample := range samples {
+						nearest = math.Min(nearest, distanceMeters(point[0], point[1], sample[0], sample[1]))
+					}
+					if nearest > tt.spacing*1.001 {
+						t.Fatalf("point %v is %.1f meters from the nearest sample, want at most %v", point, nearest, tt.spacing)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDistanceToRoute(t *testing.T) {
+	lng, lat := 121.47, 31.23
+	route := [][]float64{
+		{lng, lat},
+		offsetMeters(lng, lat, 0, 400),
+		offsetMeters(lng, lat, 400, 400),
+	}
+
+	tests := []struct {
+		name  string
+		point []float64
+		want  float64
+	}{
+		{name: "on a vertex", point: []float64{lng, lat}, want: 0},
+		{name: "on a segment", point: offsetMeters(lng, lat, 0, 200), want: 0},
+		{name: "beside the first segment", point: offsetMeters(lng, lat, 30, 100), want: 30},
+		{name: "below the second segment", point: offsetMeters(lng, lat, 200, 360), want: 40},
+		{name: "before the start", point: offsetMeters(lng, lat, 0, -50), want: 50},
+		{name: "past the end", point: offsetMeters(lng, lat, 430, 440), want: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := distanceToRoute(tt.point[0], tt.point[1], route)
+			if math.Abs(got-tt.want) > 0.5 {
+				t.Errorf("distanceToRoute(%v) = %.2f, want %.2f", tt.point, got, tt.want)
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/tencentyun/cos-go-sdk-v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type LoginSession struct {
@@ -58,30 +59,14 @@ type UploadResponse struct {
 	Filename string `json:"filename"`
 }
 
-// UploadFileToCOS uploads a file to Tencent Cloud COS and returns the public URL
-func UploadFileToCOS(fileReader io.Reader, originalFilename string, contentType string) (*UploadResponse, error) {
-	// Get configuration from config
-	cfg := config.GetConfig()
-	
-	if cfg.COSSecretID == "" || cfg.COSSecretKey == "" || cfg.COSBucketURL == "" {
-		return nil, fmt.Errorf("missing COS configuration")
-	}
-
-	// Parse bucket URL
-	u, err := url.Parse(cfg.COSBucketURL)
+// UploadFileToCOS uploads a file to Tencent Cloud COS under the given key prefix and returns the public URL
+func UploadFileToCOS(fileReader io.Reader, originalFilename string, contentType string, prefix string) (*UploadResponse, error) {
+	// Initialize COS client
+	cosClient, u, err := newCOSClient()
 	if err != nil {
-		return nil, fmt.Errorf("invalid COS bucket URL: %v", err)
+		return nil, err
 	}
 
-	// Initialize COS client
-	b := &cos.BaseURL{BucketURL: u}
-	cosClient := cos.NewClient(b, &http.Client{
-		Transport: &cos.AuthorizationTransport{
-			SecretID:  cfg.COSSecretID,
-			SecretKey: cfg.COSSecretKey,
-		},
-	})
-
 	// Generate unique filename
 	fileExt := filepath.Ext(originalFilename)
 	if fileExt == "" {
@@ -95,7 +80,7 @@ func UploadFileToCOS(fileReader io.Reader, originalFilename string, contentType
 		}
 	}
 	
-	fileName := fmt.Sprintf("avatar/%d%s", time.Now().UnixNano(), fileExt)
+	fileName := fmt.Sprintf("%s/%d%s", strings.Trim(prefix, "/"), time.Now().UnixNano(), fileExt)
 
 	// Set upload options
 	opt := &cos.ObjectPutOptions{
@@ -118,3 +103,120 @@ func UploadFileToCOS(fileReader io.Reader, originalFilename string, contentType
 		Filename: fileName,
 	}, nil
 }
+
+// PetObjectPrefix returns the COS key prefix for files that belong to a pet, such as "pet/{id}/photos"
+func PetObjectPrefix(petID primitive.ObjectID, kind string) string {
+	return fmt.Sprintf("pet/%s/%s", petID.Hex(), kind)
+}
+
+// newCOSClient creates a COS client for the configured bucket
+func newCOSClient() (*cos.Client, *url.URL, error) {
+	cfg := config.GetConfig()
+
+	if cfg.COSSecretID == "" || cfg.COSSecretKey == "" || cfg.COSBucketURL == "" {
+		return nil, nil, fmt.Errorf("missing COS configuration")
+	}
+
+	// Parse bucket URL
+	u, err := url.Parse(cfg.COSBucketURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid COS bucket URL: %v", err)
+	}
+
+	b := &cos.BaseURL{BucketURL: u}
+	cosClient := cos.NewClient(b, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  cfg.COSSecretID,
+			SecretKey: cfg.COSSecretKey,
+		},
+	})
+
+	return cosClient, u, nil
+}
+
+// PutObjectToCOS uploads a file to COS under the given key without returning its URL.
+// The object gets the bucket's default ACL, use PutPrivateObjectToCOS for files nobody may read publicly.
+func PutObjectToCOS(key string, fileReader io.Reader, contentType string) error {
+	return putObjectToCOS(key, fileReader, contentType, "")
+}
+
+// PutPrivateObjectToCOS uploads a file to COS that can only be read through presigned URLs
+func PutPrivateObjectToCOS(key string, fileReader io.Reader, contentType string) error {
+	return putObjectToCOS(key, fileReader, contentType, "private")
+}
+
+// putObjectToCOS uploads a file to COS with the given ACL, an empty ACL keeps the bucket default
+func putObjectToCOS(key string, fileReader io.Reader, contentType string, acl string) error {
+	cosClient, _, err := newCOSClient()
+	if err != nil {
+		return err
+	}
+
+	opt := &cos.ObjectPutOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+			ContentType: contentType,
+		},
+	}
+	if acl != "" {
+		opt.ACLHeaderOptions = &cos.ACLHeaderOptions{XCosACL: acl}
+	}
+
+	_, err = cosClient.Object.Put(context.Background(), key, fileReader, opt)
+	if err != nil {
+		return fmt.Errorf("failed to upload file to COS: %v", err)
+	}
+
+	return nil
+}
+
+// SetCOSObjectACL changes who may read a COS object, "private" stops its public URL from working and "default" follows the bucket
+func SetCOSObjectACL(key string, acl string) error {
+	cosClient, _, err := newCOSClient()
+	if err != nil {
+		return err
+	}
+
+	opt := &cos.ObjectPutACLOptions{
+		Header: &cos.ACLHeaderOptions{
+			XCosACL: acl,
+		},
+	}
+
+	_, err = cosClient.Object.PutACL(context.Background(), key, opt)
+	if err != nil {
+		return fmt.Errorf("failed to set COS object ACL: %v", err)
+	}
+
+	return nil
+}
+
+// GetPresignedCOSURL returns a time-limited download URL for a COS object
+func GetPresignedCOSURL(key string, expire time.Duration) (string, error) {
+	cosClient, _, err := newCOSClient()
+	if err != nil {
+		return "", err
+	}
+
+	cfg := config.GetConfig()
+	presignedURL, err := cosClient.Object.GetPresignedURL(context.Background(), http.MethodGet, key, cfg.COSSecretID, cfg.COSSecretKey, expire, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign COS URL: %v", err)
+	}
+
+	return presignedURL.String(), nil
+}
+
+// DeleteObjectFromCOS removes an object from COS by its key
+func DeleteObjectFromCOS(key string) error {
+	cosClient, _, err := newCOSClient()
+	if err != nil {
+		return err
+	}
+
+	_, err = cosClient.Object.Delete(context.Background(), key)
+	if err != nil {
+		return fmt.Errorf("failed to delete file from COS: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"playtime-go/db"
+	"playtime-go/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	weightCollection    = "weights"
+	weightAverageWindow = 7 * 24 * time.Hour // trailing window of the moving average
+	weightClockSkew     = 5 * time.Minute    // how far ahead of the server clock a measurement may be dated
+)
+
+// sizeWeightReference is used when a pet's breed is not in the breed catalog
+var sizeWeightReference = map[string]models.BreedWeightRange{
+	models.SizeSmall:  {MinKg: 1, MaxKg: 10, MaxMonthlyChangePct: 10},
+	models.SizeMedium: {MinKg: 10, MaxKg: 25, MaxMonthlyChangePct: 8},
+	models.SizeLarge:  {MinKg: 25, MaxKg: 60, MaxMonthlyChangePct: 6},
+}
+
+// LookupBreedWeightRange returns the typical weight range for a breed from the catalog, falling back to the size class
+func LookupBreedWeightRange(breedName string, size string) *models.BreedWeightRange {
+	breed, err := FindBreedByName(breedName)
+	if err != nil {
+		log.Printf("Failed to look up breed %q: %v", breedName, err)
+	}
+	if breed != nil && breed.WeightRange != nil {
+		return breed.WeightRange
+	}
+	if weightRange, ok := sizeWeightReference[NormalizeSize(size)]; ok {
+		return &weightRange
+	}
+	return nil
+}
+
+// AddWeightRecord records a weight measurement for a pet the user can care for
+func AddWeightRecord(petID primitive.ObjectID, userID primitive.ObjectID, request models.WeightRequest) (*models.WeightRecord, error) {
+	if _, err := AuthorizePet(petID, userID, PetAccessCare); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	measuredAt := request.MeasuredAt
+	if measuredAt.IsZero() {
+		measuredAt = now
+	}
+	if measuredAt.After(now.Add(weightClockSkew)) {
+		return nil, fmt.Errorf("invalid measuredAt: a weight cannot be measured in the future")
+	}
+
+	record := models.WeightRecord{
+		PetID:      petID,
+		WeightKg:   request.WeightKg,
+		MeasuredAt: measuredAt,
+		Notes:      request.Notes,
+		CreatedBy:  userID,
+		CreatedAt:  now,
+	}
+
+	id, err := InsertOne(weightCollection, record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create weight record: %v", err)
+	}
+
+	record.ID = id
+	return &record, nil
+}
+
+// DeleteWeightRecord deletes a weight measurement of a pet the user can care for
+func DeleteWeightRecord(petID primitive.ObjectID, id primitive.ObjectID, userID primitive.ObjectID) error {
+	if _, err := AuthorizePet(petID, userID, PetAccessCare); err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": id, "petId": petID}
+	var record models.WeightRecord
+	if err := FindOne(weightCollection, filter, &record); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return fmt.Errorf("no weight record found with ID: %s", id.Hex())
+		}
+		return fmt.Errorf("failed to get weight record by ID: %v", err)
+	}
+
+	if err := DeleteOne(weightCollection, filter); err != nil {
+		return fmt.Errorf("failed to delete weight record: %v", err)
+	}
+
+	return nil
+}
+
+// GetWeightSummary returns a pet's weight series with moving average and trend statistics
+func GetWeightSummary(petID primitive.ObjectID, userID primitive.ObjectID) (*models.WeightSummary, error) {
+	pet, err := AuthorizePet(petID, userID, PetAccessView)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []models.WeightRecord
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "measuredAt", Value: 1}})
+
+	if err := FindMany(weightCollection, bson.M{"petId": petID}, &records, findOptions); err != nil {
+		return nil, fmt.Errorf("failed to list weight records: %v", err)
+	}
+
+	summary := &models.WeightSummary{
+		PetID:        petID,
+		Series:       buildWeightSeries(records),
+		TypicalRange: LookupBreedWeightRange(pet.Breed, pet.Size),
+	}
+	if len(records) == 0 {
+		return summary, nil
+	}
+
+	latest := records[len(records)-1]
+	summary.LatestKg = latest.WeightKg
+	summary.Change30dPct = weightChangeSince(records, latest.MeasuredAt.AddDate(0, 0, -30))
+	summary.Change90dPct = weightChangeSince(records, latest.MeasuredAt.AddDate(0, 0, -90))
+
+	// Growing pets change weight quickly, so only pets known to be adults are compared with the breed range
+	if months, ok := pet.AgeInMonths(time.Now()); summary.TypicalRange != nil && ok && months >= 12 {
+		maxChange := summary.TypicalRange.MaxMonthlyChangePct
+		switch {
+		case summary.Change30dPct != nil && math.Abs(*summary.Change30dPct) > maxChange:
+			summary.Abnormal = true
+			summary.AbnormalReason = fmt.Sprintf("weight changed %.1f%% in 30 days, typical maximum is %.1f%%", *summary.Change30dPct, maxChange)
+		case summary.Change90dPct != nil && math.Abs(*summary.Change90dPct) > maxChange*3:
+			summary.Abnormal = true
+			summary.AbnormalReason = fmt.Sprintf("weight changed %.1f%% in 90 days, typical maximum is %.1f%%", *summary.Change90dPct, maxChange*3)
+		}
+	}
+
+	return summary, nil
+}
+
+// buildWeightSeries computes a trailing time-window moving average for each measurement
+func buildWeightSeries(records []models.WeightRecord) []models.WeightPoint {
+	series := make([]models.WeightPoint, 0, len(records))
+	start := 0
+	sum := 0.0
+
+	for i, record := range records {
+		sum += record.WeightKg
+		for records[start].MeasuredAt.Before(record.MeasuredAt.Add(-weightAverageWindow)) {
+			sum -= records[start].WeightKg
+			start++
+		}
+
+		series = append(series, models.WeightPoint{
+			ID:            record.ID,
+			MeasuredAt:    record.MeasuredAt,
+			WeightKg:      record.WeightKg,
+			MovingAverage: math.Round(sum/float64(i-start+1)*100) / 100,
+		})
+	}
+
+	return series
+}
+
+// weightChangeSince returns the percent change from the last measurement at or before since
+// to the latest measurement, or nil if there is no measurement that old
+func weightChangeSince(records []models.WeightRecord, since time.Time) *float64 {
+	var baseline *models.WeightRecord
+	for i := range records {
+		if records[i].MeasuredAt.After(since) {
+			break
+		}
+		baseline = &records[i]
+	}
+
+	if baseline == nil || baseline.WeightKg == 0 {
+		return nil
+	}
+
+	latest := records[len(records)-1].WeightKg
+	change := math.Round((latest-baseline.WeightKg)/baseline.WeightKg*1000) / 10
+	return &change
+}
+
+// EnsureWeightIndexes creates the index used to read a pet's weight series
+func EnsureWeightIndexes() error {
+	collection := db.GetCollection(weightCollection)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "petId", Value: 1}, {Key: "measuredAt", Value: 1}},
+		Options: options.Index().SetName("petId_measuredAt"),
+	}
+
+	_, err := collection.Indexes().CreateOne(ctx, indexModel)
+	if err != nil {
+		return fmt.Errorf("failed to create weight index: %v", err)
+	}
+
+	return nil
+}
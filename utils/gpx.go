@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"playtime-go/models"
+	"strings"
+	"time"
+)
+
+// gpxDocument is the subset of GPX 1.1 used for walks. Element names are matched without
+// their namespace so GPX 1.0 files import as well.
+type gpxDocument struct {
+	XMLName  xml.Name     `xml:"gpx"`
+	Version  string       `xml:"version,attr"`
+	Creator  string       `xml:"creator,attr"`
+	Xmlns    string       `xml:"xmlns,attr,omitempty"`
+	Metadata *gpxMetadata `xml:"metadata,omitempty"`
+	Tracks   []gpxTrack   `xml:"trk"`
+}
+
+type gpxMetadata struct {
+	Name string `xml:"name,omitempty"`
+	Time string `xml:"time,omitempty"`
+}
+
+type gpxTrack struct {
+	Name     string       `xml:"name,omitempty"`
+	Segments []gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+// gpxPoint keeps its coordinates as pointers so a point missing one is not read as 0
+type gpxPoint struct {
+	Latitude  *float64 `xml:"lat,attr"`
+	Longitude *float64 `xml:"lon,attr"`
+	Time      string   `xml:"time,omitempty"`
+}
+
+// ParseGPX reads the track points of a GPX file in order, joining all tracks and segments.
+// It returns the name of the first track or of the file, and fails on points without coordinates or a time.
+func ParseGPX(data []byte) (string, []models.WalkPoint, error) {
+	var document gpxDocument
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return "", nil, fmt.Errorf("invalid GPX file: %v", err)
+	}
+
+	name := ""
+	if document.Metadata != nil {
+		name = strings.TrimSpace(document.Metadata.Name)
+	}
+
+	var points []models.WalkPoint
+	for _, track := range document.Tracks {
+		if name == "" {
+			name = strings.TrimSpace(track.Name)
+		}
+		for _, segment := range track.Segments {
+			for _, point := range segment.Points {
+				if point.Latitude == nil || point.Longitude == nil {
+					return "", nil, fmt.Errorf("invalid GPX file: track point %d has no lat and lon", len(points)+1)
+				}
+				timestamp, err := time.Parse(time.RFC3339, strings.TrimSpace(point.Time))
+				if err != nil {
+					return "", nil, fmt.Errorf("invalid GPX file: track point %d has no valid time", len(points)+1)
+				}
+				points = append(points, models.WalkPoint{
+					Latitude:  *point.Latitude,
+					Longitude: *point.Longitude,
+					Time:      timestamp,
+				})
+			}
+		}
+	}
+	if len(points) == 0 {
+		return "", nil, fmt.Errorf("invalid GPX file: no track points found")
+	}
+
+	return name, points, nil
+}
+
+// EncodeGPX writes points as a single track GPX 1.1 file
+func EncodeGPX(name string, points []models.WalkPoint) ([]byte, error) {
+	segment := gpxSegment{Points: make([]gpxPoint, 0, len(points))}
+	for _, point := range points {
+		latitude, longitude := point.Latitude, point.Longitude
+		segment.Points = append(segment.Points, gpxPoint{
+			Latitude:  &latitude,
+			Longitude: &longitude,
+			Time:      point.Time.UTC().Format(time.RFC3339),
+		})
+	}
+
+	document := gpxDocument{
+		Version: "1.1",
+		Creator: "playtime",
+		Xmlns:   "http://www.topografix.com/GPX/1/1",
+		Tracks:  []gpxTrack{{Name: name, Segments: []gpxSegment{segment}}},
+	}
+	if len(points) > 0 {
+		document.Metadata = &gpxMetadata{Name: name, Time: points[0].Time.UTC().Format(time.RFC3339)}
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buffer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return nil, fmt.Errorf("failed to encode GPX: %v", err)
+	}
+	buffer.WriteString("\n")
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"playtime-go/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGPXRoundTrip(t *testing.T) {
+	start := time.Date(2024, 5, 1, 7, 30, 0, 0, time.FixedZone("CST", 8*60*60))
+
+	tests := []struct {
+		name   string
+		title  string
+		points []models.WalkPoint
+	}{
+		{
+			name:  "single point",
+			title: "Quick walk",
+			points: []models.WalkPoint{
+				{Latitude: 31.2304, Longitude: 121.4737, Time: start},
+			},
+		},
+		{
+			name:  "track",
+			title: "Morning walk <park & river>",
+			points: []models.WalkPoint{
+				{Latitude: 31.2304, Longitude: 121.4737, Time: start},
+				{Latitude: 31.2311, Longitude: 121.4742, Time: start.Add(30 * time.Second)},
+				{Latitude: -33.8688, Longitude: -151.2093, Time: start.Add(time.Minute)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := EncodeGPX(tt.title, tt.points)
+			if err != nil {
+				t.Fatalf("EncodeGPX failed: %v", err)
+			}
+
+			name, points, err := ParseGPX(data)
+			if err != nil {
+				t.Fatalf("ParseGPX failed: %v", err)
+			}
+			if name != tt.title {
+				t.Errorf("name = %q, want %q", name, tt.title)
+			}
+			if len(points) != len(tt.points) {
+				t.Fatalf("got %d points, want %d", len(points), len(tt.points))
+			}
+			for i, point := range points {
+				want := tt.points[i]
+				if point.Latitude != want.Latitude || point.Longitude != want.Longitude || !point.Time.Equal(want.Time) {
+					t.Errorf("point %d = %+v, want %+v", i, point, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseGPXRejectsIncompletePoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name:    "missing longitude",
+			data:    `<gpx><trk><trkseg><trkpt lat="31.23"><time>2024-05-01T07:30:00Z</time></trkpt></trkseg></trk></gpx>`,
+			wantErr: "has no lat and lon",
+		},
+		{
+			name:    "missing time",
+			data:    `<gpx><trk><trkseg><trkpt lat="31.23" lon="121.47"></trkpt></trkseg></trk></gpx>`,
+			wantErr: "has no valid time",
+		},
+		{
+			name:    "no track points",
+			data:    `<gpx><trk><trkseg></trkseg></trk></gpx>`,
+			wantErr: "no track points found",
+		},
+		{
+			name:    "not XML",
+			data:    `walk`,
+			wantErr: "invalid GPX file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseGPX([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseGPX error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"fmt"
+	"net/http"
 	"strings"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -25,3 +27,43 @@ func ExtractUrlParam(url string, prefix string) []string {
 
 	return partList
 }
+
+// SessionVerifier checks a session token and returns the user it was issued to
+type SessionVerifier func(token string) (primitive.ObjectID, error)
+
+// sessionVerifier is set at startup, utils cannot depend on the services that sign tokens
+var sessionVerifier SessionVerifier
+
+// SetSessionVerifier sets how request session tokens are checked
+func SetSessionVerifier(verifier SessionVerifier) {
+	sessionVerifier = verifier
+}
+
+// GetRequestUserID returns the calling user's ID from the session token in the "Authorization: Bearer" header
+func GetRequestUserID(r *http.Request) (primitive.ObjectID, error) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return primitive.NilObjectID, fmt.Errorf("missing session token")
+	}
+	return verifySessionToken(strings.TrimPrefix(header, "Bearer "))
+}
+
+// GetStreamUserID returns the calling user's ID for WebSocket, SSE and long poll requests.
+// Browsers cannot set headers on these, so the session token may also come as the token query parameter.
+func GetStreamUserID(r *http.Request) (primitive.ObjectID, error) {
+	if token := r.URL.Query().Get("token"); token != "" {
+		return verifySessionToken(token)
+	}
+	return GetRequestUserID(r)
+}
+
+// verifySessionToken checks a session token with the verifier set at startup
+func verifySessionToken(token string) (primitive.ObjectID, error) {
+	if token == "" {
+		return primitive.NilObjectID, fmt.Errorf("missing session token")
+	}
+	if sessionVerifier == nil {
+		return primitive.NilObjectID, fmt.Errorf("session tokens cannot be verified")
+	}
+	return sessionVerifier(token)
+}
@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebSocket opcodes
+const (
+	WebSocketText   = 0x1
+	WebSocketBinary = 0x2
+	WebSocketClose  = 0x8
+	WebSocketPing   = 0x9
+	WebSocketPong   = 0xA
+)
+
+const (
+	// webSocketGUID is the fixed key suffix from RFC 6455 used to compute Sec-WebSocket-Accept
+	webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	// maxWebSocketFrame is the largest frame payload accepted from a client
+	maxWebSocketFrame = 64 * 1024
+
+	webSocketWriteTimeout = 10 * time.Second
+)
+
+// WebSocketConn is a server side WebSocket connection (RFC 6455) without extensions.
+// Writes are safe for concurrent use, reads must happen from a single goroutine.
+type WebSocketConn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	writeMu sync.Mutex
+}
+
+// IsWebSocketUpgrade reports whether the request asks to switch to the WebSocket protocol
+func IsWebSocketUpgrade(r *http.Request) bool {
+	return headerContainsToken(r.Header, "Connection", "upgrade") && headerContainsToken(r.Header, "Upgrade", "websocket")
+}
+
+// UpgradeWebSocket completes the WebSocket handshake and takes over the connection.
+// Nothing may be written to w before or after a successful upgrade.
+func UpgradeWebSocket(w http.ResponseWriter, r *http.Request) (*WebSocketConn, error) {
+	if r.Method != http.MethodGet || !IsWebSocketUpgrade(r) {
+		return nil, fmt.Errorf("invalid websocket request: not an upgrade request")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, fmt.Errorf("invalid websocket request: unsupported version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, fmt.Errorf("invalid websocket request: missing key")
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, fmt.Errorf("websocket upgrade not supported by the server")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("failed to hijack connection: %v", err)
+	}
+
+	sum := sha1.Sum([]byte(key + webSocketGUID))
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n"
+
+	conn.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+	if _, err := conn.Write([]byte(response)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to complete websocket handshake: %v", err)
+	}
+
+	return &WebSocketConn{conn: conn, reader: rw.Reader}, nil
+}
+
+// ReadMessage reads the next data message, answering pings on the way.
+// It returns io.EOF once the client closes the connection.
+func (c *WebSocketConn) ReadMessage() (int, []byte, error) {
+	var (
+		opcode  int
+		message []byte
+	)
+
+	for {
+		fin, frameOpcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch frameOpcode {
+		case WebSocketPing:
+			if err := c.writeFrame(WebSocketPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case WebSocketPong:
+			continue
+		case WebSocketClose:
+			c.writeFrame(WebSocketClose, payload)
+			return 0, nil, io.EOF
+		case 0: // continuation
+			if opcode == 0 {
+				return 0, nil, fmt.Errorf("invalid websocket frame: unexpected continuation")
+			}
+		default:
+			if opcode != 0 {
+				return 0, nil, fmt.Errorf("invalid websocket frame: expected continuation")
+			}
+			opcode = frameOpcode
+		}
+
+		message = append(message, payload...)
+		if len(message) > maxWebSocketFrame {
+			return 0, nil, fmt.Errorf("invalid websocket frame: message too large")
+		}
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+// WriteText sends a text message
+func (c *WebSocketConn) WriteText(data []byte) error {
+	return c.writeFrame(WebSocketText, data)
+}
+
+// WritePing sends a ping to keep the connection alive through proxies
+func (c *WebSocketConn) WritePing() error {
+	return c.writeFrame(WebSocketPing, nil)
+}
+
+// Close sends a close frame and closes the underlying connection
+func (c *WebSocketConn) Close() error {
+	c.writeFrame(WebSocketClose, nil)
+	return c.conn.Close()
+}
+
+// readFrame reads a single frame, client frames are always masked
+func (c *WebSocketConn) readFrame() (bool, int, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := header[0]&0x80 != 0
+	opcode := int(header[0] & 0x0F)
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, fmt.Errorf("invalid websocket frame: reserved bits set")
+	}
+	if !masked {
+		return false, 0, nil, fmt.Errorf("invalid websocket frame: client frames must be masked")
+	}
+
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if length > maxWebSocketFrame {
+		return false, 0, nil, fmt.Errorf("invalid websocket frame: frame too large")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// writeFrame writes a single unmasked frame
+func (c *WebSocketConn) writeFrame(opcode int, payload []byte) error {
+	frame := make([]byte, 0, len(payload)+10)
+	frame = append(frame, 0x80|byte(opcode))
+
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, 126, byte(length>>8), byte(length))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+	frame = append(frame, payload...)
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.conn.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// headerContainsToken reports whether a comma separated header contains the token, ignoring case
+func headerContainsToken(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}